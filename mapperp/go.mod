@@ -11,3 +11,5 @@ require (
 replace github.com/greghart/powerputtygo/sqlp => ../sqlp
 
 replace github.com/greghart/powerputtygo/errcmp => ../errcmp
+
+replace github.com/greghart/powerputtygo/queryp => ../queryp
@@ -58,6 +58,84 @@ func Slice[Row any, Out any](
 	)
 }
 
+// Map maps rows into a map of entities by their ID, eg. for lookups by ID.
+// Like One, the first row of each entity is used, and zero IDs are skipped. Unlike Slice, rows of
+// the same entity needn't be consecutive.
+func Map[Row any, K comparable, Out any](
+	getID Identifier[Out, K],
+	getData DataGetter[Row, Out],
+) Mapper[Row, map[K]Out] {
+	var zero K
+	return func(out *map[K]Out, row *Row, i int) {
+		datum := getData(row)
+		if datum == nil {
+			return
+		}
+		id := getID(datum)
+		if id == zero {
+			return
+		}
+		if *out == nil {
+			*out = map[K]Out{}
+		}
+		if _, ok := (*out)[id]; !ok {
+			(*out)[id] = *datum
+		}
+	}
+}
+
+// Set maps rows into a deduplicated set of values, eg. distinct tags across joined rows.
+// Zero values are skipped.
+func Set[Row any, E comparable](
+	getData DataGetter[Row, E],
+) Mapper[Row, map[E]struct{}] {
+	var zero E
+	return func(out *map[E]struct{}, row *Row, i int) {
+		datum := getData(row)
+		if datum == nil || *datum == zero {
+			return
+		}
+		if *out == nil {
+			*out = map[E]struct{}{}
+		}
+		(*out)[*datum] = struct{}{}
+	}
+}
+
+// GroupBy groups rows by key, aggregating each group's rows with its own mapper, eg. for report
+// style shapes of key -> aggregated children. Zero keys are skipped.
+// Mappers can be stateful (eg. Slice tracks the last ID), so newMapper is called to make one per
+// group, letting groups' rows be interleaved.
+//
+//	petsByOwner := GroupBy(
+//		func(row *row) int64 { return row.person.ID },
+//		func() Mapper[row, []pet] { return Slice(petID, petData) },
+//	)
+func GroupBy[Row any, K comparable, Out any](
+	getKey func(row *Row) K,
+	newMapper func() Mapper[Row, Out],
+) Mapper[Row, map[K]Out] {
+	var zero K
+	mappers := map[K]Mapper[Row, Out]{}
+	return func(out *map[K]Out, row *Row, i int) {
+		key := getKey(row)
+		if key == zero {
+			return
+		}
+		mapper, ok := mappers[key]
+		if !ok {
+			mapper = newMapper()
+			mappers[key] = mapper
+		}
+		if *out == nil {
+			*out = map[K]Out{}
+		}
+		group := (*out)[key]
+		mapper(&group, row, i)
+		(*out)[key] = group
+	}
+}
+
 // Inner sets up a sub mapper into our current output.
 func Inner[Row any, Out any, In any](
 	getInner func(e *Out) *In,
@@ -100,6 +178,40 @@ func Last[Row any, Out any](
 	}
 }
 
+// When runs the mappers only for rows matching pred, eg. to route rows to different branches.
+//
+//	All(
+//		When(isDog, InnerSlice(dogs, petID, petData)),
+//		When(isCat, InnerSlice(cats, petID, petData)),
+//	)
+func When[Row any, Out any](
+	pred func(row *Row) bool,
+	inner ...Mapper[Row, Out],
+) Mapper[Row, Out] {
+	mapper := All(inner...)
+	return func(out *Out, row *Row, i int) {
+		if pred(row) {
+			mapper(out, row, i)
+		}
+	}
+}
+
+// Reusable returns a mapper that can be reused across result sets (eg. per query or batch in a
+// long running worker), making a fresh mapper with newMapper at the start of each one, ie. at row
+// 0. Mappers like One and Slice keep state between rows, so otherwise can't be reused.
+// Like other mappers, it's not safe for concurrent use.
+//
+//	personMapper := Reusable(func() Mapper[row, person] { return One(personData, pets) })
+func Reusable[Row any, Out any](newMapper func() Mapper[Row, Out]) Mapper[Row, Out] {
+	var mapper Mapper[Row, Out]
+	return func(out *Out, row *Row, i int) {
+		if i == 0 || mapper == nil {
+			mapper = newMapper()
+		}
+		mapper(out, row, i)
+	}
+}
+
 // All just runs all mappers in sequence.
 func All[Row any, Out any](
 	mappers ...Mapper[Row, Out],
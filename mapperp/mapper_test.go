@@ -171,6 +171,147 @@ func TestMapper_All(t *testing.T) {
 	}
 }
 
+func TestMapper_Map(t *testing.T) {
+	rows := []row{
+		{person: person{ID: 1, Name: "Alice"}, pet: pet{ID: 1, Name: "Kitty"}},
+		{person: person{ID: 2, Name: "Bob"}, pet: pet{ID: 2, Name: "Doggy"}},
+		{person: person{ID: 1, Name: "Alice again"}, pet: pet{ID: 3, Name: "Fishy"}}, // not consecutive
+		{}, // zero ID is skipped
+	}
+	rowMapper := Map(
+		func(e *person) int64 { return e.ID },
+		func(row *row) *person { return &row.person },
+	)
+
+	var result map[int64]person
+	for i, r := range rows {
+		rowMapper(&result, &r, i)
+	}
+
+	expected := map[int64]person{1: {ID: 1, Name: "Alice"}, 2: {ID: 2, Name: "Bob"}}
+	if !cmp.Equal(result, expected) {
+		t.Errorf("mapped people unexpected:\n%v", cmp.Diff(expected, result))
+	}
+}
+
+func TestMapper_Set(t *testing.T) {
+	rows := []row{
+		{pet: pet{ID: 1, Name: "Kitty"}},
+		{pet: pet{ID: 2, Name: "Doggy"}},
+		{pet: pet{ID: 3, Name: "Kitty"}},
+		{},
+	}
+	rowMapper := Set(func(row *row) *string { return &row.pet.Name })
+
+	var result map[string]struct{}
+	for i, r := range rows {
+		rowMapper(&result, &r, i)
+	}
+
+	expected := map[string]struct{}{"Kitty": {}, "Doggy": {}}
+	if !cmp.Equal(result, expected) {
+		t.Errorf("mapped names unexpected:\n%v", cmp.Diff(expected, result))
+	}
+}
+
+func TestMapper_GroupBy(t *testing.T) {
+	rows := []row{
+		{person: person{ID: 1, Name: "Alice"}, pet: pet{ID: 1, Name: "Kitty"}},
+		{person: person{ID: 2, Name: "Bob"}, pet: pet{ID: 3, Name: "Weasely"}},
+		{person: person{ID: 1, Name: "Alice"}, pet: pet{ID: 2, Name: "Doggy"}},
+		{person: person{ID: 2, Name: "Bob"}, pet: pet{ID: 3, Name: "Weasely"}}, // eg. joined twice
+		{person: person{ID: 3, Name: "Carol"}},                                 // no pets
+	}
+	rowMapper := GroupBy(
+		func(row *row) int64 { return row.person.ID },
+		func() Mapper[row, []pet] {
+			return Slice(
+				func(e *pet) int64 { return e.ID },
+				func(row *row) *pet { return &row.pet },
+			)
+		},
+	)
+
+	var result map[int64][]pet
+	for i, r := range rows {
+		rowMapper(&result, &r, i)
+	}
+
+	expected := map[int64][]pet{
+		1: {{ID: 1, Name: "Kitty"}, {ID: 2, Name: "Doggy"}},
+		2: {{ID: 3, Name: "Weasely"}},
+		3: nil,
+	}
+	if !cmp.Equal(result, expected) {
+		t.Errorf("mapped pets unexpected:\n%v", cmp.Diff(expected, result))
+	}
+}
+
+func TestMapper_When(t *testing.T) {
+	type owner struct {
+		Dogs []pet
+		Cats []pet
+	}
+	rows := []row{
+		{pet: pet{ID: 1, Name: "Doggy"}},
+		{pet: pet{ID: 2, Name: "Kitty"}},
+		{pet: pet{ID: 3, Name: "Doggo"}},
+	}
+	isDog := func(row *row) bool { return row.pet.Name[:3] == "Dog" }
+	petID := func(e *pet) int64 { return e.ID }
+	petData := func(row *row) *pet { return &row.pet }
+	rowMapper := All(
+		When(isDog, InnerSlice(func(e *owner) *[]pet { return &e.Dogs }, petID, petData)),
+		When(
+			func(row *row) bool { return !isDog(row) },
+			InnerSlice(func(e *owner) *[]pet { return &e.Cats }, petID, petData),
+		),
+	)
+
+	var result owner
+	for i, r := range rows {
+		rowMapper(&result, &r, i)
+	}
+
+	expected := owner{
+		Dogs: []pet{{ID: 1, Name: "Doggy"}, {ID: 3, Name: "Doggo"}},
+		Cats: []pet{{ID: 2, Name: "Kitty"}},
+	}
+	if !cmp.Equal(result, expected) {
+		t.Errorf("mapped pets unexpected:\n%v", cmp.Diff(expected, result))
+	}
+}
+
+func TestMapper_Reusable(t *testing.T) {
+	batches := [][]row{
+		{{person: person{ID: 1, Name: "Alice"}}, {person: person{ID: 2, Name: "Bob"}}},
+		{{person: person{ID: 2, Name: "Bob"}}}, // would be skipped as the last ID otherwise
+	}
+	rowMapper := Reusable(func() Mapper[row, []person] {
+		return Slice(
+			func(e *person) int64 { return e.ID },
+			func(row *row) *person { return &row.person },
+		)
+	})
+
+	var results [][]person
+	for _, batch := range batches {
+		var result []person
+		for i, r := range batch {
+			rowMapper(&result, &r, i)
+		}
+		results = append(results, result)
+	}
+
+	expected := [][]person{
+		{{ID: 1, Name: "Alice"}, {ID: 2, Name: "Bob"}},
+		{{ID: 2, Name: "Bob"}},
+	}
+	if !cmp.Equal(results, expected) {
+		t.Errorf("mapped people unexpected:\n%v", cmp.Diff(expected, results))
+	}
+}
+
 ////////////////////////////////////////////////////////////////////////////////
 
 // a result from a database join of person and pet
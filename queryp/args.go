@@ -1,6 +1,10 @@
 package queryp
 
-import "fmt"
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
 
 // Args are a way to build placeholder arguments for queries in a composable way.
 // This is a very simple paradigm that's not particularly useful by itself, but used with the
@@ -8,6 +12,7 @@ import "fmt"
 type Args struct {
 	placeholderer Placeholderer
 	args          []any
+	offset        int // number of arguments before these, for positional placeholders
 }
 
 type Placeholderer func(i int) string
@@ -25,16 +30,45 @@ func (a *Args) WithPlaceholderer(p Placeholderer) *Args {
 	return a
 }
 
+// WithOffset starts placeholders after n other arguments, so independently built args can later be
+// combined (see Merge) without positional placeholders colliding.
+func (a *Args) WithOffset(n int) *Args {
+	a.offset = n
+	return a
+}
+
 // Add adds an argument and returns a placeholder for it.
 func (a *Args) Add(arg any) string {
 	a.args = append(a.args, arg)
-	return a.placeholderer(len(a.args) - 1)
+	return a.placeholderer(a.offset + len(a.args) - 1)
 }
 
 func (a *Args) Args() []any {
 	return a.args
 }
 
+// Len returns the number of placeholders used so far, including the offset. This is the offset
+// to build any following args with.
+func (a *Args) Len() int {
+	return a.offset + len(a.args)
+}
+
+// Merge appends the arguments of other, which must have been built with an offset of a.Len() (or
+// it panics, as its placeholders would be wrong), eg.
+//
+//	where := queryp.NewArgs().WithPlaceholderer(queryp.PostgresPlaceholderer)
+//	q := "WHERE id = " + where.Add(1)
+//	limit := queryp.NewArgs().WithPlaceholderer(queryp.PostgresPlaceholderer).WithOffset(where.Len())
+//	q += " LIMIT " + limit.Add(10) // $2
+//	where.Merge(limit)
+func (a *Args) Merge(other *Args) *Args {
+	if other.offset != a.Len() {
+		panic(fmt.Sprintf("queryp: merged args start at offset %d, expected %d", other.offset, a.Len()))
+	}
+	a.args = append(a.args, other.args...)
+	return a
+}
+
 ////////////////////////////////////////////////////////////////////////////////
 
 var SqlitePlaceholderer = func(i int) string {
@@ -42,5 +76,60 @@ var SqlitePlaceholderer = func(i int) string {
 }
 
 var PostgresPlaceholderer = func(i int) string {
-	return fmt.Sprintf("$%d", i+1) // Postgres placeholders start at $1, so we add 1 to the index
+	if i < len(postgresPlaceholders) {
+		return postgresPlaceholders[i]
+	}
+	return "$" + strconv.Itoa(i+1) // Postgres placeholders start at $1, so we add 1 to the index
+}
+
+// postgresPlaceholders are the common Postgres placeholders, so rendering them doesn't allocate.
+var postgresPlaceholders = func() []string {
+	placeholders := make([]string, 64)
+	for i := range placeholders {
+		placeholders[i] = "$" + strconv.Itoa(i+1)
+	}
+	return placeholders
+}()
+
+// Rebind rewrites a query's `?` placeholders into p's style, eg. `$1` for Postgres, so queries can
+// be written once with `?`. Question marks in string literals, quoted identifiers and comments are
+// left as is. Any other `?` is taken as a placeholder, so Postgres' jsonb operators (`?`, `?|` and
+// `?&`) must be written with their function equivalents (jsonb_exists, jsonb_exists_any and
+// jsonb_exists_all) in queries to rebind.
+func Rebind(p Placeholderer, query string) string {
+	var b strings.Builder
+	b.Grow(len(query))
+	n := 0
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		end := -1 // end of a region to copy as is
+		switch {
+		case c == '\'' || c == '"':
+			// An escaped quote ('' or "") ends the region and starts another
+			if end = strings.IndexByte(query[i+1:], c); end >= 0 {
+				end += i + 2
+			}
+		case c == '-' && strings.HasPrefix(query[i:], "--"):
+			if end = strings.IndexByte(query[i:], '\n'); end >= 0 {
+				end += i + 1
+			}
+		case c == '/' && strings.HasPrefix(query[i:], "/*"):
+			if end = strings.Index(query[i+2:], "*/"); end >= 0 {
+				end += i + 4
+			}
+		case c == '?':
+			b.WriteString(p(n))
+			n++
+			continue
+		default:
+			b.WriteByte(c)
+			continue
+		}
+		if end < 0 { // unterminated, so the rest of the query
+			end = len(query)
+		}
+		b.WriteString(query[i:end])
+		i = end - 1
+	}
+	return b.String()
 }
@@ -33,3 +33,78 @@ func TestArgs_pg(t *testing.T) {
 		t.Errorf("age given placeholder %s, wanted '$2'", name)
 	}
 }
+
+func TestArgs_Merge(t *testing.T) {
+	where := NewArgs().WithPlaceholderer(PostgresPlaceholderer)
+	q := "WHERE id = " + where.Add(1) + " AND name = " + where.Add("Alice")
+
+	limit := NewArgs().WithPlaceholderer(PostgresPlaceholderer).WithOffset(where.Len())
+	q += " LIMIT " + limit.Add(10)
+	if limit.Len() != 3 {
+		t.Errorf("expected limit args to end at 3, got %d", limit.Len())
+	}
+
+	where.Merge(limit)
+	if expected := "WHERE id = $1 AND name = $2 LIMIT $3"; q != expected {
+		t.Errorf("expected query %q, got %q", expected, q)
+	}
+	expected := []any{1, "Alice", 10}
+	if !cmp.Equal(where.Args(), expected) {
+		t.Errorf("merged args did not match expected\n%v", cmp.Diff(expected, where.Args()))
+	}
+
+	t.Run("wrong offset -> panic", func(t *testing.T) {
+		defer func() {
+			if r := recover(); r != "queryp: merged args start at offset 0, expected 3" {
+				t.Errorf("expected panic for wrong offset, got %v", r)
+			}
+		}()
+		where.Merge(NewArgs().WithPlaceholderer(PostgresPlaceholderer))
+	})
+}
+
+func TestRebind(t *testing.T) {
+	tests := map[string]struct {
+		p        Placeholderer
+		query    string
+		expected string
+	}{
+		"postgres": {
+			PostgresPlaceholderer,
+			"SELECT * FROM people WHERE id = ? AND last_name = ?",
+			"SELECT * FROM people WHERE id = $1 AND last_name = $2",
+		},
+		"sqlite": {
+			SqlitePlaceholderer,
+			"SELECT * FROM people WHERE id = ?",
+			"SELECT * FROM people WHERE id = ?",
+		},
+		"string literals": {
+			PostgresPlaceholderer,
+			"SELECT 'what?', 'it''s ?' FROM people WHERE id = ?",
+			"SELECT 'what?', 'it''s ?' FROM people WHERE id = $1",
+		},
+		"quoted identifiers": {
+			PostgresPlaceholderer,
+			`SELECT "why?", "say ""?""" FROM people WHERE id = ?`,
+			`SELECT "why?", "say ""?""" FROM people WHERE id = $1`,
+		},
+		"comments": {
+			PostgresPlaceholderer,
+			"SELECT * -- who?\nFROM people /* which? */ WHERE id = ? /* unterminated?",
+			"SELECT * -- who?\nFROM people /* which? */ WHERE id = $1 /* unterminated?",
+		},
+		"jsonb functions": {
+			PostgresPlaceholderer,
+			"SELECT * FROM people WHERE jsonb_exists(tags, ?) AND id - ? > 0",
+			"SELECT * FROM people WHERE jsonb_exists(tags, $1) AND id - $2 > 0",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if q := Rebind(test.p, test.query); q != test.expected {
+				t.Errorf("expected %q, got %q", test.expected, q)
+			}
+		})
+	}
+}
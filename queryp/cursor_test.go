@@ -0,0 +1,59 @@
+package queryp
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCursorCodec(t *testing.T) {
+	type cursor struct {
+		CreatedAt time.Time
+		ID        int64
+	}
+	codec := NewCursorCodec([]byte("secret"))
+	in := cursor{CreatedAt: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), ID: 42}
+	token, err := codec.Encode(in)
+	if err != nil {
+		t.Fatalf("failed to encode: %v", err)
+	}
+
+	t.Run("round trips", func(t *testing.T) {
+		var out cursor
+		if err := codec.Decode(token, &out); err != nil {
+			t.Fatalf("failed to decode: %v", err)
+		}
+		if !cmp.Equal(in, out) {
+			t.Errorf("decoded cursor unexpected:\n%v", cmp.Diff(in, out))
+		}
+	})
+
+	tests := map[string]struct {
+		codec *CursorCodec
+		token string
+	}{
+		"different key":     {NewCursorCodec([]byte("other")), token},
+		"tampered payload":  {codec, "x" + token},
+		"tampered sig":      {codec, token + "x"},
+		"missing signature": {codec, strings.Split(token, ".")[0]},
+		"garbage":           {codec, "not a cursor"},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var out cursor
+			if err := test.codec.Decode(test.token, &out); !errors.Is(err, ErrInvalidCursor) {
+				t.Errorf("got %v, expected ErrInvalidCursor", err)
+			}
+		})
+	}
+
+	t.Run("different shape", func(t *testing.T) {
+		var out struct{ ID int64 }
+		if err := codec.Decode(token, &out); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("got %v, expected ErrInvalidCursor", err)
+		}
+	})
+}
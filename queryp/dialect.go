@@ -0,0 +1,38 @@
+package queryp
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Dialect identifies a SQL dialect, for the few helpers that must render differently per database.
+type Dialect string
+
+const (
+	Sqlite   Dialect = "sqlite"
+	Postgres Dialect = "postgres"
+	MySQL    Dialect = "mysql"
+)
+
+// Placeholderer returns the placeholder style of the dialect.
+func (d Dialect) Placeholderer() Placeholderer {
+	if d == Postgres {
+		return PostgresPlaceholderer
+	}
+	return SqlitePlaceholderer
+}
+
+// QuoteIdent quotes an identifier (eg. a table or schema name) for the dialect: backticks for
+// MySQL, and standard double quotes otherwise.
+func (d Dialect) QuoteIdent(name string) string {
+	if d == MySQL {
+		return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+	}
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// unsupported panics for a helper that has no rendering for the dialect.
+// Dialects are set up by the programmer, so like Must, this is treated as a programming error.
+func (d Dialect) unsupported(helper string) string {
+	panic(fmt.Sprintf("queryp: %s is not supported for dialect %q", helper, d))
+}
@@ -0,0 +1,22 @@
+package queryp
+
+import "testing"
+
+func TestDialect_QuoteIdent(t *testing.T) {
+	tests := map[string]struct {
+		d        Dialect
+		name     string
+		expected string
+	}{
+		"postgres": {Postgres, `my"table`, `"my""table"`},
+		"sqlite":   {Sqlite, "people", `"people"`},
+		"mysql":    {MySQL, "my`table", "`my``table`"},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := test.d.QuoteIdent(test.name); got != test.expected {
+				t.Errorf("expected %v, got %v", test.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package queryp
+
+import (
+	"fmt"
+)
+
+// EqFold renders a case-insensitive equality, eg. for email or username lookups. Each dialect's
+// rendering can use an index made by FoldIndex, while a plain index on the column can't be used.
+//   - Postgres: `LOWER(column) = LOWER(value)`
+//   - SQLite: `column = value COLLATE NOCASE` (which folds ASCII only)
+//   - MySQL: `LOWER(column) = LOWER(value)`
+func EqFold(d Dialect, column string, value string) Fragment {
+	return func(args *Args) string {
+		switch d {
+		case Postgres, MySQL:
+			return fmt.Sprintf("LOWER(%s) = LOWER(%s)", column, args.Add(value))
+		case Sqlite:
+			return fmt.Sprintf("%s = %s COLLATE NOCASE", column, args.Add(value))
+		default:
+			return d.unsupported("EqFold")
+		}
+	}
+}
+
+// LikeFold renders a case-insensitive LIKE.
+//   - Postgres: `column ILIKE pattern`
+//   - SQLite: `column LIKE pattern` (which is case-insensitive for ASCII)
+//   - MySQL: `LOWER(column) LIKE LOWER(pattern)`
+//
+// Only prefix patterns (eg. 'john%') can use an index, made by FoldIndex for SQLite and MySQL.
+// Postgres needs `text_pattern_ops` on the index expression, or a pg_trgm index for other patterns.
+func LikeFold(d Dialect, column string, pattern string) Fragment {
+	return func(args *Args) string {
+		switch d {
+		case Postgres:
+			return fmt.Sprintf("%s ILIKE %s", column, args.Add(pattern))
+		case Sqlite:
+			return fmt.Sprintf("%s LIKE %s", column, args.Add(pattern))
+		case MySQL:
+			return fmt.Sprintf("LOWER(%s) LIKE LOWER(%s)", column, args.Add(pattern))
+		default:
+			return d.unsupported("LikeFold")
+		}
+	}
+}
+
+// FoldIndex returns the DDL of an index that case-insensitive lookups with EqFold can use, eg.
+// for migrations. Names are written as is.
+//   - Postgres: `CREATE INDEX name ON table (LOWER(column))`
+//   - SQLite: `CREATE INDEX name ON table (column COLLATE NOCASE)`
+//   - MySQL (8.0.13+): `CREATE INDEX name ON table ((LOWER(column)))`
+func FoldIndex(d Dialect, name, table, column string) string {
+	switch d {
+	case Postgres:
+		return fmt.Sprintf("CREATE INDEX %s ON %s (LOWER(%s))", name, table, column)
+	case Sqlite:
+		return fmt.Sprintf("CREATE INDEX %s ON %s (%s COLLATE NOCASE)", name, table, column)
+	case MySQL:
+		return fmt.Sprintf("CREATE INDEX %s ON %s ((LOWER(%s)))", name, table, column)
+	default:
+		return d.unsupported("FoldIndex")
+	}
+}
@@ -0,0 +1,84 @@
+package queryp
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFold(t *testing.T) {
+	tests := map[string]struct {
+		f            Fragment
+		d            Dialect
+		expectedQ    string
+		expectedArgs []any
+	}{
+		"postgres eq": {
+			EqFold(Postgres, "email", "John@Example.com"), Postgres,
+			"LOWER(email) = LOWER($1)",
+			[]any{"John@Example.com"},
+		},
+		"sqlite eq": {
+			EqFold(Sqlite, "email", "John@Example.com"), Sqlite,
+			"email = ? COLLATE NOCASE",
+			[]any{"John@Example.com"},
+		},
+		"mysql eq": {
+			EqFold(MySQL, "email", "John@Example.com"), MySQL,
+			"LOWER(email) = LOWER(?)",
+			[]any{"John@Example.com"},
+		},
+		"postgres like": {
+			LikeFold(Postgres, "username", "john%"), Postgres,
+			"username ILIKE $1",
+			[]any{"john%"},
+		},
+		"sqlite like": {
+			LikeFold(Sqlite, "username", "john%"), Sqlite,
+			"username LIKE ?",
+			[]any{"john%"},
+		},
+		"mysql like": {
+			LikeFold(MySQL, "username", "john%"), MySQL,
+			"LOWER(username) LIKE LOWER(?)",
+			[]any{"john%"},
+		},
+		"composes": {
+			And(Eq("last_name", "Doe"), EqFold(Sqlite, "first_name", "JOHN")), Sqlite,
+			"(last_name = ? AND first_name = ? COLLATE NOCASE)",
+			[]any{"Doe", "JOHN"},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			q, args := test.f.Execute(test.d.Placeholderer())
+			if q != test.expectedQ {
+				t.Errorf("expected query %q, got %q", test.expectedQ, q)
+			}
+			if !cmp.Equal(args, test.expectedArgs) {
+				t.Errorf("unexpected args: %s", cmp.Diff(test.expectedArgs, args))
+			}
+		})
+	}
+
+	t.Run("index", func(t *testing.T) {
+		for d, expected := range map[Dialect]string{
+			Postgres: "CREATE INDEX people_email ON people (LOWER(email))",
+			Sqlite:   "CREATE INDEX people_email ON people (email COLLATE NOCASE)",
+			MySQL:    "CREATE INDEX people_email ON people ((LOWER(email)))",
+		} {
+			if got := FoldIndex(d, "people_email", "people", "email"); got != expected {
+				t.Errorf("%s: expected %q, got %q", d, expected, got)
+			}
+		}
+	})
+
+	t.Run("unknown dialect panics", func(t *testing.T) {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Errorf("expected panic")
+			}
+		}()
+		EqFold(Dialect("oracle"), "email", "x").Execute(nil)
+	})
+}
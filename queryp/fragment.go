@@ -0,0 +1,15 @@
+package queryp
+
+// Fragment is a piece of SQL that renders itself against args, adding any arguments it needs and
+// returning SQL with placeholders in args' style.
+// Rendering against a shared Args is what lets fragments compose while keeping placeholders in
+// order, even for positional styles like Postgres.
+type Fragment func(args *Args) string
+
+// Execute renders the fragment on its own, returning the SQL and its arguments.
+// A nil placeholderer defaults to SQLite style.
+func (f Fragment) Execute(p Placeholderer) (string, []any) {
+	args := NewArgs().WithPlaceholderer(p)
+	q := f(args)
+	return q, args.Args()
+}
@@ -0,0 +1,56 @@
+package queryp
+
+import (
+	"strings"
+)
+
+// InsertSelect renders `INSERT INTO table (columns) SELECT ...`, inserting the rows of a select,
+// eg. for backfill or copy jobs. The select can be any fragment, such as a NamedQuery's:
+//
+//	queryp.InsertSelect("people_archive", []string{"id", "first_name"}, queryp.Named(
+//		"SELECT id, first_name FROM people WHERE updated_at < :before",
+//	).Param("before", cutoff).Fragment())
+//
+// No columns inserts into all columns of table, in the order of the select.
+func InsertSelect(table string, columns []string, sel Fragment) Fragment {
+	return func(args *Args) string {
+		b := strings.Builder{}
+		b.WriteString("INSERT INTO ")
+		b.WriteString(table)
+		if len(columns) > 0 {
+			b.WriteString(" (")
+			b.WriteString(strings.Join(columns, ", "))
+			b.WriteString(")")
+		}
+		b.WriteString(" ")
+		b.WriteString(sel(args))
+		return b.String()
+	}
+}
+
+// InsertValues renders a multi-row `INSERT INTO table (columns) VALUES (...), (...)`, with a
+// placeholder per value. Each row must have a value per column.
+func InsertValues(table string, columns []string, rows [][]any) Fragment {
+	return func(args *Args) string {
+		b := strings.Builder{}
+		b.WriteString("INSERT INTO ")
+		b.WriteString(table)
+		b.WriteString(" (")
+		b.WriteString(strings.Join(columns, ", "))
+		b.WriteString(") VALUES ")
+		for i, row := range rows {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			b.WriteString("(")
+			for j, v := range row {
+				if j > 0 {
+					b.WriteString(", ")
+				}
+				b.WriteString(args.Add(v))
+			}
+			b.WriteString(")")
+		}
+		return b.String()
+	}
+}
@@ -0,0 +1,54 @@
+package queryp
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestInsertSelect(t *testing.T) {
+	tests := map[string]struct {
+		f            Fragment
+		expectedQ    string
+		expectedArgs []any
+	}{
+		"named select": {
+			InsertSelect("people_archive", []string{"id", "first_name"}, Named(
+				"SELECT id, first_name FROM people WHERE last_name = :last_name AND id > :id",
+			).Params(map[string]any{"id": 10, "last_name": "Doe"}).Fragment()),
+			"INSERT INTO people_archive (id, first_name) SELECT id, first_name FROM people WHERE last_name = $1 AND id > $2",
+			[]any{"Doe", 10},
+		},
+		"all columns with predicates": {
+			InsertSelect("people_archive", nil, func(args *Args) string {
+				return "SELECT * FROM people WHERE " + And(Eq("last_name", "Doe"), In("id", []int{1, 2}))(args)
+			}),
+			"INSERT INTO people_archive SELECT * FROM people WHERE (last_name = $1 AND id IN ($2, $3))",
+			[]any{"Doe", 1, 2},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			q, args := test.f.Execute(PostgresPlaceholderer)
+			if q != test.expectedQ {
+				t.Errorf("expected query %q, got %q", test.expectedQ, q)
+			}
+			if !cmp.Equal(args, test.expectedArgs) {
+				t.Errorf("unexpected args: %s", cmp.Diff(test.expectedArgs, args))
+			}
+		})
+	}
+}
+
+func TestInsertValues(t *testing.T) {
+	q, args := InsertValues("people", []string{"first_name", "last_name"}, [][]any{
+		{"John", "Doe"},
+		{"Jane", "Doe"},
+	}).Execute(PostgresPlaceholderer)
+	if expected := "INSERT INTO people (first_name, last_name) VALUES ($1, $2), ($3, $4)"; q != expected {
+		t.Errorf("expected query %q, got %q", expected, q)
+	}
+	if expected := []any{"John", "Doe", "Jane", "Doe"}; !cmp.Equal(args, expected) {
+		t.Errorf("unexpected args: %s", cmp.Diff(expected, args))
+	}
+}
@@ -0,0 +1,94 @@
+package queryp
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// JSONValue renders an expression for the JSON value at path within a JSON column.
+// Path elements are object keys, or array indices when numeric, eg. ("pets", "0", "name").
+//   - Postgres: `column #> path`
+//   - SQLite: `column -> path`
+//   - MySQL: `JSON_EXTRACT(column, path)`
+func JSONValue(d Dialect, column string, path ...string) Fragment {
+	return func(args *Args) string {
+		switch d {
+		case Postgres:
+			return fmt.Sprintf("%s #> %s::text[]", column, args.Add(pgJSONPath(path)))
+		case Sqlite:
+			return fmt.Sprintf("%s -> %s", column, args.Add(jsonPath(path)))
+		case MySQL:
+			return fmt.Sprintf("JSON_EXTRACT(%s, %s)", column, args.Add(jsonPath(path)))
+		default:
+			return d.unsupported("JSONValue")
+		}
+	}
+}
+
+// JSONText renders an expression for the value at path within a JSON column as text, for use in
+// comparisons with regular values.
+//   - Postgres: `column #>> path`
+//   - SQLite: `json_extract(column, path)`
+//   - MySQL: `JSON_UNQUOTE(JSON_EXTRACT(column, path))`
+func JSONText(d Dialect, column string, path ...string) Fragment {
+	return func(args *Args) string {
+		switch d {
+		case Postgres:
+			return fmt.Sprintf("%s #>> %s::text[]", column, args.Add(pgJSONPath(path)))
+		case Sqlite:
+			return fmt.Sprintf("json_extract(%s, %s)", column, args.Add(jsonPath(path)))
+		case MySQL:
+			return fmt.Sprintf("JSON_UNQUOTE(JSON_EXTRACT(%s, %s))", column, args.Add(jsonPath(path)))
+		default:
+			return d.unsupported("JSONText")
+		}
+	}
+}
+
+// JSONContains renders a predicate that a JSON column contains the JSON encoding of v.
+// Panics if v can't be encoded.
+//   - Postgres: `column @> v` (column must be jsonb)
+//   - MySQL: `JSON_CONTAINS(column, v)`
+//   - SQLite: not supported
+func JSONContains(d Dialect, column string, v any) Fragment {
+	doc, err := json.Marshal(v)
+	if err != nil {
+		panic(fmt.Sprintf("queryp: failed to encode JSONContains value: %v", err))
+	}
+	return func(args *Args) string {
+		switch d {
+		case Postgres:
+			return fmt.Sprintf("%s @> %s::jsonb", column, args.Add(string(doc)))
+		case MySQL:
+			return fmt.Sprintf("JSON_CONTAINS(%s, %s)", column, args.Add(string(doc)))
+		default:
+			return d.unsupported("JSONContains")
+		}
+	}
+}
+
+// jsonPath builds a SQLite/MySQL JSON path, eg. `$."pets"[0]."name"`.
+func jsonPath(path []string) string {
+	b := strings.Builder{}
+	b.WriteString("$")
+	for _, key := range path {
+		if _, err := strconv.Atoi(key); err == nil {
+			b.WriteString("[" + key + "]")
+			continue
+		}
+		b.WriteString(`."` + strings.ReplaceAll(key, `"`, `\"`) + `"`)
+	}
+	return b.String()
+}
+
+// pgJSONPath builds a Postgres text array literal path, eg. `{"pets","0","name"}`.
+func pgJSONPath(path []string) string {
+	quoted := make([]string, len(path))
+	for i, key := range path {
+		key = strings.ReplaceAll(key, `\`, `\\`)
+		quoted[i] = `"` + strings.ReplaceAll(key, `"`, `\"`) + `"`
+	}
+	return "{" + strings.Join(quoted, ",") + "}"
+}
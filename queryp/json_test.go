@@ -0,0 +1,77 @@
+package queryp
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestJSON(t *testing.T) {
+	tests := map[string]struct {
+		f            Fragment
+		d            Dialect
+		expectedQ    string
+		expectedArgs []any
+	}{
+		"postgres value": {
+			JSONValue(Postgres, "data", "pets", "0"), Postgres,
+			"data #> $1::text[]",
+			[]any{`{"pets","0"}`},
+		},
+		"postgres text": {
+			JSONText(Postgres, "data", "owner", `a "name"`), Postgres,
+			"data #>> $1::text[]",
+			[]any{`{"owner","a \"name\""}`},
+		},
+		"postgres contains": {
+			JSONContains(Postgres, "data", map[string]any{"type": "dog"}), Postgres,
+			"data @> $1::jsonb",
+			[]any{`{"type":"dog"}`},
+		},
+		"sqlite value": {
+			JSONValue(Sqlite, "data", "pets", "0"), Sqlite,
+			"data -> ?",
+			[]any{`$."pets"[0]`},
+		},
+		"sqlite text": {
+			JSONText(Sqlite, "data", "owner", `a "name"`), Sqlite,
+			"json_extract(data, ?)",
+			[]any{`$."owner"."a \"name\""`},
+		},
+		"mysql value": {
+			JSONValue(MySQL, "data", "pets"), MySQL,
+			"JSON_EXTRACT(data, ?)",
+			[]any{`$."pets"`},
+		},
+		"mysql text": {
+			JSONText(MySQL, "data", "owner"), MySQL,
+			"JSON_UNQUOTE(JSON_EXTRACT(data, ?))",
+			[]any{`$."owner"`},
+		},
+		"mysql contains": {
+			JSONContains(MySQL, "data", []int{1, 2}), MySQL,
+			"JSON_CONTAINS(data, ?)",
+			[]any{`[1,2]`},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			q, args := test.f.Execute(test.d.Placeholderer())
+			if q != test.expectedQ {
+				t.Errorf("expected query %q, got %q", test.expectedQ, q)
+			}
+			if !cmp.Equal(args, test.expectedArgs) {
+				t.Errorf("unexpected args: %s", cmp.Diff(test.expectedArgs, args))
+			}
+		})
+	}
+
+	t.Run("sqlite contains panics", func(t *testing.T) {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Errorf("expected panic")
+			}
+		}()
+		JSONContains(Sqlite, "data", 1).Execute(nil)
+	})
+}
@@ -1,20 +1,32 @@
 package queryp
 
 import (
-	"fmt"
+	"maps"
+	"slices"
 	"strings"
+	"sync/atomic"
 )
 
 // NamedQuery represents a SQL query with named parameters.
 // It defers the actually building of the final query and its' arguments until either
 // `String()` or `Args()` is called, since order of the replacement matters in cases where the
 // driver doesn't use positional arguments (like sqlite)
+//
+// Once setup, a NamedQuery is safe for concurrent use of String, Args, and Execute, so it can be
+// shared (eg. as a package level var). The setters (Param, Params, etc.) are not safe for concurrent
+// use -- Clone the shared query first to customize it per use.
 type NamedQuery struct {
 	query         string
 	params        map[string]any // Store named parameters
 	placeholderer Placeholderer
-	builtQuery    string
-	builtArgs     *Args
+	offset        int
+	built         atomic.Pointer[namedBuild]
+}
+
+// namedBuild is the immutable result of building a NamedQuery.
+type namedBuild struct {
+	query string
+	args  []any
 }
 
 func Named(query string) *NamedQuery {
@@ -24,6 +36,16 @@ func Named(query string) *NamedQuery {
 	}
 }
 
+// Clone returns a copy of the NamedQuery that can be changed independently.
+func (n *NamedQuery) Clone() *NamedQuery {
+	return &NamedQuery{
+		query:         n.query,
+		params:        maps.Clone(n.params),
+		placeholderer: n.placeholderer,
+		offset:        n.offset,
+	}
+}
+
 // WithPlaceholderer sets the Placeholderer for the NamedQuery.
 func (n *NamedQuery) WithPlaceholderer(p Placeholderer) *NamedQuery {
 	n.reset()
@@ -31,6 +53,14 @@ func (n *NamedQuery) WithPlaceholderer(p Placeholderer) *NamedQuery {
 	return n
 }
 
+// WithOffset starts placeholders after n other arguments, to combine with separately built args.
+// See Args.WithOffset.
+func (n *NamedQuery) WithOffset(offset int) *NamedQuery {
+	n.reset()
+	n.offset = offset
+	return n
+}
+
 // WithQuery sets the query string for the NamedQuery.
 func (n *NamedQuery) WithQuery(q string) *NamedQuery {
 	n.reset()
@@ -48,6 +78,7 @@ func (n *NamedQuery) Params(m map[string]any) *NamedQuery {
 }
 
 // Param adds a single named parameter to the NamedQuery.
+// A Fragment value (eg. a predicate) is rendered inline, rather than as a single placeholder.
 func (n *NamedQuery) Param(key string, v any) *NamedQuery {
 	n.reset()
 	n.params[key] = v
@@ -56,61 +87,128 @@ func (n *NamedQuery) Param(key string, v any) *NamedQuery {
 
 // String returns the final built query with all named parameters replaced.
 func (n *NamedQuery) String() string {
-	if n.builtArgs == nil {
-		n.build()
-	}
-	return n.builtQuery
+	return n.load().query
 }
 
 // Args returns the arguments for the query, with named parameters replaced by their placeholders.
 func (n *NamedQuery) Args() []any {
-	if n.builtArgs == nil {
-		n.build()
-	}
-	return n.builtArgs.Args()
+	return n.load().args
 }
 
 // Execute returns the query and arguments for the named query.
 func (n *NamedQuery) Execute() (string, []any) {
-	if n.builtArgs == nil {
-		n.build()
-	}
-	return n.builtQuery, n.builtArgs.Args()
+	b := n.load()
+	return b.query, b.args
+}
+
+// Fragment returns the query as a Fragment, to compose into a larger query. Its arguments are added
+// to the larger query's, so its own placeholderer and offset don't apply.
+func (n *NamedQuery) Fragment() Fragment {
+	return n.render
 }
 
 ////////////////////////////////////////////////////////////////////////////////
 
 func (n *NamedQuery) reset() {
-	n.builtArgs = nil
-	n.builtQuery = ""
+	n.built.Store(nil)
+}
+
+// load returns the built query, building it if needed.
+// Concurrent callers may race to build, but build is deterministic so any result is fine.
+func (n *NamedQuery) load() *namedBuild {
+	if b := n.built.Load(); b != nil {
+		return b
+	}
+	b := n.build()
+	n.built.Store(b)
+	return b
 }
 
 // build constructs the final query string and arguments based on the named parameters.
-func (n *NamedQuery) build() {
-	n.builtArgs = NewArgs().WithPlaceholderer(n.placeholderer)
+func (n *NamedQuery) build() *namedBuild {
+	args := NewArgs().WithPlaceholderer(n.placeholderer).WithOffset(n.offset)
+	q := n.render(args)
+	// Clip args, so callers appending to a shared result don't clobber each other.
+	return &namedBuild{query: q, args: slices.Clip(args.Args())}
+}
 
+// render renders the query with named parameters replaced by placeholders from args.
+// Order matters! Eg. for 'WHERE id = :id AND name = :name', the id arg must come before name's, so
+// params are replaced in a single pass over the query.
+func (n *NamedQuery) render(args *Args) string {
 	q := strings.Builder{}
-	// Order matters!
-	// Eg. for 'WHERE id = :id AND name = :name', we need to ensure id arg comes before name arg.
-	// Performance isn't a huge concern here, so just use a double loop, but we could optimize
-	// by setting up a trie or something if needed
-	for i := 0; i < len(n.query); i++ {
-		c := n.query[i]
-		// Is there a matching named parameter starting at i?
-		match := false
-		if c == ':' {
-			for k, v := range n.params {
-				if strings.HasPrefix(n.query[i:], fmt.Sprintf(":%s", k)) {
-					match = true
-					q.WriteString(n.builtArgs.Add(v))
-					i += len(k) // skip over the ":key" part
-					break
-				}
-			}
+	q.Grow(len(n.query))
+	start := 0 // start of pending literal SQL
+	for i, j := nextName(n.query, 0); i >= 0; i, j = nextName(n.query, j) {
+		v, ok := n.params[n.query[i+1:j]]
+		if !ok {
+			continue // unknown names are left as is
 		}
-		if !match {
-			q.WriteByte(c)
+		q.WriteString(n.query[start:i])
+		if f, ok := v.(Fragment); ok {
+			q.WriteString(f(args)) // fragments render inline
+		} else {
+			q.WriteString(args.Add(v))
 		}
+		start = j
 	}
-	n.builtQuery = q.String()
+	q.WriteString(n.query[start:])
+	return q.String()
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// namedPart is a part of a parsed named query: either literal SQL, or a named parameter.
+type namedPart struct {
+	text  string // literal SQL, if not a param
+	param string // param name, if a param
+}
+
+// parseNamed splits query into literal SQL and `:name` params, where isParam reports whether a
+// name is a param -- unknown names are left as literal SQL.
+func parseNamed(query string, isParam func(name string) bool) []namedPart {
+	var parts []namedPart
+	start := 0 // start of pending literal SQL
+	for i, j := nextName(query, 0); i >= 0; i, j = nextName(query, j) {
+		name := query[i+1 : j]
+		if !isParam(name) {
+			continue
+		}
+		if start < i {
+			parts = append(parts, namedPart{text: query[start:i]})
+		}
+		parts = append(parts, namedPart{param: name})
+		start = j
+	}
+	if start < len(query) {
+		parts = append(parts, namedPart{text: query[start:]})
+	}
+	return parts
+}
+
+// nextName returns the bounds of the next `:name` in query from index from, with i at the colon
+// and j just past the name, or -1s if there are none.
+// Names are made of letters, digits, and underscores, and Postgres casts (`::type`) are skipped.
+func nextName(query string, from int) (i, j int) {
+	for i = from; i < len(query); i++ {
+		if query[i] != ':' {
+			continue
+		}
+		if i+1 < len(query) && query[i+1] == ':' {
+			i++ // skip cast
+			continue
+		}
+		j = i + 1
+		for j < len(query) && isNameByte(query[j]) {
+			j++
+		}
+		if j > i+1 {
+			return i, j
+		}
+	}
+	return -1, -1
+}
+
+func isNameByte(c byte) bool {
+	return c == '_' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9')
 }
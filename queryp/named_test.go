@@ -1,6 +1,7 @@
 package queryp
 
 import (
+	"sync"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -43,6 +44,24 @@ func TestNamed(t *testing.T) {
 			"SELECT * FROM test WHERE id = $1",
 			[]any{1},
 		},
+		"matches whole names": {
+			Named("SELECT * FROM test WHERE id = :id AND identity = :identity AND idx = :idx").Params(map[string]any{
+				"id":       1,
+				"identity": "me",
+			}),
+			"SELECT * FROM test WHERE id = ? AND identity = ? AND idx = :idx",
+			[]any{1, "me"},
+		},
+		"skips postgres casts": {
+			Named("SELECT :id::text, '1'::int").Params(map[string]any{"id": 1, "text": "no", "int": "no"}),
+			"SELECT ?::text, '1'::int",
+			[]any{1},
+		},
+		"supports offset placeholders": {
+			Named("SELECT * FROM test WHERE id = :id").WithPlaceholderer(PostgresPlaceholderer).WithOffset(2).Param("id", 1),
+			"SELECT * FROM test WHERE id = $3",
+			[]any{1},
+		},
 	}
 
 	for name, test := range tests {
@@ -56,3 +75,59 @@ func TestNamed(t *testing.T) {
 		})
 	}
 }
+
+func TestNamed_concurrent(t *testing.T) {
+	shared := Named("SELECT * FROM test WHERE id = :id AND name = :name").Params(map[string]any{
+		"id":   1,
+		"name": "Alice",
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			q, args := shared.Execute()
+			args = append(args, "extra") // must not affect other callers
+			if q != "SELECT * FROM test WHERE id = ? AND name = ?" || len(args) != 3 {
+				t.Errorf("unexpected shared execute: %v %v", q, args)
+			}
+		}()
+	}
+	wg.Wait()
+	if !cmp.Equal(shared.Args(), []any{1, "Alice"}) {
+		t.Errorf("shared args were changed: %v", shared.Args())
+	}
+}
+
+func TestNamed_Clone(t *testing.T) {
+	shared := Named("SELECT * FROM test WHERE id = :id").Param("id", 1)
+	clone := shared.Clone().Param("id", 2).WithPlaceholderer(PostgresPlaceholderer)
+
+	if q, args := shared.Execute(); q != "SELECT * FROM test WHERE id = ?" || !cmp.Equal(args, []any{1}) {
+		t.Errorf("original changed by clone: %v %v", q, args)
+	}
+	if q, args := clone.Execute(); q != "SELECT * FROM test WHERE id = $1" || !cmp.Equal(args, []any{2}) {
+		t.Errorf("clone unexpected: %v %v", q, args)
+	}
+}
+
+func BenchmarkNamed(b *testing.B) {
+	query := Named(`
+		SELECT * FROM people
+		WHERE first_name = :first_name AND last_name = :last_name AND created_at > :since::timestamp
+		ORDER BY :order LIMIT :limit
+	`).WithPlaceholderer(PostgresPlaceholderer).Params(map[string]any{
+		"first_name": "John",
+		"last_name":  "Doe",
+		"since":      "2020-01-01",
+		"limit":      10,
+		"unused_1":   1,
+		"unused_2":   2,
+	})
+	b.ReportAllocs()
+	for b.Loop() {
+		query.reset()
+		query.Execute()
+	}
+}
@@ -0,0 +1,129 @@
+package queryp
+
+import (
+	"strings"
+)
+
+// Predicates are a tiny library of common conditions as Fragments, a middle ground between raw
+// strings and a full DSL. Columns are written as is, so they must never come from user input --
+// only values are passed as arguments.
+//
+// Fragments can be used directly, or as NamedQuery (and so Template) param values, where they're
+// rendered inline in place of a placeholder:
+//
+//	queryp.Named("SELECT * FROM people WHERE :where").
+//		Param("where", queryp.And(queryp.Eq("last_name", "Doe"), queryp.Gt("age", 30)))
+//	// SELECT * FROM people WHERE (last_name = ? AND age > ?)
+
+// Eq renders `column = value`.
+func Eq(column string, value any) Fragment {
+	return compare(column, "=", value)
+}
+
+// Ne renders `column <> value`.
+func Ne(column string, value any) Fragment {
+	return compare(column, "<>", value)
+}
+
+// Lt renders `column < value`.
+func Lt(column string, value any) Fragment {
+	return compare(column, "<", value)
+}
+
+// Lte renders `column <= value`.
+func Lte(column string, value any) Fragment {
+	return compare(column, "<=", value)
+}
+
+// Gt renders `column > value`.
+func Gt(column string, value any) Fragment {
+	return compare(column, ">", value)
+}
+
+// Gte renders `column >= value`.
+func Gte(column string, value any) Fragment {
+	return compare(column, ">=", value)
+}
+
+// Like renders `column LIKE pattern`.
+func Like(column string, pattern string) Fragment {
+	return compare(column, "LIKE", pattern)
+}
+
+// Between renders `column BETWEEN low AND high`.
+func Between(column string, low, high any) Fragment {
+	return func(args *Args) string {
+		return column + " BETWEEN " + args.Add(low) + " AND " + args.Add(high)
+	}
+}
+
+// In renders `column IN (values...)`, with a placeholder per value.
+// No values renders an always false condition, rather than invalid SQL.
+func In[T any](column string, values []T) Fragment {
+	return func(args *Args) string {
+		if len(values) == 0 {
+			return "1=0"
+		}
+		placeholders := make([]string, len(values))
+		for i, v := range values {
+			placeholders[i] = args.Add(v)
+		}
+		return column + " IN (" + strings.Join(placeholders, ", ") + ")"
+	}
+}
+
+// Raw renders sql as is, replacing each `?` with a placeholder for the next of args.
+// Note `?` is replaced anywhere, including in string literals, so keep raw SQL simple.
+func Raw(sql string, args ...any) Fragment {
+	return func(a *Args) string {
+		b := strings.Builder{}
+		i := 0
+		for _, c := range sql {
+			if c == '?' && i < len(args) {
+				b.WriteString(a.Add(args[i]))
+				i++
+				continue
+			}
+			b.WriteRune(c)
+		}
+		return b.String()
+	}
+}
+
+// And renders all fragments joined with AND, skipping nil fragments, so optional conditions can be
+// passed in directly. No fragments renders an always true condition.
+func And(fragments ...Fragment) Fragment {
+	return join("AND", "1=1", fragments)
+}
+
+// Or renders all fragments joined with OR, skipping nil fragments. No fragments renders an always
+// false condition.
+func Or(fragments ...Fragment) Fragment {
+	return join("OR", "1=0", fragments)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+func compare(column, op string, value any) Fragment {
+	return func(args *Args) string {
+		return column + " " + op + " " + args.Add(value)
+	}
+}
+
+func join(op, empty string, fragments []Fragment) Fragment {
+	return func(args *Args) string {
+		parts := make([]string, 0, len(fragments))
+		for _, f := range fragments {
+			if f != nil {
+				parts = append(parts, f(args))
+			}
+		}
+		switch len(parts) {
+		case 0:
+			return empty
+		case 1:
+			return parts[0]
+		}
+		return "(" + strings.Join(parts, " "+op+" ") + ")"
+	}
+}
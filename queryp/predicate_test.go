@@ -0,0 +1,63 @@
+package queryp
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestPredicates(t *testing.T) {
+	tests := map[string]struct {
+		f            Fragment
+		expectedQ    string
+		expectedArgs []any
+	}{
+		"eq":      {Eq("id", 1), "id = $1", []any{1}},
+		"ne":      {Ne("id", 1), "id <> $1", []any{1}},
+		"lt":      {Lt("age", 30), "age < $1", []any{30}},
+		"lte":     {Lte("age", 30), "age <= $1", []any{30}},
+		"gt":      {Gt("age", 30), "age > $1", []any{30}},
+		"gte":     {Gte("age", 30), "age >= $1", []any{30}},
+		"like":    {Like("name", "A%"), "name LIKE $1", []any{"A%"}},
+		"between": {Between("age", 18, 30), "age BETWEEN $1 AND $2", []any{18, 30}},
+		"in":      {In("id", []int{1, 2, 3}), "id IN ($1, $2, $3)", []any{1, 2, 3}},
+		"in none": {In("id", []int{}), "1=0", nil},
+		"raw":     {Raw("age > ? AND age < ?", 18, 30), "age > $1 AND age < $2", []any{18, 30}},
+		"and": {
+			And(Eq("last_name", "Doe"), nil, Or(Gt("age", 30), Like("first_name", "J%"))),
+			"(last_name = $1 AND (age > $2 OR first_name LIKE $3))",
+			[]any{"Doe", 30, "J%"},
+		},
+		"and single": {And(Eq("id", 1)), "id = $1", []any{1}},
+		"and none":   {And(), "1=1", nil},
+		"or none":    {Or(nil), "1=0", nil},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			q, args := test.f.Execute(PostgresPlaceholderer)
+			if q != test.expectedQ {
+				t.Errorf("expected query %q, got %q", test.expectedQ, q)
+			}
+			if !cmp.Equal(args, test.expectedArgs) {
+				t.Errorf("unexpected args: %s", cmp.Diff(test.expectedArgs, args))
+			}
+		})
+	}
+}
+
+func TestPredicates_named(t *testing.T) {
+	q, args := Named("SELECT * FROM people WHERE id <> :id AND :where LIMIT :limit").
+		WithPlaceholderer(PostgresPlaceholderer).
+		Params(map[string]any{
+			"id":    1,
+			"where": And(Eq("last_name", "Doe"), In("age", []int{30, 40})),
+			"limit": 10,
+		}).
+		Execute()
+	if expected := "SELECT * FROM people WHERE id <> $1 AND (last_name = $2 AND age IN ($3, $4)) LIMIT $5"; q != expected {
+		t.Errorf("expected query %q, got %q", expected, q)
+	}
+	if expected := []any{1, "Doe", 30, 40, 10}; !cmp.Equal(args, expected) {
+		t.Errorf("unexpected args: %s", cmp.Diff(expected, args))
+	}
+}
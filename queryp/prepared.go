@@ -0,0 +1,81 @@
+package queryp
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PreparedQuery is a template executed ahead of time into a frozen query, leaving only binding
+// params per call. For hot queries, pair it with a prepared statement:
+//
+//	var getPerson = queryp.Must(queryp.NewTemplate(...)).Prepare("pet")
+//	...
+//	stmt, err := db.PrepareContext(ctx, getPerson.String()) // once
+//	args, err := getPerson.Args(map[string]any{"id": 1})   // per call
+//	rows, err := stmt.QueryContext(ctx, args...)
+//
+// A PreparedQuery is immutable, and safe for concurrent use.
+type PreparedQuery struct {
+	query string
+	names []string // param name for each placeholder, in order
+}
+
+// Prepare executes the template with the builder's includes into a PreparedQuery.
+// Params are those referenced with `.Param` in the template, plus any set on the builder (their
+// values are ignored, only binding matters).
+func (t *TemplateBuilder) Prepare() (*PreparedQuery, error) {
+	data := t.data()
+	data.referenced = map[string]bool{}
+	buffer := &strings.Builder{}
+	if err := t.Template.text.Execute(buffer, data); err != nil {
+		return nil, err
+	}
+
+	args := NewArgs().WithPlaceholderer(t.placeholderer).WithOffset(t.offset)
+	q := strings.Builder{}
+	var names []string
+	parts := parseNamed(buffer.String(), func(name string) bool {
+		_, ok := t.params[name]
+		return ok || data.referenced[name]
+	})
+	for _, part := range parts {
+		if part.param == "" {
+			q.WriteString(part.text)
+			continue
+		}
+		q.WriteString(args.Add(nil))
+		names = append(names, part.param)
+	}
+	return &PreparedQuery{query: q.String(), names: names}, nil
+}
+
+// Prepare executes the template with the given includes into a PreparedQuery.
+// Proxies to templateBuilder under the hood.
+func (t *Template) Prepare(includes ...string) (*PreparedQuery, error) {
+	return t.Build().Include(includes...).Prepare()
+}
+
+// String returns the frozen query.
+func (p *PreparedQuery) String() string {
+	return p.query
+}
+
+// Args binds params into the arguments for the query, erroring if any param is missing.
+// Fragment params are bound as is, since the query is frozen -- use Execute on a builder for those.
+func (p *PreparedQuery) Args(params map[string]any) ([]any, error) {
+	args := make([]any, len(p.names))
+	for i, name := range p.names {
+		v, ok := params[name]
+		if !ok {
+			return nil, fmt.Errorf("queryp: missing param %q", name)
+		}
+		args[i] = v
+	}
+	return args, nil
+}
+
+// Execute returns the query and arguments for the given params.
+func (p *PreparedQuery) Execute(params map[string]any) (string, []any, error) {
+	args, err := p.Args(params)
+	return p.query, args, err
+}
@@ -0,0 +1,65 @@
+package queryp
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestPrepare(t *testing.T) {
+	tmpl := Must(NewTemplate(`SELECT * FROM test{{if .Includes "pet"}} JOIN pets{{end}} ` +
+		`WHERE id = {{.Param "id"}} OR (name = :name AND :name <> '') AND created_at > :since::timestamp`))
+
+	tests := map[string]struct {
+		prepare      func() (*PreparedQuery, error)
+		params       map[string]any
+		expectedQ    string
+		expectedArgs []any
+		expectedErr  string
+	}{
+		"referenced params only": {
+			prepare:      func() (*PreparedQuery, error) { return tmpl.Prepare() },
+			params:       map[string]any{"id": 1},
+			expectedQ:    "SELECT * FROM test WHERE id = ? OR (name = :name AND :name <> '') AND created_at > :since::timestamp",
+			expectedArgs: []any{1},
+		},
+		"with includes": {
+			prepare:      func() (*PreparedQuery, error) { return tmpl.Prepare("pet") },
+			params:       map[string]any{"id": 1},
+			expectedQ:    "SELECT * FROM test JOIN pets WHERE id = ? OR (name = :name AND :name <> '') AND created_at > :since::timestamp",
+			expectedArgs: []any{1},
+		},
+		"builder params shape": {
+			prepare: func() (*PreparedQuery, error) {
+				return tmpl.Placeholderer(PostgresPlaceholderer).Params(map[string]any{"name": nil, "since": nil}).Prepare()
+			},
+			params:       map[string]any{"id": 1, "name": "Alice", "since": "2020-01-01"},
+			expectedQ:    "SELECT * FROM test WHERE id = $1 OR (name = $2 AND $3 <> '') AND created_at > $4::timestamp",
+			expectedArgs: []any{1, "Alice", "Alice", "2020-01-01"},
+		},
+		"missing param": {
+			prepare:     func() (*PreparedQuery, error) { return tmpl.Prepare() },
+			params:      map[string]any{"name": "Alice"},
+			expectedQ:   "SELECT * FROM test WHERE id = ? OR (name = :name AND :name <> '') AND created_at > :since::timestamp",
+			expectedErr: `queryp: missing param "id"`,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			p, err := test.prepare()
+			if err != nil {
+				t.Fatalf("failed to prepare: %v", err)
+			}
+			q, args, err := p.Execute(test.params)
+			if q != test.expectedQ {
+				t.Errorf("expected query %q, got %q", test.expectedQ, q)
+			}
+			if (err == nil && test.expectedErr != "") || (err != nil && err.Error() != test.expectedErr) {
+				t.Errorf("expected error %q, got %v", test.expectedErr, err)
+			}
+			if !cmp.Equal(args, test.expectedArgs) {
+				t.Errorf("unexpected args: %s", cmp.Diff(test.expectedArgs, args))
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package queryp
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SearchTerm is a full text search of user entered text over some columns.
+// Matching semantics are up to each database, but in all cases all words of Query are searched for
+// without interpreting any search syntax.
+type SearchTerm struct {
+	Query   string   // The text to search for
+	Columns []string // The columns to search
+	Table   string   // SQLite only: the FTS5 table to match against, required for multiple columns
+	Config  string   // Postgres only: text search configuration, eg. "english"
+}
+
+// Search renders a full text search predicate for the dialect:
+//   - Postgres: `to_tsvector(...) @@ plainto_tsquery(...)`, requiring Columns
+//   - SQLite: `table MATCH ...` against an FTS5 table
+//   - MySQL: `MATCH (...) AGAINST (... IN NATURAL LANGUAGE MODE)`, requiring Columns with a FULLTEXT index
+func Search(d Dialect, term SearchTerm) Fragment {
+	return func(args *Args) string {
+		switch d {
+		case Postgres:
+			term.requireColumns(d)
+			config := ""
+			if term.Config != "" {
+				config = quoteLiteral(term.Config) + ", "
+			}
+			doc := term.Columns[0]
+			if len(term.Columns) > 1 {
+				coalesced := make([]string, len(term.Columns))
+				for i, col := range term.Columns {
+					coalesced[i] = fmt.Sprintf("coalesce(%s, '')", col)
+				}
+				doc = strings.Join(coalesced, " || ' ' || ")
+			}
+			return fmt.Sprintf("to_tsvector(%s%s) @@ plainto_tsquery(%s%s)", config, doc, config, args.Add(term.Query))
+		case Sqlite:
+			// Quote each word as an FTS5 string, so user input can't use (or break on) query syntax.
+			words := strings.Fields(term.Query)
+			for i, w := range words {
+				words[i] = `"` + strings.ReplaceAll(w, `"`, `""`) + `"`
+			}
+			query := strings.Join(words, " ")
+			target := term.Table
+			switch {
+			case target == "" && len(term.Columns) == 1:
+				target = term.Columns[0]
+			case target == "":
+				panic("queryp: sqlite search over multiple columns requires the FTS5 Table")
+			case len(term.Columns) > 0:
+				// A filter only applies to the phrase after it, so group the words
+				query = "{" + strings.Join(term.Columns, " ") + "} : (" + query + ")"
+			}
+			return fmt.Sprintf("%s MATCH %s", target, args.Add(query))
+		case MySQL:
+			term.requireColumns(d)
+			return fmt.Sprintf(
+				"MATCH (%s) AGAINST (%s IN NATURAL LANGUAGE MODE)",
+				strings.Join(term.Columns, ", "), args.Add(term.Query),
+			)
+		default:
+			return d.unsupported("Search")
+		}
+	}
+}
+
+// requireColumns panics if the term has no columns, which d can't search without.
+func (term SearchTerm) requireColumns(d Dialect) {
+	if len(term.Columns) == 0 {
+		panic(fmt.Sprintf("queryp: %s search requires Columns", d))
+	}
+}
+
+// quoteLiteral quotes s as a standard SQL string literal.
+func quoteLiteral(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
@@ -0,0 +1,78 @@
+package queryp
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSearch(t *testing.T) {
+	tests := map[string]struct {
+		d            Dialect
+		term         SearchTerm
+		expectedQ    string
+		expectedArgs []any
+	}{
+		"postgres single column": {
+			Postgres,
+			SearchTerm{Query: "big dog", Columns: []string{"name"}},
+			"to_tsvector(name) @@ plainto_tsquery($1)",
+			[]any{"big dog"},
+		},
+		"postgres multiple columns with config": {
+			Postgres,
+			SearchTerm{Query: "big dog", Columns: []string{"name", "bio"}, Config: "english"},
+			"to_tsvector('english', coalesce(name, '') || ' ' || coalesce(bio, '')) @@ plainto_tsquery('english', $1)",
+			[]any{"big dog"},
+		},
+		"sqlite single column": {
+			Sqlite,
+			SearchTerm{Query: `big "dog"`, Columns: []string{"name"}},
+			"name MATCH ?",
+			[]any{`"big" """dog"""`},
+		},
+		"sqlite table with column filter": {
+			Sqlite,
+			SearchTerm{Query: "big dog", Columns: []string{"name", "bio"}, Table: "pets_fts"},
+			"pets_fts MATCH ?",
+			[]any{`{name bio} : ("big" "dog")`},
+		},
+		"mysql": {
+			MySQL,
+			SearchTerm{Query: "big dog", Columns: []string{"name", "bio"}},
+			"MATCH (name, bio) AGAINST (? IN NATURAL LANGUAGE MODE)",
+			[]any{"big dog"},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			q, args := Search(test.d, test.term).Execute(test.d.Placeholderer())
+			if q != test.expectedQ {
+				t.Errorf("expected query %q, got %q", test.expectedQ, q)
+			}
+			if !cmp.Equal(args, test.expectedArgs) {
+				t.Errorf("unexpected args: %s", cmp.Diff(test.expectedArgs, args))
+			}
+		})
+	}
+
+	t.Run("unsupported dialect panics", func(t *testing.T) {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Errorf("expected panic")
+			}
+		}()
+		Search(Dialect("oracle"), SearchTerm{Query: "dog", Columns: []string{"name"}}).Execute(nil)
+	})
+
+	for _, d := range []Dialect{Postgres, MySQL} {
+		t.Run(string(d)+" without columns panics", func(t *testing.T) {
+			defer func() {
+				if r := recover(); r != "queryp: "+string(d)+" search requires Columns" {
+					t.Errorf("expected panic for no columns, got %v", r)
+				}
+			}()
+			Search(d, SearchTerm{Query: "dog"}).Execute(d.Placeholderer())
+		})
+	}
+}
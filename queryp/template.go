@@ -3,13 +3,20 @@ package queryp
 import (
 	"bytes"
 	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
 	"text/template"
 )
 
 // Template represents a SQL template.
-// Any method on Template spins off a mutable builder so this can be re-used freely.
+// Any method on Template spins off a mutable builder so this can be re-used freely, including
+// concurrently (eg. as a package level var).
 type Template struct {
-	text *template.Template
+	text  *template.Template
+	cache *sync.Map           // rendered text by shape, if caching
+	deps  map[string][]string // includes implied by each include
 }
 
 func NewTemplate(text string) (*Template, error) {
@@ -30,6 +37,37 @@ func Must(t *Template, err error) *Template {
 	return t
 }
 
+// WithCache returns a copy of the template that caches rendered text by shape -- the set of
+// includes and which params are present -- so repeated Executes with the same shape skip
+// text/template execution, only binding params.
+// Only use this for templates whose output doesn't depend on param values (eg. `{{if eq ...}}`
+// against a param), as those would be served stale renders.
+func (t *Template) WithCache() *Template {
+	c := t.clone()
+	c.cache = &sync.Map{}
+	return c
+}
+
+// DependsOn returns a copy of the template where including `include` also includes `deps`
+// (transitively), eg. DependsOn("child_pet", "child") so a pet join can rely on the child join.
+func (t *Template) DependsOn(include string, deps ...string) *Template {
+	c := t.clone()
+	c.deps = maps.Clone(t.deps)
+	if c.deps == nil {
+		c.deps = make(map[string][]string)
+	}
+	c.deps[include] = append(slices.Clip(c.deps[include]), deps...)
+	if t.cache != nil {
+		c.cache = &sync.Map{} // renders may differ now
+	}
+	return c
+}
+
+func (t *Template) clone() *Template {
+	c := *t
+	return &c
+}
+
 // Build returns a TemplateBuilder that can be used to build custom data for the template.
 func (t *Template) Build() *TemplateBuilder {
 	return newTemplateBuilder(t)
@@ -41,6 +79,12 @@ func (t *Template) Placeholderer(p Placeholderer) *TemplateBuilder {
 	return t.Build().Placeholderer(p)
 }
 
+// Offset starts placeholders after n other arguments (see Args.WithOffset).
+// Proxies to templateBuilder under the hood.
+func (t *Template) Offset(n int) *TemplateBuilder {
+	return t.Build().Offset(n)
+}
+
 // Param sets a named parameter value.
 // Proxies to templateBuilder under the hood.
 func (t *Template) Param(key string, val any) *TemplateBuilder {
@@ -67,11 +111,15 @@ func (t *Template) Execute() (string, []any, error) {
 
 ////////////////////////////////////////////////////////////////////////////////
 
+// TemplateBuilder builds up data to execute a Template with.
+// Like NamedQuery, it's safe to Execute concurrently once setup, but setters are not safe for
+// concurrent use -- Clone a shared builder first to customize it per use.
 type TemplateBuilder struct {
 	*Template
 	params        map[string]any  // Store parameters
 	includes      map[string]bool // Store included associations
 	placeholderer Placeholderer
+	offset        int
 }
 
 func newTemplateBuilder(t *Template) *TemplateBuilder {
@@ -82,11 +130,27 @@ func newTemplateBuilder(t *Template) *TemplateBuilder {
 	}
 }
 
+// Clone returns a copy of the builder that can be changed independently.
+func (t *TemplateBuilder) Clone() *TemplateBuilder {
+	return &TemplateBuilder{
+		Template:      t.Template,
+		params:        maps.Clone(t.params),
+		includes:      maps.Clone(t.includes),
+		placeholderer: t.placeholderer,
+		offset:        t.offset,
+	}
+}
+
 func (t *TemplateBuilder) Placeholderer(p Placeholderer) *TemplateBuilder {
 	t.placeholderer = p
 	return t
 }
 
+func (t *TemplateBuilder) Offset(n int) *TemplateBuilder {
+	t.offset = n
+	return t
+}
+
 func (t *TemplateBuilder) Param(key string, val any) *TemplateBuilder {
 	return t.Params(map[string]any{key: val})
 }
@@ -106,32 +170,91 @@ func (t *TemplateBuilder) Include(associations ...string) *TemplateBuilder {
 }
 
 func (t *TemplateBuilder) Execute() (string, []any, error) {
-	data := &templateData{
-		params:   t.params,
-		includes: t.includes,
-	}
-	buffer := &bytes.Buffer{}
-	err := t.Template.text.Execute(buffer, data)
+	text, err := t.render()
 	if err != nil {
 		return "", nil, err
 	}
 	// We also support NamedQuery style, which can be applied post template execution
-	q, args := Named(buffer.String()).
+	q, args := Named(text).
 		WithPlaceholderer(t.placeholderer).
+		WithOffset(t.offset).
 		Params(t.params).
 		Execute()
 	return q, args, nil
 }
 
+// render executes the text template, through the cache if enabled.
+func (t *TemplateBuilder) render() (string, error) {
+	var key string
+	if t.cache != nil {
+		key = t.shape()
+		if text, ok := t.cache.Load(key); ok {
+			return text.(string), nil
+		}
+	}
+	buffer := &bytes.Buffer{}
+	if err := t.Template.text.Execute(buffer, t.data()); err != nil {
+		return "", err
+	}
+	text := buffer.String()
+	if t.cache != nil {
+		t.cache.Store(key, text)
+	}
+	return text, nil
+}
+
+// data returns the data to execute the template against.
+func (t *TemplateBuilder) data() *templateData {
+	return &templateData{
+		params:   t.params,
+		includes: t.resolveIncludes(),
+	}
+}
+
+// resolveIncludes returns the included associations along with all their dependencies.
+func (t *TemplateBuilder) resolveIncludes() map[string]bool {
+	if len(t.deps) == 0 {
+		return t.includes
+	}
+	resolved := make(map[string]bool, len(t.includes))
+	var visit func(include string)
+	visit = func(include string) {
+		if resolved[include] {
+			return
+		}
+		resolved[include] = true
+		for _, dep := range t.deps[include] {
+			visit(dep)
+		}
+	}
+	for include := range t.includes {
+		visit(include)
+	}
+	return resolved
+}
+
+// shape identifies the includes and params present, which is all a cacheable render depends on.
+func (t *TemplateBuilder) shape() string {
+	includes := slices.Sorted(maps.Keys(t.includes))
+	params := slices.Sorted(maps.Keys(t.params))
+	return strings.Join(includes, "\x00") + "\x01" + strings.Join(params, "\x00")
+}
+
 ////////////////////////////////////////////////////////////////////////////////
 
 // templateData is the data object a template will be executed against.
 type templateData struct {
 	params   map[string]any
 	includes map[string]bool
+	// When preparing, every param is treated as present, and those referenced are tracked.
+	referenced map[string]bool
 }
 
 func (t *templateData) Param(key string) string {
+	if t.referenced != nil {
+		t.referenced[key] = true
+		return fmt.Sprintf(":%s", key)
+	}
 	if _, ok := t.params[key]; ok {
 		return fmt.Sprintf(":%s", key)
 	}
@@ -159,3 +282,8 @@ func (t *templateData) Includes(keys ...string) bool {
 func (t *templateData) Include(keys ...string) bool {
 	return t.Includes(keys...)
 }
+
+// Included returns all included associations, dependencies included, in sorted order.
+func (t *templateData) Included() []string {
+	return slices.Sorted(maps.Keys(t.includes))
+}
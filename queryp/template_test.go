@@ -1,6 +1,7 @@
 package queryp
 
 import (
+	"sync"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -48,6 +49,14 @@ func TestTemplate(t *testing.T) {
 			"SELECT * FROM test WHERE id = $1",
 			[]any{1},
 		},
+		"supports offset placeholders": {
+			Must(NewTemplate("SELECT * FROM test WHERE id = :id")).
+				Placeholderer(PostgresPlaceholderer).
+				Offset(2).
+				Param("id", 1),
+			"SELECT * FROM test WHERE id = $3",
+			[]any{1},
+		},
 		"supports Param function": {
 			Must(NewTemplate(`SELECT * FROM test WHERE id = {{.Param "id"}}`)).
 				Param("id", 1),
@@ -76,3 +85,104 @@ func TestTemplate(t *testing.T) {
 		})
 	}
 }
+
+func TestTemplate_concurrent(t *testing.T) {
+	shared := Must(NewTemplate(`SELECT * FROM test{{if .Includes "pet"}} JOIN pets{{end}} WHERE id = :id`))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			b := shared.Param("id", i)
+			expected := "SELECT * FROM test WHERE id = ?"
+			if i%2 == 0 {
+				b.Include("pet")
+				expected = "SELECT * FROM test JOIN pets WHERE id = ?"
+			}
+			q, args, err := b.Execute()
+			if err != nil || q != expected || !cmp.Equal(args, []any{i}) {
+				t.Errorf("unexpected shared execute: %v %v %v", q, args, err)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestTemplateBuilder_Clone(t *testing.T) {
+	base := Must(NewTemplate(`SELECT * FROM test{{if .Includes "pet"}} JOIN pets{{end}} WHERE id = :id`)).
+		Param("id", 1)
+	clone := base.Clone().Include("pet").Param("id", 2)
+
+	if q, args, _ := base.Execute(); q != "SELECT * FROM test WHERE id = ?" || !cmp.Equal(args, []any{1}) {
+		t.Errorf("original changed by clone: %v %v", q, args)
+	}
+	if q, args, _ := clone.Execute(); q != "SELECT * FROM test JOIN pets WHERE id = ?" || !cmp.Equal(args, []any{2}) {
+		t.Errorf("clone unexpected: %v %v", q, args)
+	}
+}
+
+func TestTemplate_WithCache(t *testing.T) {
+	// Render the param value itself, so we can see when a cached render is served
+	uncached := Must(NewTemplate(`SELECT {{index .Params "v"}}{{if .Includes "pet"}} JOIN pets{{end}} WHERE id = :v`))
+	cached := uncached.WithCache()
+
+	execute := func(tmpl *Template, v int, includes ...string) string {
+		q, args, err := tmpl.Include(includes...).Param("v", v).Execute()
+		if err != nil || !cmp.Equal(args, []any{v}) {
+			t.Fatalf("unexpected execute: %v %v", args, err)
+		}
+		return q
+	}
+	if q := execute(cached, 1); q != "SELECT 1 WHERE id = ?" {
+		t.Errorf("unexpected first render: %v", q)
+	}
+	if q := execute(cached, 2); q != "SELECT 1 WHERE id = ?" {
+		t.Errorf("expected cached render for same shape, got: %v", q)
+	}
+	if q := execute(cached, 3, "pet"); q != "SELECT 3 JOIN pets WHERE id = ?" {
+		t.Errorf("expected fresh render for new shape, got: %v", q)
+	}
+	if q := execute(uncached, 2); q != "SELECT 2 WHERE id = ?" {
+		t.Errorf("original template should not cache, got: %v", q)
+	}
+}
+
+func TestTemplate_DependsOn(t *testing.T) {
+	base := Must(NewTemplate(`SELECT *{{range .Included}} {{.}}{{end}} FROM people p` +
+		`{{if .Includes "child"}} JOIN people c{{end}}{{if .Includes "child_pet"}} JOIN pets cp{{end}}`))
+	tmpl := base.DependsOn("child_pet", "child").DependsOn("child", "parent")
+
+	tests := map[string]struct {
+		t         *TemplateBuilder
+		expectedQ string
+	}{
+		"no includes": {
+			tmpl.Build(),
+			"SELECT * FROM people p",
+		},
+		"direct dependency": {
+			tmpl.Include("child"),
+			"SELECT * child parent FROM people p JOIN people c",
+		},
+		"transitive dependencies": {
+			tmpl.Include("child_pet"),
+			"SELECT * child child_pet parent FROM people p JOIN people c JOIN pets cp",
+		},
+		"original template unchanged": {
+			base.Include("child_pet"),
+			"SELECT * child_pet FROM people p JOIN pets cp",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			q, _, err := test.t.Execute()
+			if err != nil {
+				t.Fatalf("failed to execute: %v", err)
+			}
+			if q != test.expectedQ {
+				t.Errorf("expected query %q, got %q", test.expectedQ, q)
+			}
+		})
+	}
+}
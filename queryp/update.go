@@ -0,0 +1,95 @@
+package queryp
+
+import (
+	"strings"
+)
+
+// UpdateFrom is a correlated update, setting columns of Table from the rows of From that join to
+// it, eg. copying a denormalized column from a related table.
+type UpdateFrom struct {
+	Table  string       // The table to update
+	As     string       // Optional alias for Table
+	From   string       // The table to update from
+	FromAs string       // Optional alias for From
+	On     Fragment     // How From joins to Table, eg. Raw("pet.parent_id = p.id"), required
+	Set    []Assignment // Columns of Table to set
+	Where  Fragment     // Optional further condition
+}
+
+// Assignment sets a column to a value, as part of an UPDATE.
+// Fragment values are rendered inline (eg. Raw("pet.name") to refer to another column), and
+// anything else as an argument.
+type Assignment struct {
+	Column string
+	Value  any
+}
+
+// Set returns an Assignment of value to column.
+func Set(column string, value any) Assignment {
+	return Assignment{Column: column, Value: value}
+}
+
+// Update renders a correlated update for the dialect:
+//   - Postgres and SQLite: `UPDATE table SET ... FROM from WHERE on AND where`
+//   - MySQL: `UPDATE table JOIN from ON on SET ... WHERE where`, qualifying set columns by table
+func Update(d Dialect, u UpdateFrom) Fragment {
+	return func(args *Args) string {
+		if u.On == nil {
+			// Without it, every row of Table would be updated from an arbitrary row of From
+			panic("queryp: Update requires On, to join From to Table")
+		}
+		b := strings.Builder{}
+		b.WriteString("UPDATE ")
+		b.WriteString(aliased(u.Table, u.As))
+		switch d {
+		case Postgres, Sqlite:
+			b.WriteString(" SET ")
+			b.WriteString(u.assignments(args, ""))
+			b.WriteString(" FROM ")
+			b.WriteString(aliased(u.From, u.FromAs))
+			b.WriteString(" WHERE ")
+			b.WriteString(And(u.On, u.Where)(args))
+		case MySQL:
+			b.WriteString(" JOIN ")
+			b.WriteString(aliased(u.From, u.FromAs))
+			b.WriteString(" ON ")
+			b.WriteString(u.On(args))
+			b.WriteString(" SET ")
+			qualifier := u.Table
+			if u.As != "" {
+				qualifier = u.As
+			}
+			b.WriteString(u.assignments(args, qualifier+"."))
+			if u.Where != nil {
+				b.WriteString(" WHERE ")
+				b.WriteString(u.Where(args))
+			}
+		default:
+			return d.unsupported("Update")
+		}
+		return b.String()
+	}
+}
+
+func (u UpdateFrom) assignments(args *Args, qualifier string) string {
+	sets := make([]string, len(u.Set))
+	for i, a := range u.Set {
+		sets[i] = qualifier + a.Column + " = " + value(args, a.Value)
+	}
+	return strings.Join(sets, ", ")
+}
+
+// value renders a value inline if it's a Fragment, or as an argument otherwise.
+func value(args *Args, v any) string {
+	if f, ok := v.(Fragment); ok {
+		return f(args)
+	}
+	return args.Add(v)
+}
+
+func aliased(table, as string) string {
+	if as == "" {
+		return table
+	}
+	return table + " AS " + as
+}
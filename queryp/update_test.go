@@ -0,0 +1,73 @@
+package queryp
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestUpdate(t *testing.T) {
+	u := UpdateFrom{
+		Table: "people", As: "p",
+		From: "pets", FromAs: "pet",
+		On:    Raw("pet.parent_id = p.id"),
+		Set:   []Assignment{Set("pet_name", Raw("pet.name")), Set("updated_by", "sync")},
+		Where: Eq("pet.type", "Dog"),
+	}
+	tests := map[string]struct {
+		d            Dialect
+		u            UpdateFrom
+		expectedQ    string
+		expectedArgs []any
+	}{
+		"postgres": {
+			Postgres, u,
+			"UPDATE people AS p SET pet_name = pet.name, updated_by = $1 FROM pets AS pet WHERE (pet.parent_id = p.id AND pet.type = $2)",
+			[]any{"sync", "Dog"},
+		},
+		"sqlite": {
+			Sqlite, u,
+			"UPDATE people AS p SET pet_name = pet.name, updated_by = ? FROM pets AS pet WHERE (pet.parent_id = p.id AND pet.type = ?)",
+			[]any{"sync", "Dog"},
+		},
+		"mysql": {
+			MySQL, u,
+			"UPDATE people AS p JOIN pets AS pet ON pet.parent_id = p.id SET p.pet_name = pet.name, p.updated_by = ? WHERE pet.type = ?",
+			[]any{"sync", "Dog"},
+		},
+		"no aliases or where": {
+			MySQL,
+			UpdateFrom{
+				Table: "people", From: "pets",
+				On:  Raw("pets.parent_id = people.id"),
+				Set: []Assignment{Set("pet_name", Raw("pets.name"))},
+			},
+			"UPDATE people JOIN pets ON pets.parent_id = people.id SET people.pet_name = pets.name",
+			nil,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			q, args := Update(test.d, test.u).Execute(test.d.Placeholderer())
+			if q != test.expectedQ {
+				t.Errorf("expected query %q, got %q", test.expectedQ, q)
+			}
+			if !cmp.Equal(args, test.expectedArgs) {
+				t.Errorf("unexpected args: %s", cmp.Diff(test.expectedArgs, args))
+			}
+		})
+	}
+
+	for _, d := range []Dialect{Postgres, MySQL} {
+		t.Run(string(d)+" without on panics", func(t *testing.T) {
+			defer func() {
+				if r := recover(); r != "queryp: Update requires On, to join From to Table" {
+					t.Errorf("expected panic for no on, got %v", r)
+				}
+			}()
+			u := u
+			u.On = nil
+			Update(d, u).Execute(d.Placeholderer())
+		})
+	}
+}
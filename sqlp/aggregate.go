@@ -0,0 +1,87 @@
+package sqlp
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/greghart/powerputtygo/queryp"
+)
+
+// Number is any numeric type an aggregation can be scanned into.
+type Number interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 |
+		~float32 | ~float64
+}
+
+// Aggregation is an aggregate function over a column, for use with Aggregate.
+type Aggregation struct {
+	fn       string
+	column   string
+	notFound bool
+}
+
+// Sum aggregates the sum of column.
+func Sum(column string) Aggregation {
+	return Aggregation{fn: "SUM", column: column}
+}
+
+// Min aggregates the minimum of column.
+func Min(column string) Aggregation {
+	return Aggregation{fn: "MIN", column: column}
+}
+
+// Max aggregates the maximum of column.
+func Max(column string) Aggregation {
+	return Aggregation{fn: "MAX", column: column}
+}
+
+// Avg aggregates the average of column.
+func Avg(column string) Aggregation {
+	return Aggregation{fn: "AVG", column: column}
+}
+
+// OrNotFound makes a NULL aggregate (ie. no matching rows) return ErrNotFound instead of zero.
+func (a Aggregation) OrNotFound() Aggregation {
+	a.notFound = true
+	return a
+}
+
+// String returns the aggregation as a SQL expression, eg. `SUM(amount)`.
+func (a Aggregation) String() string {
+	return fmt.Sprintf("%s(%s)", a.fn, a.column)
+}
+
+// Aggregate runs the aggregation against the repository's table, filtered by the optional where
+// clause (written with the dialect's placeholders, as for raw queries), and scans the result into N.
+// If no rows match, the result is zero, unless the aggregation is set to OrNotFound.
+//
+//	total, err := sqlp.Aggregate[int64](ctx, repo, sqlp.Sum("amount"), "user_id = ?", userID)
+func Aggregate[N Number, E any](
+	ctx context.Context, r *Repository[E], agg Aggregation, where string, args ...any,
+) (N, error) {
+	q := "SELECT " + agg.String() + " FROM " + r.QualifiedTable()
+	var cond queryp.Fragment
+	if where != "" {
+		cond = func(a *queryp.Args) string {
+			for _, arg := range args {
+				a.Add(arg)
+			}
+			return where
+		}
+	}
+	if cond != nil || r.discriminator != nil {
+		where, args = r.where(cond)
+		q += " WHERE " + where
+	}
+
+	var out sql.Null[N]
+	if err := r.QueryRow(ctx, q, args...).Scan(&out); err != nil {
+		return out.V, fmt.Errorf("failed to aggregate %v: %w", agg, err)
+	}
+	if !out.Valid && agg.notFound {
+		return out.V, ErrNotFound
+	}
+	return out.V, nil
+}
@@ -0,0 +1,72 @@
+package sqlp
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestAggregate(t *testing.T) {
+	db, ctx, cleanup := testDB(t)
+	defer cleanup()
+
+	repository := NewRepository[person](db, "people")
+	grandparent := grandchildrenSetup(ctx, db)
+	ids := []int64{grandparent.ID, grandparent.Child.ID, grandparent.Child.Child.ID}
+
+	t.Run("sum", func(t *testing.T) {
+		sum, err := Aggregate[int64](ctx, repository, Sum("id"), "")
+		if err != nil {
+			t.Fatalf("failed to aggregate: %v", err)
+		}
+		if expected := ids[0] + ids[1] + ids[2]; sum != expected {
+			t.Errorf("sum %d, expected %d", sum, expected)
+		}
+	})
+
+	t.Run("min with where", func(t *testing.T) {
+		got, err := Aggregate[int](ctx, repository, Min("id"), "parent_id IS NOT NULL")
+		if err != nil {
+			t.Fatalf("failed to aggregate: %v", err)
+		}
+		if expected := int(ids[1]); got != expected {
+			t.Errorf("min %d, expected %d", got, expected)
+		}
+	})
+
+	t.Run("max with args", func(t *testing.T) {
+		got, err := Aggregate[int64](ctx, repository, Max("id"), "first_name = ?", "Lil Johnnie")
+		if err != nil {
+			t.Fatalf("failed to aggregate: %v", err)
+		}
+		if got != ids[1] {
+			t.Errorf("max %d, expected %d", got, ids[1])
+		}
+	})
+
+	t.Run("avg into float", func(t *testing.T) {
+		avg, err := Aggregate[float64](ctx, repository, Avg("id"), "")
+		if err != nil {
+			t.Fatalf("failed to aggregate: %v", err)
+		}
+		if expected := float64(ids[0]+ids[1]+ids[2]) / 3; avg != expected {
+			t.Errorf("avg %v, expected %v", avg, expected)
+		}
+	})
+
+	t.Run("no rows -> zero", func(t *testing.T) {
+		sum, err := Aggregate[int64](ctx, repository, Sum("id"), "1=0")
+		if err != nil {
+			t.Fatalf("failed to aggregate: %v", err)
+		}
+		if sum != 0 {
+			t.Errorf("sum %d, expected 0", sum)
+		}
+	})
+
+	t.Run("no rows -> ErrNotFound", func(t *testing.T) {
+		_, err := Aggregate[int64](ctx, repository, Sum("id").OrNotFound(), "1=0")
+		if !errors.Is(err, ErrNotFound) {
+			t.Errorf("got %v, expected ErrNotFound", err)
+		}
+	})
+}
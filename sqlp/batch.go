@@ -0,0 +1,128 @@
+package sqlp
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// BatchOption configures batch write helpers, eg. UpdateWhereIDs and ExecBatch.
+type BatchOption int
+
+const (
+	// SkipFailedRows runs each chunk of a batch in a savepoint, and retries a failed chunk row by row
+	// (each in its own savepoint) so only the rows that fail are skipped. The rest of the batch
+	// commits, and the failed rows are reported in a *BatchError.
+	SkipFailedRows BatchOption = iota + 1
+)
+
+// BatchError reports the rows of a batch that failed, when the rest of the batch succeeded.
+// It unwraps to its failures, so errors.Is and errors.As match any failure's cause, eg.
+//
+//	if errors.Is(err, ErrEmailTaken) { ... } // some row's email was taken
+//	var failure *sqlp.BatchFailure
+//	if errors.As(err, &failure) { ... } // the first failure
+type BatchError struct {
+	Failures []*BatchFailure
+}
+
+func (e *BatchError) Error() string {
+	failures := make([]string, len(e.Failures))
+	for i, f := range e.Failures {
+		failures[i] = f.Error()
+	}
+	return fmt.Sprintf("sqlp: %d rows of batch failed: %s", len(e.Failures), strings.Join(failures, "; "))
+}
+
+// Unwrap returns the failures.
+func (e *BatchError) Unwrap() []error {
+	errs := make([]error, len(e.Failures))
+	for i, f := range e.Failures {
+		errs[i] = f
+	}
+	return errs
+}
+
+// Keys returns the keys of the failed rows, eg. to retry them.
+func (e *BatchError) Keys() []any {
+	keys := make([]any, len(e.Failures))
+	for i, f := range e.Failures {
+		keys[i] = f.Key
+	}
+	return keys
+}
+
+// BatchFailure is a failed row of a batch.
+type BatchFailure struct {
+	Index int // Index of the row in the batch
+	Key   any // Key of the row's entity, eg. its id
+	Err   error
+}
+
+func (f *BatchFailure) Error() string {
+	return fmt.Sprintf("%d (%v): %v", f.Index, f.Key, f.Err)
+}
+
+// Unwrap returns the cause of the failure.
+func (f *BatchFailure) Unwrap() error {
+	return f.Err
+}
+
+// Statement is a query and its args, eg. for ExecBatch.
+type Statement struct {
+	Query string
+	Args  []any
+}
+
+// ExecBatch runs statements in order in one transaction (the context's, if any), returning each
+// statement's result. The first failure rolls the batch back, returning a *BatchFailure with the
+// failed statement's index.
+//
+// With SkipFailedRows, each statement runs in a savepoint instead, so failed statements are skipped
+// (with a nil result) and reported in a *BatchError, while the rest commit.
+//
+//	results, err := db.ExecBatch(ctx, []sqlp.Statement{
+//		{Query: "UPDATE people SET last_name = ? WHERE id = ?", Args: []any{"Doe", 1}},
+//		{Query: "DELETE FROM pets WHERE parent_id = ?", Args: []any{1}},
+//	})
+func (db *DB) ExecBatch(ctx context.Context, stmts []Statement, opts ...BatchOption) ([]sql.Result, error) {
+	results := make([]sql.Result, len(stmts))
+	var failures []*BatchFailure
+	skip := hasBatchOption(opts, SkipFailedRows)
+	err := db.RunInTx(ctx, func(ctx context.Context) error {
+		for i, stmt := range stmts {
+			exec := func(ctx context.Context) (err error) {
+				results[i], err = db.Exec(ctx, stmt.Query, stmt.Args...)
+				return err
+			}
+			if !skip {
+				if err := exec(ctx); err != nil {
+					return &BatchFailure{Index: i, Key: stmt.Query, Err: err}
+				}
+				continue
+			}
+			if err := db.RunInSavepoint(ctx, exec); err != nil {
+				failures = append(failures, &BatchFailure{Index: i, Key: stmt.Query, Err: err})
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(failures) > 0 {
+		return results, &BatchError{Failures: failures}
+	}
+	return results, nil
+}
+
+// hasBatchOption returns whether opts contains opt.
+func hasBatchOption(opts []BatchOption, opt BatchOption) bool {
+	for _, o := range opts {
+		if o == opt {
+			return true
+		}
+	}
+	return false
+}
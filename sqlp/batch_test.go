@@ -0,0 +1,96 @@
+package sqlp
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/greghart/powerputtygo/errcmp"
+)
+
+func TestBatchError(t *testing.T) {
+	errTaken := errors.New("taken")
+	err := error(&BatchError{Failures: []*BatchFailure{
+		{Index: 1, Key: int64(2), Err: errors.New("boom")},
+		{Index: 3, Key: "four", Err: errTaken},
+	}})
+
+	errcmp.MustMatch(t, err, "sqlp: 2 rows of batch failed: 1 (2): boom; 3 (four): taken")
+	if !errors.Is(err, errTaken) {
+		t.Errorf("expected to match a failure's cause")
+	}
+	var failure *BatchFailure
+	if !errors.As(err, &failure) || failure.Index != 1 {
+		t.Errorf("expected first failure, got %+v", failure)
+	}
+	var batchErr *BatchError
+	if !errors.As(err, &batchErr) {
+		t.Fatalf("expected a BatchError")
+	}
+	if expected := []any{int64(2), "four"}; !cmp.Equal(batchErr.Keys(), expected) {
+		t.Errorf("unexpected keys:\n%v", cmp.Diff(expected, batchErr.Keys()))
+	}
+}
+
+func TestDB_ExecBatch(t *testing.T) {
+	db, ctx, cleanup := testDB(t)
+	defer cleanup()
+	count := func(t *testing.T) int64 {
+		t.Helper()
+		n, err := GetScalar[int64](ctx, db, "SELECT COUNT(*) FROM people")
+		if err != nil {
+			t.Fatalf("failed to count: %v", err)
+		}
+		return n
+	}
+	stmts := []Statement{
+		{Query: "INSERT INTO people (first_name) VALUES (?)", Args: []any{"John"}},
+		{Query: "INSERT INTO nope (first_name) VALUES (?)", Args: []any{"Jane"}},
+		{Query: "INSERT INTO people (first_name) VALUES (?), (?)", Args: []any{"Jim", "Jill"}},
+	}
+
+	t.Run("results per statement", func(t *testing.T) {
+		results, err := db.ExecBatch(ctx, []Statement{stmts[0], stmts[2]})
+		if err != nil {
+			t.Fatalf("failed to exec: %v", err)
+		}
+		for i, expected := range []int64{1, 2} {
+			if n, _ := results[i].RowsAffected(); n != expected {
+				t.Errorf("expected %d rows affected by %d, got %d", expected, i, n)
+			}
+		}
+		if n := count(t); n != 3 {
+			t.Errorf("expected 3 people, got %d", n)
+		}
+		db.MustExec(ctx, "DELETE FROM people")
+	})
+
+	t.Run("failure -> rolls back", func(t *testing.T) {
+		results, err := db.ExecBatch(ctx, stmts)
+		errcmp.MustMatch(t, err, "1 (INSERT INTO nope (first_name) VALUES (?)): no such table: nope")
+		var failure *BatchFailure
+		if !errors.As(err, &failure) || failure.Index != 1 {
+			t.Errorf("expected failure of statement 1, got %v", err)
+		}
+		if results != nil {
+			t.Errorf("expected no results, got %v", results)
+		}
+		if n := count(t); n != 0 {
+			t.Errorf("expected no people, got %d", n)
+		}
+	})
+
+	t.Run("skip failed statements", func(t *testing.T) {
+		results, err := db.ExecBatch(ctx, stmts, SkipFailedRows)
+		var batchErr *BatchError
+		if !errors.As(err, &batchErr) || len(batchErr.Failures) != 1 || batchErr.Failures[0].Index != 1 {
+			t.Errorf("expected failure of statement 1, got %v", err)
+		}
+		if results[0] == nil || results[1] != nil || results[2] == nil {
+			t.Errorf("expected results of statements 0 and 2, got %v", results)
+		}
+		if n := count(t); n != 3 {
+			t.Errorf("expected 3 people, got %d", n)
+		}
+	})
+}
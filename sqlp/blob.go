@@ -0,0 +1,192 @@
+package sqlp
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/greghart/powerputtygo/queryp"
+)
+
+// blobChunkSize is how many bytes ReadBlob and WriteBlob move per query.
+var blobChunkSize = 1 << 20
+
+// blobMaxSize is the most bytes WriteBlob writes to a SQLite or MySQL blob. Appending a chunk there
+// rewrites the whole value so far, so writes cost quadratically more as blobs grow.
+var blobMaxSize int64 = 64 << 20
+
+// ReadBlob returns a reader of the blob in column of table's row with the given id, a chunk per
+// query, so large binary values aren't buffered in memory whole. Read it within a transaction for a
+// consistent read of a blob that may be concurrently written. A missing row reads as ErrNotFound,
+// and a NULL blob as empty.
+//
+// On Postgres, the column is the oid of a large object, read with lo_get, so reads stream. On
+// SQLite and MySQL, the column is a blob read with SUBSTR, which loads the whole value per chunk, so
+// blobs are capped in size, see WriteBlob.
+func (db *DB) ReadBlob(ctx context.Context, table, column string, id any) io.Reader {
+	return &blobReader{ctx: ctx, db: db, table: table, column: column, id: id}
+}
+
+type blobReader struct {
+	ctx    context.Context
+	db     *DB
+	table  string
+	column string
+	id     any
+
+	offset int    // Bytes read so far
+	chunk  []byte // Unread bytes of the current chunk
+	err    error
+}
+
+func (r *blobReader) Read(p []byte) (int, error) {
+	if len(r.chunk) == 0 && r.err == nil {
+		r.chunk, r.err = r.next()
+	}
+	if len(r.chunk) == 0 {
+		return 0, r.err
+	}
+	n := copy(p, r.chunk)
+	r.chunk = r.chunk[n:]
+	r.offset += n
+	return n, nil
+}
+
+// next reads the next chunk, returning io.EOF once there are none.
+func (r *blobReader) next() ([]byte, error) {
+	d := r.db.Dialect()
+	args := queryp.NewArgs().WithPlaceholderer(d.Placeholderer())
+	column := d.QuoteIdent(r.column)
+	var substr string
+	switch d {
+	case queryp.Postgres:
+		substr = fmt.Sprintf("lo_get(%s, %s, %s)", column, args.Add(r.offset), args.Add(blobChunkSize))
+	case queryp.Sqlite, queryp.MySQL:
+		substr = fmt.Sprintf("SUBSTR(%s, %s, %s)", column, args.Add(r.offset+1), args.Add(blobChunkSize))
+	default:
+		return nil, r.db.errUnknownDialect("ReadBlob")
+	}
+	q := "SELECT " + substr + " FROM " + r.table + " WHERE id = " + args.Add(r.id)
+
+	var chunk []byte
+	if err := r.db.QueryRow(r.ctx, q, args.Args()...).Scan(&chunk); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: %s %v", ErrNotFound, r.table, r.id)
+		}
+		return nil, err
+	}
+	if len(chunk) == 0 {
+		return nil, io.EOF
+	}
+	return chunk, nil
+}
+
+// WriteBlob writes src into column of table's row with the given id, a chunk per query in one
+// transaction, so large binary values aren't buffered in memory whole. Returns the number of bytes
+// written, or ErrNotFound if there's no such row.
+//
+// On Postgres, the column is the oid of a large object: src is written to a new large object with
+// lo_put, which then replaces (and unlinks) the row's previous one, so writes stream. On SQLite and
+// MySQL, the column is a blob that each chunk is appended to, which rewrites the value so far, so
+// writing more than 64MiB errors rather than slowing down quadratically.
+func (db *DB) WriteBlob(ctx context.Context, table, column string, id any, src io.Reader) (int64, error) {
+	d := db.Dialect()
+	switch d {
+	case queryp.Postgres:
+		return db.writeLargeObject(ctx, table, column, id, src)
+	case queryp.Sqlite, queryp.MySQL:
+	default:
+		return 0, db.errUnknownDialect("WriteBlob")
+	}
+	quoted := d.QuoteIdent(column)
+	appendExpr := "CONCAT(" + quoted + ", ?)"
+	if d == queryp.Sqlite {
+		appendExpr = "CAST(" + quoted + " || ? AS BLOB)" // || is text concatenation, which keeps bytes
+	}
+	where := " WHERE id = ?"
+
+	var written int64
+	err := db.RunInTx(ctx, func(ctx context.Context) error {
+		var exists int
+		if err := db.QueryRow(ctx, "SELECT COUNT(*) FROM "+table+where, id).Scan(&exists); err != nil {
+			return err
+		}
+		if exists == 0 {
+			return fmt.Errorf("%w: %s %v", ErrNotFound, table, id)
+		}
+		if _, err := db.Exec(ctx, "UPDATE "+table+" SET "+quoted+" = ?"+where, []byte{}, id); err != nil {
+			return err
+		}
+
+		buf := make([]byte, blobChunkSize)
+		for {
+			n, err := io.ReadFull(src, buf)
+			if n > 0 {
+				if written+int64(n) > blobMaxSize {
+					return fmt.Errorf("sqlp: blob of %s.%s exceeds %d bytes", table, column, blobMaxSize)
+				}
+				if _, err := db.Exec(ctx, "UPDATE "+table+" SET "+quoted+" = "+appendExpr+where, buf[:n], id); err != nil {
+					return err
+				}
+				written += int64(n)
+			}
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+		}
+	})
+	return written, err
+}
+
+// writeLargeObject writes src to a new Postgres large object, replacing the one of column of
+// table's row with the given id.
+func (db *DB) writeLargeObject(ctx context.Context, table, column string, id any, src io.Reader) (int64, error) {
+	quoted := queryp.Postgres.QuoteIdent(column)
+	var written int64
+	err := db.RunInTx(ctx, func(ctx context.Context) error {
+		var previous sql.Null[int64]
+		err := db.QueryRow(ctx, "SELECT "+quoted+" FROM "+table+" WHERE id = $1 FOR UPDATE", id).Scan(&previous)
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%w: %s %v", ErrNotFound, table, id)
+		} else if err != nil {
+			return err
+		}
+
+		var oid int64
+		if err := db.QueryRow(ctx, "SELECT lo_create(0)").Scan(&oid); err != nil {
+			return fmt.Errorf("failed to create large object: %w", err)
+		}
+		buf := make([]byte, blobChunkSize)
+		for {
+			n, err := io.ReadFull(src, buf)
+			if n > 0 {
+				if _, err := db.Exec(ctx, "SELECT lo_put($1, $2, $3)", oid, written, buf[:n]); err != nil {
+					return fmt.Errorf("failed to write large object: %w", err)
+				}
+				written += int64(n)
+			}
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				break
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+		if _, err := db.Exec(ctx, "UPDATE "+table+" SET "+quoted+" = $1 WHERE id = $2", oid, id); err != nil {
+			return err
+		}
+		if previous.Valid {
+			if _, err := db.Exec(ctx, "SELECT lo_unlink($1)", previous.V); err != nil {
+				return fmt.Errorf("failed to unlink previous large object: %w", err)
+			}
+		}
+		return nil
+	})
+	return written, err
+}
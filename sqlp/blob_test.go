@@ -0,0 +1,60 @@
+package sqlp
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/greghart/powerputtygo/errcmp"
+)
+
+func TestDB_Blob(t *testing.T) {
+	db, ctx, cleanup := testDB(t)
+	defer cleanup()
+	db.MustExec(ctx, "DROP TABLE IF EXISTS assets; CREATE TABLE assets (id INTEGER PRIMARY KEY, data BLOB)")
+	db.MustExec(ctx, "INSERT INTO assets (id) VALUES (1)")
+	defer db.MustExec(ctx, "DROP TABLE assets")
+
+	defer func(size int) { blobChunkSize = size }(blobChunkSize)
+	blobChunkSize = 4
+
+	// Bytes that aren't valid text, across a few chunks
+	data := []byte{0, 1, 2, 0xff, 0xfe, 0, 'a', 'b', 0x80, 9, 10}
+	n, err := db.WriteBlob(ctx, "assets", "data", 1, bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("WriteBlob: %v", err)
+	}
+	if n != int64(len(data)) {
+		t.Errorf("expected %d bytes written, got %d", len(data), n)
+	}
+
+	got, err := io.ReadAll(db.ReadBlob(ctx, "assets", "data", 1))
+	if err != nil {
+		t.Fatalf("ReadBlob: %v", err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Errorf("expected %v, got %v", data, got)
+	}
+
+	// Rewriting replaces
+	if _, err := db.WriteBlob(ctx, "assets", "data", 1, bytes.NewReader(data[:2])); err != nil {
+		t.Fatalf("WriteBlob: %v", err)
+	}
+	if got, _ := io.ReadAll(db.ReadBlob(ctx, "assets", "data", 1)); !bytes.Equal(got, data[:2]) {
+		t.Errorf("expected %v, got %v", data[:2], got)
+	}
+
+	// Too large -> err, leaving the blob as it was
+	defer func(size int64) { blobMaxSize = size }(blobMaxSize)
+	blobMaxSize = 8
+	_, err = db.WriteBlob(ctx, "assets", "data", 1, bytes.NewReader(data))
+	errcmp.MustMatch(t, err, "blob of assets.data exceeds 8 bytes")
+	if got, _ := io.ReadAll(db.ReadBlob(ctx, "assets", "data", 1)); !bytes.Equal(got, data[:2]) {
+		t.Errorf("expected %v, got %v", data[:2], got)
+	}
+
+	_, err = db.WriteBlob(ctx, "assets", "data", 2, bytes.NewReader(data))
+	errcmp.MustMatch(t, err, "not found")
+	_, err = io.ReadAll(db.ReadBlob(ctx, "assets", "data", 2))
+	errcmp.MustMatch(t, err, "not found")
+}
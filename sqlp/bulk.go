@@ -0,0 +1,119 @@
+package sqlp
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+
+	"github.com/greghart/powerputtygo/queryp"
+	"github.com/greghart/powerputtygo/sqlp/internal/reflectp"
+)
+
+// maxParams is the most placeholders a statement can have per dialect, for chunking bulk inserts.
+// Unknown dialects get SQLite's historic limit of 999, which every driver supports.
+var maxParams = map[queryp.Dialect]int{
+	queryp.Postgres: 65535,
+	queryp.MySQL:    65535,
+	queryp.Sqlite:   32766,
+}
+
+// BulkInsertRows inserts rows of values for columns into table, with multi-row INSERTs of as many
+// rows as the dialect's placeholder limit allows per statement. The chunks run in one transaction
+// (the context's, if any), so either all rows are inserted or none are. Returns the rows inserted.
+func (db *DB) BulkInsertRows(ctx context.Context, table string, columns []string, rows [][]any) (int64, error) {
+	if len(columns) == 0 {
+		return 0, fmt.Errorf("sqlp: bulk insert into %s needs columns", table)
+	}
+	for i, row := range rows {
+		if len(row) != len(columns) {
+			return 0, fmt.Errorf("sqlp: bulk insert row %d has %d values for %d columns", i, len(row), len(columns))
+		}
+	}
+	limit, ok := maxParams[db.Dialect()]
+	if !ok {
+		limit = 999
+	}
+	chunk := max(limit/len(columns), 1)
+
+	var inserted int64
+	err := db.RunInTx(ctx, func(ctx context.Context) error {
+		for rows := range slices.Chunk(rows, chunk) {
+			q, args := queryp.InsertValues(table, columns, rows).Execute(db.Dialect().Placeholderer())
+			n, err := db.ExecRows(ctx, q, args...)
+			if err != nil {
+				return err
+			}
+			inserted += n
+		}
+		return nil
+	})
+	return inserted, err
+}
+
+// BulkInsert inserts entities into table, see BulkInsertRows, with a column per columnar field of E.
+// The `id` column is left out if it's zero for every entity, so the database assigns ids (entities
+// mixing zero and non-zero ids are an error, as zero would be inserted as is), as are
+// columns ignored with IgnoreColumns, so the database fills in their defaults (eg. timestamps).
+//
+//	n, err := sqlp.BulkInsert(sqlp.IgnoreColumns(ctx, "created_at"), db, "people", people)
+func BulkInsert[E any](ctx context.Context, db *DB, table string, entities []E) (int64, error) {
+	var e E
+	t := reflect.TypeOf(e)
+	fields, err := reflectp.FieldsFactory(t)
+	if err != nil {
+		return 0, fmt.Errorf("failed to reflect fields for %v: %w", t, err)
+	}
+	// Columns are named as in a result set, with the prefixes of promoted structs
+	byField := make(map[*reflectp.Field]string, len(fields.ByColumnName))
+	for column, field := range fields.ByColumnName {
+		byField[field] = column
+	}
+	ignored := fields.Lookup(ignoredColumns(ctx))
+	var columns []string
+	var columnFields []*reflectp.Field
+	for _, field := range fields.Columns() {
+		if ignored[field] {
+			continue
+		}
+		if byField[field] == "id" {
+			switch zero := countZero(entities, field); {
+			case zero == len(entities):
+				continue
+			case zero > 0:
+				// A zero id would be inserted as is, rather than assigned
+				return 0, fmt.Errorf("sqlp: bulk insert into %s mixes zero and non-zero ids, insert them separately", table)
+			}
+		}
+		columns = append(columns, byField[field])
+		columnFields = append(columnFields, field)
+	}
+
+	rows := make([][]any, len(entities))
+	for i, entity := range entities {
+		v := reflect.ValueOf(entity)
+		rows[i] = make([]any, len(columnFields))
+		for j, field := range columnFields {
+			fv, err := v.FieldByIndexErr(field.Index)
+			if err != nil || !fv.IsValid() {
+				continue // nil embedded pointer
+			}
+			if rows[i][j], err = field.Value(fv); err != nil {
+				return 0, fmt.Errorf("sqlp: bulk insert row %d column %s: %w", i, columns[j], err)
+			}
+		}
+	}
+	return db.BulkInsertRows(ctx, table, columns, rows)
+}
+
+// countZero returns how many of entities have a zero field.
+func countZero[E any](entities []E, field *reflectp.Field) int {
+	n := 0
+	for _, entity := range entities {
+		fv, err := reflect.ValueOf(entity).FieldByIndexErr(field.Index)
+		if err != nil || fv.IsZero() {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,114 @@
+package sqlp
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/greghart/powerputtygo/errcmp"
+	"github.com/greghart/powerputtygo/queryp"
+)
+
+func TestBulkInsert(t *testing.T) {
+	db, ctx, cleanup := testDB(t)
+	defer cleanup()
+
+	count := func() int {
+		count, err := GetScalar[int](ctx, db, "SELECT COUNT(*) FROM people")
+		errcmp.MustMatch(t, err, "")
+		return count
+	}
+
+	t.Run("entities", func(t *testing.T) {
+		defer db.MustExec(ctx, "DELETE FROM people")
+		people := make([]person, 10000)
+		for i := range people {
+			people[i] = person{FirstName: fmt.Sprintf("John %d", i), LastName: "Doe"}
+		}
+		n, err := BulkInsert(IgnoreColumns(ctx, "null_string", "created_at", "updated_at"), db, "people", people)
+		if err != nil || n != 10000 {
+			t.Fatalf("expected 10000 inserted, got %d, %v", n, err)
+		}
+		last, err := Get[person](ctx, db, "SELECT * FROM people ORDER BY id DESC LIMIT 1")
+		errcmp.MustMatch(t, err, "")
+		if last.ID == 0 || last.FirstName != "John 9999" || last.CreatedAt.IsZero() {
+			t.Errorf("expected ids and defaults assigned by the database, got %+v", last)
+		}
+	})
+
+	t.Run("chunks", func(t *testing.T) {
+		defer db.MustExec(ctx, "DELETE FROM people")
+		defer func(n int) { maxParams[queryp.Sqlite] = n }(maxParams[queryp.Sqlite])
+		maxParams[queryp.Sqlite] = 5 // 2 rows of 2 columns per statement
+
+		ctx, capture := CaptureQueries(ctx)
+		n, err := db.BulkInsertRows(ctx, "people", []string{"first_name", "last_name"}, [][]any{
+			{"John", "Doe"}, {"Jane", "Doe"}, {"Jim", "Doe"},
+		})
+		if err != nil || n != 3 {
+			t.Fatalf("expected 3 inserted, got %d, %v", n, err)
+		}
+		queries := capture.Queries()
+		if len(queries) != 2 ||
+			queries[0].Query != "INSERT INTO people (first_name, last_name) VALUES (?, ?), (?, ?)" ||
+			queries[1].Query != "INSERT INTO people (first_name, last_name) VALUES (?, ?)" {
+			t.Errorf("expected 2 statements, got %v", queries)
+		}
+	})
+
+	t.Run("failed chunk -> none inserted", func(t *testing.T) {
+		defer func(n int) { maxParams[queryp.Sqlite] = n }(maxParams[queryp.Sqlite])
+		maxParams[queryp.Sqlite] = 2
+
+		_, err := db.BulkInsertRows(ctx, "people", []string{"id", "first_name"}, [][]any{
+			{1, "John"}, {2, "Jane"}, {1, "Jim"},
+		})
+		errcmp.MustMatch(t, err, "UNIQUE constraint failed: people.id")
+		if count() != 0 {
+			t.Errorf("expected earlier chunks rolled back")
+		}
+	})
+
+	t.Run("bad rows -> err", func(t *testing.T) {
+		_, err := db.BulkInsertRows(ctx, "people", []string{"first_name"}, [][]any{{"John", "Doe"}})
+		errcmp.MustMatch(t, err, "row 0 has 2 values for 1 columns")
+		_, err = BulkInsert(context.Background(), db, "people", []person{{}})
+		errcmp.MustMatch(t, err, "table people has no column named null_string")
+	})
+
+	t.Run("prefixed columns", func(t *testing.T) {
+		db.MustExec(ctx, "CREATE TABLE audited (id INTEGER PRIMARY KEY, name TEXT, audit_id INTEGER, audit_created TEXT DEFAULT 'now', created TEXT)")
+		defer db.MustExec(ctx, "DROP TABLE audited")
+		type audit struct {
+			ID      int64  `sqlp:"id"`
+			Created string `sqlp:"created"`
+		}
+		type audited struct {
+			ID      int64  `sqlp:"id"`
+			Name    string `sqlp:"name"`
+			Created string `sqlp:"created"`
+			audit   `sqlp:"audit,promote"`
+		}
+		// The nested id doesn't count as the entity's, and ignoring audit_created leaves created
+		rows := []audited{{Name: "John", Created: "today", audit: audit{ID: 7, Created: "ignored"}}}
+		n, err := BulkInsert(IgnoreColumns(ctx, "audit_created"), db, "audited", rows)
+		if err != nil || n != 1 {
+			t.Fatalf("expected 1 inserted, got %d, %v", n, err)
+		}
+		got, err := Get[audited](ctx, db, "SELECT * FROM audited")
+		errcmp.MustMatch(t, err, "")
+		expected := audited{ID: 1, Name: "John", Created: "today", audit: audit{ID: 7, Created: "now"}}
+		if got == nil || *got != expected {
+			t.Errorf("expected %+v, got %+v", expected, got)
+		}
+	})
+
+	t.Run("mixed ids -> err", func(t *testing.T) {
+		ctx := IgnoreColumns(ctx, "null_string", "created_at", "updated_at")
+		_, err := BulkInsert(ctx, db, "people", []person{{ID: 5, FirstName: "John"}, {FirstName: "Jane"}})
+		errcmp.MustMatch(t, err, "bulk insert into people mixes zero and non-zero ids")
+		if count() != 0 {
+			t.Errorf("expected none inserted")
+		}
+	})
+}
@@ -0,0 +1,182 @@
+package sqlp
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+const captureKey = contextKeyType("sqlp.capture")
+
+// Capture records the queries run for a context, as a serializable bundle that can be replayed
+// against another database later (eg. reproducing a data dependent production bug on staging).
+// Safe for concurrent use, as a request may query from multiple goroutines.
+type Capture struct {
+	mu      sync.Mutex
+	queries []*CapturedQuery
+}
+
+// CapturedQuery is a single query or statement recorded by a Capture.
+type CapturedQuery struct {
+	Exec     bool          `json:"exec,omitempty"` // Whether run through Exec, vs. Query/QueryRow
+	Query    string        `json:"query"`
+	Args     []any         `json:"args,omitempty"`
+	Duration time.Duration `json:"duration"`
+	// Rows affected for Exec, or rows scanned by sqlp helpers (Get, Select, Repository, etc.) for
+	// queries. -1 if unknown, eg. raw Query rows that sqlp doesn't iterate.
+	Rows int64  `json:"rows"`
+	Err  string `json:"err,omitempty"`
+
+	rows *sql.Rows // to attribute scanned rows back to the query
+}
+
+// CaptureQueries returns a context that records every query run through sqlp with it (query, args,
+// timing, and row counts) into the returned Capture.
+func CaptureQueries(ctx context.Context) (context.Context, *Capture) {
+	c := &Capture{}
+	return context.WithValue(ctx, captureKey, c), c
+}
+
+// Queries returns a copy of the queries captured so far, in the order they were run.
+func (c *Capture) Queries() []CapturedQuery {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	out := make([]CapturedQuery, len(c.queries))
+	for i, q := range c.queries {
+		out[i] = *q
+		out[i].rows = nil
+	}
+	return out
+}
+
+// Encode writes the capture as JSON.
+func (c *Capture) Encode(w io.Writer) error {
+	return json.NewEncoder(w).Encode(c.Queries())
+}
+
+// DecodeCapture reads a capture written by Encode.
+// Note args round trip through JSON, so they're replayed as their JSON types (eg. times become
+// strings, and numbers json.Number strings), which drivers generally accept in their place.
+func DecodeCapture(r io.Reader) (*Capture, error) {
+	var queries []CapturedQuery
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	if err := dec.Decode(&queries); err != nil {
+		return nil, err
+	}
+	c := &Capture{queries: make([]*CapturedQuery, len(queries))}
+	for i := range queries {
+		c.queries[i] = &queries[i]
+	}
+	return c, nil
+}
+
+func (c *Capture) record(q *CapturedQuery) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.queries = append(c.queries, q)
+}
+
+// scanned attributes the number of rows scanned to the query that returned rows.
+func (c *Capture) scanned(rows *sql.Rows, n int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i := len(c.queries) - 1; i >= 0; i-- {
+		if c.queries[i].rows == rows {
+			c.queries[i].Rows = n
+			c.queries[i].rows = nil
+			return
+		}
+	}
+}
+
+// captureFromContext returns the context's Capture if any.
+func captureFromContext(ctx context.Context) *Capture {
+	c, _ := ctx.Value(captureKey).(*Capture)
+	return c
+}
+
+// captureExec records an Exec, if capturing.
+func (db *DB) captureExec(ctx context.Context, start time.Time, query string, args []any, res sql.Result, err error) {
+	c := captureFromContext(ctx)
+	if c == nil {
+		return
+	}
+	q := &CapturedQuery{Exec: true, Query: query, Args: args, Duration: time.Since(start), Rows: -1}
+	if err != nil {
+		q.Err = err.Error()
+	} else if n, err := res.RowsAffected(); err == nil {
+		q.Rows = n
+	}
+	c.record(q)
+}
+
+// captureQuery records a Query (or QueryRow, with nil rows), if capturing.
+func (db *DB) captureQuery(ctx context.Context, start time.Time, query string, args []any, rows *sql.Rows, err error) {
+	c := captureFromContext(ctx)
+	if c == nil {
+		return
+	}
+	q := &CapturedQuery{Query: query, Args: args, Duration: time.Since(start), Rows: -1, rows: rows}
+	if err != nil {
+		q.Err = err.Error()
+	}
+	c.record(q)
+}
+
+// captureScanned records the number of rows scanned from a query's rows, if capturing.
+func (db *DB) captureScanned(ctx context.Context, rows *sql.Rows, n int64) {
+	if c := captureFromContext(ctx); c != nil {
+		c.scanned(rows, n)
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// ReplayResult is the outcome of replaying a captured query, to compare against the original.
+type ReplayResult struct {
+	Captured CapturedQuery
+	Duration time.Duration
+	Rows     int64 // Rows affected for Exec, or rows returned for queries
+	Err      error
+}
+
+// Replay runs the captured queries in order, returning how each went.
+// Statements are executed for real, so to replay without side effects run it in a transaction that
+// is rolled back, eg.
+//
+//	db.RunInTx(ctx, func(ctx context.Context) error {
+//		results = db.Replay(ctx, capture)
+//		return errRollback
+//	})
+func (db *DB) Replay(ctx context.Context, c *Capture) []ReplayResult {
+	queries := c.Queries()
+	results := make([]ReplayResult, len(queries))
+	for i, q := range queries {
+		start := time.Now()
+		rows, err := db.replay(ctx, q)
+		results[i] = ReplayResult{Captured: q, Duration: time.Since(start), Rows: rows, Err: err}
+	}
+	return results
+}
+
+func (db *DB) replay(ctx context.Context, q CapturedQuery) (int64, error) {
+	if q.Exec {
+		return db.ExecRows(ctx, q.Query, q.Args...)
+	}
+	ctx, cancel := db.timeoutContext(ctx)
+	defer cancel()
+	rows, err := db.Query(ctx, q.Query, q.Args...)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+	var n int64
+	for rows.Next() {
+		n++
+	}
+	return n, rows.Err()
+}
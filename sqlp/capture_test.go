@@ -0,0 +1,72 @@
+package sqlp
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestCaptureQueries(t *testing.T) {
+	db, ctx, cleanup := testDB(t)
+	defer cleanup()
+	grandparent := grandchildrenSetup(ctx, db)
+
+	captureCtx, capture := CaptureQueries(ctx)
+	db.MustExec(captureCtx, "UPDATE people SET last_name = ? WHERE last_name = ?", "Smith", "Doe")
+	if _, err := Select[person](captureCtx, db, "SELECT id, first_name FROM people WHERE id > ?", grandparent.ID); err != nil {
+		t.Fatalf("failed to select: %v", err)
+	}
+	var count int
+	if err := db.QueryRow(captureCtx, "SELECT COUNT(*) FROM people").Scan(&count); err != nil {
+		t.Fatalf("failed to count: %v", err)
+	}
+	db.Exec(captureCtx, "SELECT * FROM nope") // nolint:errcheck
+	db.MustExec(ctx, "UPDATE people SET last_name = ?", "Uncaptured")
+
+	expected := []CapturedQuery{
+		{Exec: true, Query: "UPDATE people SET last_name = ? WHERE last_name = ?", Args: []any{"Smith", "Doe"}, Rows: 3},
+		{Query: "SELECT id, first_name FROM people WHERE id > ?", Args: []any{grandparent.ID}, Rows: 2},
+		{Query: "SELECT COUNT(*) FROM people", Rows: -1},
+		{Exec: true, Query: "SELECT * FROM nope", Rows: -1, Err: "no such table: nope"},
+	}
+	ignoreDuration := cmpopts.IgnoreFields(CapturedQuery{}, "Duration")
+	if diff := cmp.Diff(expected, capture.Queries(), ignoreDuration, cmpopts.EquateEmpty(), cmp.AllowUnexported(CapturedQuery{})); diff != "" {
+		t.Errorf("captured queries unexpected:\n%v", diff)
+	}
+
+	t.Run("encode and replay", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		if err := capture.Encode(buf); err != nil {
+			t.Fatalf("failed to encode: %v", err)
+		}
+		decoded, err := DecodeCapture(buf)
+		if err != nil {
+			t.Fatalf("failed to decode: %v", err)
+		}
+
+		errRollback := errors.New("rollback")
+		var results []ReplayResult
+		err = db.RunInTx(ctx, func(ctx context.Context) error {
+			db.MustExec(ctx, "UPDATE people SET last_name = ?", "Doe")
+			results = db.Replay(ctx, decoded)
+			return errRollback
+		})
+		if err != errRollback {
+			t.Fatalf("expected rollback, got %v", err)
+		}
+		rows := make([]int64, len(results))
+		for i, r := range results {
+			rows[i] = r.Rows
+		}
+		if expected := []int64{3, 2, 1, 0}; !cmp.Equal(rows, expected) {
+			t.Errorf("replayed rows unexpected:\n%v", cmp.Diff(expected, rows))
+		}
+		if results[3].Err == nil {
+			t.Errorf("expected replayed error for missing table")
+		}
+	})
+}
@@ -0,0 +1,84 @@
+package sqlp
+
+import (
+	"context"
+	"slices"
+
+	"github.com/greghart/powerputtygo/queryp"
+)
+
+// Dependent is a repository whose rows reference another repository's rows, see WithDependent.
+// Any *Repository is a Dependent.
+type Dependent interface {
+	deleteReferencing(ctx context.Context, column string, ids []any) (int64, error)
+}
+
+// dependent is a registered Dependent, with its column referencing the repository's ids.
+type dependent struct {
+	column     string
+	repository Dependent
+}
+
+// WithDependent returns a copy of the repository whose DeleteCascade first deletes the rows of
+// repo whose column references the deleted ids, eg. for where ON DELETE CASCADE isn't
+// available or wanted. Dependents' own dependents are deleted in turn, children before parents.
+//
+//	pets := sqlp.NewRepository[pet](db, "pets")
+//	people := sqlp.NewRepository[person](db, "people").WithDependent("parent_id", pets)
+//	n, err := people.DeleteCascade(ctx, id) // DELETE FROM pets WHERE parent_id IN (?), then people
+func (r *Repository[E]) WithDependent(column string, repo Dependent) *Repository[E] {
+	c := *r
+	c.dependents = append(slices.Clip(c.dependents), dependent{column: column, repository: repo})
+	return &c
+}
+
+// DeleteCascade deletes the row with the given id along with its dependents' rows (see
+// WithDependent), in one transaction (the context's, if any). Returns the number of rows deleted,
+// dependents' included.
+func (r *Repository[E]) DeleteCascade(ctx context.Context, id any) (int64, error) {
+	if err := r.checkWritable("DeleteCascade"); err != nil {
+		return 0, err
+	}
+	var deleted int64
+	err := r.RunInTx(ctx, func(ctx context.Context) (err error) {
+		deleted, err = r.deleteReferencing(ctx, "id", []any{id})
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return deleted, nil
+}
+
+// deleteReferencing deletes the rows whose column is one of ids, after their dependents' rows.
+func (r *Repository[E]) deleteReferencing(ctx context.Context, column string, ids []any) (int64, error) {
+	if err := r.checkWritable("DeleteCascade"); err != nil {
+		return 0, err
+	}
+	where := queryp.In(column, ids)
+	var deleted int64
+	if len(r.dependents) > 0 {
+		args := queryp.NewArgs().WithPlaceholderer(r.Dialect().Placeholderer())
+		q := "SELECT id FROM " + r.QualifiedTable() + " WHERE " + r.scope(where)(args)
+		own, err := SelectScalars[any](ctx, r.DB, q, args.Args()...)
+		if err != nil {
+			return 0, r.TranslateError(err)
+		}
+		if len(own) == 0 {
+			return 0, nil
+		}
+		for _, d := range r.dependents {
+			n, err := d.repository.deleteReferencing(ctx, d.column, own)
+			if err != nil {
+				return 0, err
+			}
+			deleted += n
+		}
+	}
+	q, args := r.deleteWhere(where)
+	n, err := r.ExecRows(ctx, q, args...)
+	if err != nil {
+		return 0, err
+	}
+	return deleted + n, nil
+}
@@ -0,0 +1,88 @@
+package sqlp
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/greghart/powerputtygo/errcmp"
+)
+
+func TestRepository_DeleteCascade(t *testing.T) {
+	db, ctx, cleanup := testDB(t)
+	defer cleanup()
+
+	count := func(table string) int {
+		count, err := GetScalar[int](ctx, db, "SELECT COUNT(*) FROM "+table)
+		errcmp.MustMatch(t, err, "")
+		return count
+	}
+
+	pets := NewRepository[pet](db, "pets")
+	grandchildren := NewRepository[person](db, "people").WithDependent("parent_id", pets)
+	children := NewRepository[person](db, "people").
+		WithDependent("parent_id", pets).
+		WithDependent("parent_id", grandchildren)
+	people := NewRepository[person](db, "people").
+		WithDependent("parent_id", pets).
+		WithDependent("parent_id", children)
+
+	t.Run("children before parents", func(t *testing.T) {
+		grandparent := grandchildrenSetup(ctx, db)
+		albert := albertSetup(ctx, db)
+
+		ctx, capture := CaptureQueries(ctx)
+		n, err := people.DeleteCascade(ctx, grandparent.ID)
+		if err != nil || n != 4 {
+			t.Fatalf("expected 3 people and a pet deleted, got %d, %v", n, err)
+		}
+		if count("people") != 1 || count("pets") != 0 {
+			t.Errorf("expected only %s left", albert.FirstName)
+		}
+		var deletes []string
+		for _, q := range capture.Queries() {
+			if q.Exec {
+				deletes = append(deletes, q.Query)
+			}
+		}
+		expected := []string{
+			"DELETE FROM pets WHERE parent_id IN (?)",   // the grandparent's pets
+			"DELETE FROM pets WHERE parent_id IN (?)",   // the child's pets
+			"DELETE FROM pets WHERE parent_id IN (?)",   // the grandchild's pets
+			"DELETE FROM people WHERE parent_id IN (?)", // the grandchild
+			"DELETE FROM people WHERE parent_id IN (?)", // the child
+			"DELETE FROM people WHERE id IN (?)",        // the grandparent
+		}
+		if diff := cmp.Diff(expected, deletes); diff != "" {
+			t.Errorf("unexpected deletes:\n%v", diff)
+		}
+		db.MustExec(ctx, "DELETE FROM people")
+	})
+
+	t.Run("without dependents", func(t *testing.T) {
+		grandparent := grandchildrenSetup(ctx, db)
+		n, err := NewRepository[pet](db, "pets").DeleteCascade(ctx, grandparent.Child.Pet.ID)
+		if err != nil || n != 1 {
+			t.Errorf("expected pet deleted, got %d, %v", n, err)
+		}
+		n, err = people.DeleteCascade(ctx, -1)
+		if err != nil || n != 0 {
+			t.Errorf("expected nothing deleted, got %d, %v", n, err)
+		}
+		db.MustExec(ctx, "DELETE FROM people")
+	})
+
+	t.Run("failure -> rolled back", func(t *testing.T) {
+		grandparent := grandchildrenSetup(ctx, db)
+		broken := NewRepository[person](db, "people").WithDependent("nope", pets)
+		_, err := broken.DeleteCascade(ctx, grandparent.ID)
+		errcmp.MustMatch(t, err, "no such column: nope")
+		if count("people") != 3 {
+			t.Errorf("expected nothing deleted")
+		}
+	})
+
+	t.Run("read only -> err", func(t *testing.T) {
+		_, err := people.ReadOnly().DeleteCascade(ctx, 1)
+		errcmp.MustMatch(t, err, "read-only")
+	})
+}
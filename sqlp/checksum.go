@@ -0,0 +1,139 @@
+package sqlp
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"slices"
+
+	"github.com/greghart/powerputtygo/sqlp/internal/reflectp"
+)
+
+// checksum is a column storing a checksum of other columns, see WithChecksum.
+type checksum struct {
+	column  string
+	columns []string
+}
+
+// WithChecksum returns a copy of the repository that keeps a checksum of the given columns in
+// column, eg. for applications that must detect corrupted rows. Reads through the repository that
+// select the checksum and its columns verify it, returning ErrIntegrity on a mismatch, and its
+// write helpers compute it. Rows written otherwise should set it with Checksum.
+// The checksum column must be a string field of E (eg. `CHAR(64)`), and is excluded from the checksum.
+//
+//	accounts := sqlp.NewRepository[account](db).WithChecksum("checksum", "owner_id", "balance")
+func (r *Repository[E]) WithChecksum(column string, columns ...string) *Repository[E] {
+	c := *r
+	c.checksum = &checksum{column: column, columns: slices.Clone(columns)}
+	return &c
+}
+
+// Checksum returns the checksum of entity's checksummed columns, to store in the checksum column,
+// see WithChecksum.
+func (r *Repository[E]) Checksum(entity E) (string, error) {
+	if r.checksum == nil {
+		return "", fmt.Errorf("sqlp: %s has no checksum, see WithChecksum", r.table)
+	}
+	fields, err := r.checksumFields()
+	if err != nil {
+		return "", err
+	}
+	v := reflect.ValueOf(entity)
+	values := make(map[string]any, len(r.checksum.columns))
+	for _, column := range r.checksum.columns {
+		fv, err := v.FieldByIndexErr(fields.ByColumnName[column].Index)
+		if err != nil || !fv.IsValid() {
+			values[column] = nil
+			continue
+		}
+		values[column] = fv.Interface()
+	}
+	return fingerprintValues(values)
+}
+
+// checksumFields returns E's fields, validating the checksum's columns against them.
+func (r *Repository[E]) checksumFields() (*reflectp.Fields, error) {
+	fields, err := reflectp.FieldsFactory(r.t)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reflect fields for %v: %w", r.t, err)
+	}
+	if field, ok := fields.ByColumnName[r.checksum.column]; !ok || field.DirectType.Kind() != reflect.String {
+		return nil, fmt.Errorf("%w: checksum column %q must be a string field", ErrUnknownColumn, r.checksum.column)
+	}
+	for _, column := range r.checksum.columns {
+		if column == r.checksum.column {
+			return nil, fmt.Errorf("sqlp: checksum column %q cannot checksum itself", column)
+		}
+		if field, ok := fields.ByColumnName[column]; !ok || !field.Columnar() {
+			return nil, fmt.Errorf("%w: cannot checksum %q", ErrUnknownColumn, column)
+		}
+	}
+	return fields, nil
+}
+
+// rowsVerifier returns a function verifying entities scanned from rows' checksums, or nil if
+// there's nothing to verify, as the rows don't include (or ignore) the checksum and all its columns.
+func (r *Repository[E]) rowsVerifier(ctx context.Context, rows *sql.Rows) (func(entity E) error, error) {
+	if r.checksum == nil {
+		return nil, nil
+	}
+	resultColumns, err := rows.Columns()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get columns: %w", err)
+	}
+	fields, err := r.checksumFields()
+	if err != nil {
+		return nil, err
+	}
+	ignored := ignoredColumns(ctx)
+	for _, column := range append([]string{r.checksum.column}, r.checksum.columns...) {
+		field := fields.ByColumnName[column]
+		if !slices.Contains(resultColumns, column) || slices.Contains(ignored, column) || slices.Contains(ignored, field.Name) {
+			return nil, nil
+		}
+	}
+	index := fields.ByColumnName[r.checksum.column].Index
+	return func(entity E) error {
+		sum, err := r.Checksum(entity)
+		if err != nil {
+			return err
+		}
+		stored := reflect.Indirect(reflect.ValueOf(entity).FieldByIndex(index))
+		if stored.IsValid() && stored.String() == sum {
+			return nil
+		}
+		if id, err := r.idOf(entity); err == nil {
+			return fmt.Errorf("%w: checksum mismatch for %s %v", ErrIntegrity, r.table, id)
+		}
+		return fmt.Errorf("%w: checksum mismatch for %s", ErrIntegrity, r.table)
+	}, nil
+}
+
+// checksumAssignment returns the checksum to set alongside assignments of checksummed columns, if
+// any, requiring all of them to be set.
+func (r *Repository[E]) checksumAssignment(columns []string, values []any) (string, bool, error) {
+	if r.checksum == nil {
+		return "", false, nil
+	}
+	if slices.Contains(columns, r.checksum.column) {
+		return "", false, fmt.Errorf("sqlp: cannot set checksum column %q directly", r.checksum.column)
+	}
+	set := map[string]any{}
+	for _, column := range r.checksum.columns {
+		if i := slices.Index(columns, column); i >= 0 {
+			set[column] = values[i]
+		}
+	}
+	if len(set) == 0 {
+		return "", false, nil
+	}
+	if len(set) < len(r.checksum.columns) {
+		return "", false, fmt.Errorf("sqlp: checksummed columns %v must be set together", r.checksum.columns)
+	}
+	if _, err := r.checksumFields(); err != nil {
+		return "", false, err
+	}
+	sum, err := fingerprintValues(set)
+	return sum, true, err
+}
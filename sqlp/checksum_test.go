@@ -0,0 +1,70 @@
+package sqlp
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/greghart/powerputtygo/errcmp"
+	"github.com/greghart/powerputtygo/queryp"
+)
+
+type account struct {
+	ID       int64  `sqlp:"id"`
+	OwnerID  int64  `sqlp:"owner_id"`
+	Balance  int64  `sqlp:"balance"`
+	Checksum string `sqlp:"checksum"`
+}
+
+func TestRepository_WithChecksum(t *testing.T) {
+	db, ctx, cleanup := testDB(t)
+	defer cleanup()
+	db.MustExec(ctx, "DROP TABLE IF EXISTS accounts; CREATE TABLE accounts (id INTEGER PRIMARY KEY, owner_id INTEGER, balance INTEGER, checksum TEXT)")
+	defer db.MustExec(ctx, "DROP TABLE accounts")
+
+	r := NewRepository[account](db, "accounts").WithChecksum("checksum", "owner_id", "balance")
+	sum, err := r.Checksum(account{OwnerID: 1, Balance: 100})
+	if err != nil {
+		t.Fatalf("Checksum: %v", err)
+	}
+	db.MustExec(ctx, "INSERT INTO accounts (id, owner_id, balance, checksum) VALUES (1, 1, 100, ?)", sum)
+	if _, err := r.Find(ctx, 1); err != nil {
+		t.Fatalf("expected valid checksum, got %v", err)
+	}
+
+	// Write helpers keep it up to date
+	if _, err := r.UpdateWhereIDs(ctx, []int64{1}, map[string]any{"owner_id": 1, "balance": 50}); err != nil {
+		t.Fatalf("UpdateWhereIDs: %v", err)
+	}
+	if a, err := r.Find(ctx, 1); err != nil || a.Balance != 50 {
+		t.Fatalf("expected valid updated account, got %+v, %v", a, err)
+	}
+	_, err = r.UpdateWhereIDs(ctx, []int64{1}, map[string]any{"balance": 0})
+	errcmp.MustMatch(t, err, "checksummed columns [owner_id balance] must be set together")
+	_, err = r.UpdateWhereIDs(ctx, []int64{1}, map[string]any{"checksum": ""})
+	errcmp.MustMatch(t, err, `cannot set checksum column "checksum" directly`)
+
+	// Corruption, or writes around the repository, are caught
+	db.MustExec(ctx, "UPDATE accounts SET balance = 1000000 WHERE id = 1")
+	_, err = r.Find(ctx, 1)
+	if !errors.Is(err, ErrIntegrity) {
+		t.Errorf("expected ErrIntegrity, got %v", err)
+	}
+	errcmp.MustMatch(t, err, "checksum mismatch for accounts 1")
+
+	// Deleted rows are returned as they were, mismatch or not
+	deleted, err := r.DeleteWhereReturning(ctx, queryp.Eq("id", 1))
+	if err != nil || len(deleted) != 1 || deleted[0].Balance != 1000000 {
+		t.Errorf("expected tampered account deleted, got %+v, %v", deleted, err)
+	}
+	db.MustExec(ctx, "INSERT INTO accounts (id, owner_id, balance, checksum) VALUES (1, 1, 1000000, ?)", sum)
+
+	// Can't verify without all the columns
+	if _, err := r.Get(ctx, "SELECT id, balance FROM accounts WHERE id = 1"); err != nil {
+		t.Errorf("expected partial select to skip verification, got %v", err)
+	}
+
+	_, err = NewRepository[account](db, "accounts").WithChecksum("checksum", "nope").Find(ctx, 1)
+	errcmp.MustMatch(t, err, `cannot checksum "nope"`)
+	_, err = NewRepository[account](db, "accounts").WithChecksum("balance", "owner_id").Find(ctx, 1)
+	errcmp.MustMatch(t, err, `checksum column "balance" must be a string field`)
+}
@@ -0,0 +1,248 @@
+package sqlp
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/greghart/powerputtygo/queryp"
+)
+
+// Config declares how to open and configure a DB, eg. loaded from the environment with LoadEnv, so
+// wiring sqlp in a service is declarative and mistakes are caught at startup by Validate.
+// Zero values leave the corresponding setting at its default.
+type Config struct {
+	Driver string `env:"DRIVER"` // eg. "postgres", must be registered with database/sql
+	DSN    string `env:"DSN"`    // Data source name or URL of the database
+
+	MaxOpenConns    int           `env:"MAX_OPEN_CONNS"`
+	MaxIdleConns    int           `env:"MAX_IDLE_CONNS"`
+	ConnMaxLifetime time.Duration `env:"CONN_MAX_LIFETIME"`
+	ConnMaxIdleTime time.Duration `env:"CONN_MAX_IDLE_TIME"`
+
+	DefaultTimeout time.Duration  `env:"DEFAULT_TIMEOUT"` // See WithDefaultTimeout
+	Dialect        queryp.Dialect `env:"DIALECT"`         // See WithDialect
+	Schema         string         `env:"SCHEMA"`          // See WithSchema
+	StmtCacheSize  int            `env:"STMT_CACHE_SIZE"` // See WithStmtCache
+	MaxRows        int64          `env:"MAX_ROWS"`        // See WithMaxRows
+
+	DryRun                 bool          `env:"DRY_RUN"`                  // See WithDryRun
+	RowsLeakDetection      bool          `env:"ROWS_LEAK_DETECTION"`      // See WithRowsLeakDetection
+	TxLeakThreshold        time.Duration `env:"TX_LEAK_THRESHOLD"`        // See WithTxLeakDetection
+	SkipReadOnlySavepoints bool          `env:"SKIP_READONLY_SAVEPOINTS"` // See WithSkipReadOnlySavepoints
+}
+
+// LoadEnv sets the config's fields from environment variables named by prefix and their `env`
+// tag, eg. "APP_DB_" + "DSN". Unset variables leave their field as is, so defaults can be set
+// beforehand. Durations are parsed by time.ParseDuration, eg. "3s".
+func (c *Config) LoadEnv(prefix string) error {
+	v := reflect.ValueOf(c).Elem()
+	var errs []error
+	for i := range v.NumField() {
+		field := v.Type().Field(i)
+		name := prefix + field.Tag.Get("env")
+		s, ok := os.LookupEnv(name)
+		if !ok {
+			continue
+		}
+		if err := setEnvValue(v.Field(i), s); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%w: %w", ErrInvalidConfig, errors.Join(errs...))
+	}
+	return nil
+}
+
+// setEnvValue parses s into a config field.
+func setEnvValue(v reflect.Value, s string) error {
+	if v.Type() == reflect.TypeOf(time.Duration(0)) {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	default:
+		return fmt.Errorf("unsupported config type %s", v.Type())
+	}
+	return nil
+}
+
+// Validate returns an error wrapping ErrInvalidConfig and each of the config's problems, if any.
+func (c Config) Validate() error {
+	var errs []error
+	if c.Driver == "" {
+		errs = append(errs, errors.New("driver is required"))
+	} else if !slices.Contains(sql.Drivers(), c.Driver) {
+		errs = append(errs, fmt.Errorf("driver %q isn't registered (is it imported?)", c.Driver))
+	}
+	if c.DSN == "" {
+		errs = append(errs, errors.New("dsn is required"))
+	}
+	switch c.Dialect {
+	case "", queryp.Postgres, queryp.Sqlite, queryp.MySQL:
+	default:
+		errs = append(errs, fmt.Errorf("unknown dialect %q", c.Dialect))
+	}
+	for _, setting := range []struct {
+		name string
+		n    int64
+	}{
+		{"max open conns", int64(c.MaxOpenConns)},
+		{"max idle conns", int64(c.MaxIdleConns)},
+		{"conn max lifetime", int64(c.ConnMaxLifetime)},
+		{"conn max idle time", int64(c.ConnMaxIdleTime)},
+		{"default timeout", int64(c.DefaultTimeout)},
+		{"stmt cache size", int64(c.StmtCacheSize)},
+		{"max rows", c.MaxRows},
+		{"tx leak threshold", int64(c.TxLeakThreshold)},
+	} {
+		if setting.n < 0 {
+			errs = append(errs, fmt.Errorf("%s can't be negative", setting.name))
+		}
+	}
+	if c.MaxOpenConns > 0 && c.MaxIdleConns > c.MaxOpenConns {
+		errs = append(errs, fmt.Errorf(
+			"max idle conns (%d) can't be more than max open conns (%d)", c.MaxIdleConns, c.MaxOpenConns,
+		))
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%w: %w", ErrInvalidConfig, errors.Join(errs...))
+	}
+	return nil
+}
+
+// Options returns the options the config declares.
+func (c Config) Options() []Option {
+	var opts []Option
+	if c.DefaultTimeout > 0 {
+		opts = append(opts, WithDefaultTimeout(c.DefaultTimeout))
+	}
+	if c.Dialect != "" {
+		opts = append(opts, WithDialect(c.Dialect))
+	}
+	if c.Schema != "" {
+		opts = append(opts, WithSchema(c.Schema))
+	}
+	if c.StmtCacheSize > 0 {
+		opts = append(opts, WithStmtCache(c.StmtCacheSize))
+	}
+	if c.MaxRows > 0 {
+		opts = append(opts, WithMaxRows(c.MaxRows))
+	}
+	if c.DryRun {
+		opts = append(opts, WithDryRun())
+	}
+	if c.RowsLeakDetection {
+		opts = append(opts, WithRowsLeakDetection())
+	}
+	if c.TxLeakThreshold > 0 {
+		opts = append(opts, WithTxLeakDetection(c.TxLeakThreshold))
+	}
+	if c.SkipReadOnlySavepoints {
+		opts = append(opts, WithSkipReadOnlySavepoints())
+	}
+	return opts
+}
+
+// OpenConfig validates the config and opens its database, with its pool settings and options, and
+// then opts (eg. ones that can't be declared, like WithLogger).
+func OpenConfig(c Config, opts ...Option) (*DB, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	db, err := Open(c.Driver, c.DSN, append(c.Options(), opts...)...)
+	if err != nil {
+		return nil, err
+	}
+	Options{
+		MaxOpenConns:    c.MaxOpenConns,
+		MaxIdleConns:    c.MaxIdleConns,
+		ConnMaxLifetime: c.ConnMaxLifetime,
+		ConnMaxIdleTime: c.ConnMaxIdleTime,
+	}.setPool(db.DB)
+	return db, nil
+}
+
+// Options are the common settings of a DB, its pool's and sqlp's, to open it with in one place, see
+// OpenWithOptions. Zero values leave the corresponding setting at its default.
+type Options struct {
+	Dialect        queryp.Dialect // See WithDialect
+	Logger         Logger         // See WithLogger
+	DefaultTimeout time.Duration  // See WithDefaultTimeout
+
+	MaxOpenConns    int // See sql.DB.SetMaxOpenConns
+	MaxIdleConns    int // See sql.DB.SetMaxIdleConns
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+}
+
+// OpenWithOptions opens a database configured with o, and then opts for any other options.
+//
+//	db, err := sqlp.OpenWithOptions("postgres", dsn, sqlp.Options{
+//		MaxOpenConns:   20,
+//		MaxIdleConns:   5,
+//		DefaultTimeout: 3 * time.Second,
+//	})
+func OpenWithOptions(driverName, dataSourceName string, o Options, opts ...Option) (*DB, error) {
+	db, err := Open(driverName, dataSourceName, append(o.options(), opts...)...)
+	if err != nil {
+		return nil, err
+	}
+	o.setPool(db.DB)
+	return db, nil
+}
+
+// options returns the sqlp options of o.
+func (o Options) options() []Option {
+	var opts []Option
+	if o.Dialect != "" {
+		opts = append(opts, WithDialect(o.Dialect))
+	}
+	if o.Logger != nil {
+		opts = append(opts, WithLogger(o.Logger))
+	}
+	if o.DefaultTimeout > 0 {
+		opts = append(opts, WithDefaultTimeout(o.DefaultTimeout))
+	}
+	return opts
+}
+
+// setPool applies the pool settings of o to db.
+func (o Options) setPool(db *sql.DB) {
+	if o.MaxOpenConns > 0 {
+		db.SetMaxOpenConns(o.MaxOpenConns)
+	}
+	if o.MaxIdleConns > 0 {
+		db.SetMaxIdleConns(o.MaxIdleConns)
+	}
+	if o.ConnMaxLifetime > 0 {
+		db.SetConnMaxLifetime(o.ConnMaxLifetime)
+	}
+	if o.ConnMaxIdleTime > 0 {
+		db.SetConnMaxIdleTime(o.ConnMaxIdleTime)
+	}
+}
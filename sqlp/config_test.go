@@ -0,0 +1,122 @@
+package sqlp
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/greghart/powerputtygo/errcmp"
+	"github.com/greghart/powerputtygo/queryp"
+)
+
+func TestConfig(t *testing.T) {
+	t.Run("load env", func(t *testing.T) {
+		t.Setenv("APP_DB_DRIVER", "sqlite3")
+		t.Setenv("APP_DB_DSN", "./test.db")
+		t.Setenv("APP_DB_MAX_OPEN_CONNS", "10")
+		t.Setenv("APP_DB_DEFAULT_TIMEOUT", "3s")
+		t.Setenv("APP_DB_DIALECT", "sqlite")
+		t.Setenv("APP_DB_DRY_RUN", "true")
+
+		c := Config{MaxIdleConns: 2}
+		errcmp.MustMatch(t, c.LoadEnv("APP_DB_"), "")
+		expected := Config{
+			Driver:         "sqlite3",
+			DSN:            "./test.db",
+			MaxOpenConns:   10,
+			MaxIdleConns:   2,
+			DefaultTimeout: 3 * time.Second,
+			Dialect:        queryp.Sqlite,
+			DryRun:         true,
+		}
+		if diff := cmp.Diff(expected, c); diff != "" {
+			t.Errorf("loaded config unexpected:\n%v", diff)
+		}
+	})
+
+	t.Run("load env bad values -> err", func(t *testing.T) {
+		t.Setenv("APP_DB_MAX_ROWS", "lots")
+		t.Setenv("APP_DB_DEFAULT_TIMEOUT", "3")
+		var c Config
+		err := c.LoadEnv("APP_DB_")
+		errcmp.MustMatch(t, err, "APP_DB_DEFAULT_TIMEOUT: time: missing unit")
+		errcmp.MustMatch(t, err, `APP_DB_MAX_ROWS: strconv.ParseInt: parsing "lots"`)
+		if !errors.Is(err, ErrInvalidConfig) {
+			t.Errorf("expected ErrInvalidConfig, got %v", err)
+		}
+	})
+
+	t.Run("validate", func(t *testing.T) {
+		err := Config{Driver: "sqlite3", DSN: "./test.db"}.Validate()
+		errcmp.MustMatch(t, err, "")
+
+		err = Config{
+			Driver:       "oracle",
+			Dialect:      "oracle",
+			MaxOpenConns: 2,
+			MaxIdleConns: 5,
+			MaxRows:      -1,
+		}.Validate()
+		for _, expected := range []string{
+			"invalid config",
+			`driver "oracle" isn't registered`,
+			"dsn is required",
+			`unknown dialect "oracle"`,
+			"max rows can't be negative",
+			"max idle conns (5) can't be more than max open conns (2)",
+		} {
+			errcmp.MustMatch(t, err, expected)
+		}
+	})
+
+	t.Run("open", func(t *testing.T) {
+		db, err := OpenConfig(Config{
+			Driver:       "sqlite3",
+			DSN:          filepath.Join(t.TempDir(), "config.db"),
+			MaxOpenConns: 3,
+			Schema:       "main",
+			MaxRows:      1,
+		})
+		if err != nil {
+			t.Fatalf("failed to open: %v", err)
+		}
+		defer db.Close()
+		if db.Stats().MaxOpenConnections != 3 || db.schema != "main" {
+			t.Errorf("expected config applied, got %+v", db.Stats())
+		}
+		_, err = SelectScalars[int](context.Background(), db, "SELECT 1 UNION ALL SELECT 2")
+		errcmp.MustMatch(t, err, "more than 1 rows")
+
+		_, err = OpenConfig(Config{})
+		errcmp.MustMatch(t, err, "driver is required")
+	})
+}
+
+func TestOpenWithOptions(t *testing.T) {
+	logger := &testLogger{}
+	db, err := OpenWithOptions("sqlite3", filepath.Join(t.TempDir(), "options.db"), Options{
+		Dialect:        queryp.Sqlite,
+		Logger:         logger,
+		DefaultTimeout: time.Second,
+		MaxOpenConns:   4,
+	}, WithDryRun())
+	if err != nil {
+		t.Fatalf("failed to open: %v", err)
+	}
+	defer db.Close()
+
+	if db.Stats().MaxOpenConnections != 4 {
+		t.Errorf("expected pool settings applied, got %+v", db.Stats())
+	}
+	if db.dialect != queryp.Sqlite || db.defaultTimeout != time.Second {
+		t.Errorf("expected sqlp settings applied")
+	}
+	db.MustExec(context.Background(), "DROP TABLE nope")
+	if logs := logger.String(); !strings.Contains(logs, "dry run, skipped exec: DROP TABLE nope") {
+		t.Errorf("expected logger and extra options applied, got %v", logs)
+	}
+}
@@ -0,0 +1,91 @@
+package sqlp
+
+import (
+	"context"
+	"database/sql"
+)
+
+// Conn is a single connection of a DB's pool, for session-pinned work such as SET session
+// variables, temporary tables, and advisory locks, which need every statement on the same
+// connection. It has the same surface as DB, and Context pins other sqlp helpers (eg. repositories
+// and generic helpers) to it. Close returns the connection to the pool.
+type Conn struct {
+	db   *DB
+	conn *sql.Conn
+}
+
+// connKey is the context key of a connection pinned by Conn, keyed by the underlying pool like
+// txKey.
+type connKey struct {
+	db *sql.DB
+}
+
+// Conn returns a single connection of the pool, see Conn. It shadows sql.DB.Conn, which is still
+// available as db.DB.Conn.
+func (db *DB) Conn(ctx context.Context) (*Conn, error) {
+	conn, err := db.DB.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &Conn{db: db, conn: conn}, nil
+}
+
+// Context returns a context where db's statements run on the connection, unless they run in a
+// transaction.
+//
+//	people, err := repository.FindAllBy(conn.Context(ctx), "last_name", "Doe")
+func (c *Conn) Context(ctx context.Context) context.Context {
+	return context.WithValue(ctx, connKey{c.db.DB}, c.conn)
+}
+
+// Exec runs Exec on the connection.
+func (c *Conn) Exec(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return c.db.Exec(c.Context(ctx), query, args...)
+}
+
+// MustExec runs MustExec on the connection.
+func (c *Conn) MustExec(ctx context.Context, query string, args ...any) sql.Result {
+	return c.db.MustExec(c.Context(ctx), query, args...)
+}
+
+// Query runs Query on the connection.
+func (c *Conn) Query(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return c.db.Query(c.Context(ctx), query, args...)
+}
+
+// QueryRow runs QueryRow on the connection.
+func (c *Conn) QueryRow(ctx context.Context, query string, args ...any) *sql.Row {
+	return c.db.QueryRow(c.Context(ctx), query, args...)
+}
+
+// Get runs Get on the connection.
+func (c *Conn) Get(ctx context.Context, dest any, query string, args ...any) error {
+	return c.db.Get(c.Context(ctx), dest, query, args...)
+}
+
+// Select runs Select on the connection.
+func (c *Conn) Select(ctx context.Context, dest any, query string, args ...any) error {
+	return c.db.Select(c.Context(ctx), dest, query, args...)
+}
+
+// RunInTx runs RunInTx with its transaction on the connection.
+func (c *Conn) RunInTx(ctx context.Context, fn func(context.Context) error) error {
+	return c.db.RunInTx(c.Context(ctx), fn)
+}
+
+// Raw runs Raw with the connection's driver connection.
+func (c *Conn) Raw(ctx context.Context, fn func(driverConn any) error) error {
+	return c.db.Raw(c.Context(ctx), fn)
+}
+
+// Close returns the connection to the pool. Session state (eg. temporary tables) is left as is, so
+// reset anything later users of the connection shouldn't see first.
+func (c *Conn) Close() error {
+	return c.conn.Close()
+}
+
+// pinnedConn returns the connection pinned by context for db, if any.
+func (db *DB) pinnedConn(ctx context.Context) *sql.Conn {
+	conn, _ := ctx.Value(connKey{db.DB}).(*sql.Conn)
+	return conn
+}
@@ -0,0 +1,58 @@
+package sqlp
+
+import (
+	"context"
+	"testing"
+
+	"github.com/greghart/powerputtygo/errcmp"
+)
+
+func TestDB_Conn(t *testing.T) {
+	db, ctx, cleanup := testDB(t)
+	defer cleanup()
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		t.Fatalf("failed to get conn: %v", err)
+	}
+	defer conn.Close()
+
+	// Temporary tables only exist on the connection that created them
+	conn.MustExec(ctx, "CREATE TEMP TABLE scratch (id INTEGER PRIMARY KEY, name TEXT)")
+	err = conn.RunInTx(ctx, func(ctx context.Context) error {
+		_, err := db.Exec(ctx, "INSERT INTO scratch (name) VALUES (?), (?)", "John", "Jane")
+		return err
+	})
+	errcmp.MustMatch(t, err, "")
+
+	t.Run("select", func(t *testing.T) {
+		var names []struct {
+			Name string `sqlp:"name"`
+		}
+		err := conn.Select(ctx, &names, "SELECT name FROM scratch ORDER BY id")
+		errcmp.MustMatch(t, err, "")
+		if len(names) != 2 || names[0].Name != "John" || names[1].Name != "Jane" {
+			t.Errorf("unexpected names: %v", names)
+		}
+	})
+
+	t.Run("context pins helpers", func(t *testing.T) {
+		count, err := GetScalar[int](conn.Context(ctx), db, "SELECT COUNT(*) FROM scratch")
+		if err != nil || count != 2 {
+			t.Errorf("expected 2 rows, got %d, %v", count, err)
+		}
+	})
+
+	t.Run("other connections -> err", func(t *testing.T) {
+		_, err := db.Exec(ctx, "SELECT * FROM scratch")
+		errcmp.MustMatch(t, err, "no such table: scratch")
+	})
+
+	t.Run("parallel unpins", func(t *testing.T) {
+		err := Parallel(conn.Context(ctx), func(ctx context.Context) error {
+			_, err := db.Exec(ctx, "SELECT * FROM scratch")
+			return err
+		})
+		errcmp.MustMatch(t, err, "no such table: scratch")
+	})
+}
@@ -0,0 +1,77 @@
+package sqlp
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// WithConstraintError registers a domain error for violations of a constraint, eg. a unique
+// constraint "people_email_key" to ErrEmailTaken. The repository's Exec, ExecRows, ExecID, Select
+// and write helpers then return errors that wrap both the domain error and the driver's error, so
+// callers can branch on the former with errors.Is.
+//
+// Constraints are matched by name in the driver's error message, which Postgres and MySQL include.
+// SQLite only names the columns of unique and not null constraints, so register those instead,
+// eg. "people.email".
+func (r *Repository[E]) WithConstraintError(constraint string, err error) *Repository[E] {
+	if r.constraintErrors == nil {
+		r.constraintErrors = map[string]error{}
+	}
+	r.constraintErrors[constraint] = err
+	return r
+}
+
+// TranslateError returns err wrapped with the domain error of any registered constraint it
+// violates, see WithConstraintError. Useful for errors from queries run outside the repository.
+func (r *Repository[E]) TranslateError(err error) error {
+	if err == nil || len(r.constraintErrors) == 0 {
+		return err
+	}
+	msg := err.Error()
+	for constraint, domainErr := range r.constraintErrors {
+		if containsName(msg, constraint) {
+			return fmt.Errorf("%w: %w", domainErr, err)
+		}
+	}
+	return err
+}
+
+// Exec runs DB.Exec, translating constraint violations, see WithConstraintError.
+func (r *Repository[E]) Exec(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	res, err := r.DB.Exec(ctx, query, args...)
+	return res, r.TranslateError(err)
+}
+
+// ExecRows runs DB.ExecRows, translating constraint violations, see WithConstraintError.
+func (r *Repository[E]) ExecRows(ctx context.Context, query string, args ...any) (int64, error) {
+	n, err := r.DB.ExecRows(ctx, query, args...)
+	return n, r.TranslateError(err)
+}
+
+// ExecID runs DB.ExecID, translating constraint violations, see WithConstraintError.
+func (r *Repository[E]) ExecID(ctx context.Context, query string, args ...any) (int64, error) {
+	id, err := r.DB.ExecID(ctx, query, args...)
+	return id, r.TranslateError(err)
+}
+
+// containsName returns whether s contains name as a whole identifier, so eg. "people_email" doesn't
+// match a "people_email_key" constraint.
+func containsName(s, name string) bool {
+	for i := 0; ; {
+		j := strings.Index(s[i:], name)
+		if j < 0 {
+			return false
+		}
+		start, end := i+j, i+j+len(name)
+		if (start == 0 || !isIdentByte(s[start-1])) && (end == len(s) || !isIdentByte(s[end])) {
+			return true
+		}
+		i = start + 1
+	}
+}
+
+func isIdentByte(c byte) bool {
+	return c == '_' || '0' <= c && c <= '9' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
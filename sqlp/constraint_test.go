@@ -0,0 +1,63 @@
+package sqlp
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/greghart/powerputtygo/errcmp"
+)
+
+func TestRepository_WithConstraintError(t *testing.T) {
+	db, ctx, cleanup := testDB(t)
+	defer cleanup()
+	db.MustExec(ctx, "CREATE UNIQUE INDEX people_first_name ON people (first_name)")
+	albertSetup(ctx, db)
+
+	errNameTaken := errors.New("name taken")
+	repository := NewRepository[person](db, "people").WithConstraintError("people.first_name", errNameTaken)
+	insert := "INSERT INTO people (first_name, last_name) VALUES (?, ?)"
+
+	_, err := repository.Exec(ctx, insert, "Albert", "Einstein")
+	errcmp.MustMatch(t, err, "name taken: UNIQUE constraint failed: people.first_name")
+	if !errors.Is(err, errNameTaken) {
+		t.Errorf("expected Exec error to be errNameTaken, got %v", err)
+	}
+	if _, err := repository.ExecID(ctx, insert, "Albert", "Einstein"); !errors.Is(err, errNameTaken) {
+		t.Errorf("expected ExecID error to be errNameTaken, got %v", err)
+	}
+	if _, err := repository.Select(ctx, insert+" RETURNING *", "Albert", "Einstein"); !errors.Is(err, errNameTaken) {
+		t.Errorf("expected Select error to be errNameTaken, got %v", err)
+	}
+	bob := must(db.ExecID(ctx, insert, "Bob", "Smith"))
+	if _, err := repository.UpdateWhereIDs(ctx, []int64{bob}, map[string]any{"first_name": "Albert"}); !errors.Is(err, errNameTaken) {
+		t.Errorf("expected UpdateWhereIDs error to be errNameTaken, got %v", err)
+	}
+
+	_, err = NewRepository[person](db, "people").Exec(ctx, insert, "Albert", "Einstein")
+	errcmp.MustMatch(t, err, "UNIQUE constraint failed")
+	if errors.Is(err, errNameTaken) {
+		t.Errorf("expected unregistered repository to not translate, got %v", err)
+	}
+}
+
+func TestContainsName(t *testing.T) {
+	tests := map[string]struct {
+		s, name  string
+		expected bool
+	}{
+		"postgres":  {`pq: duplicate key value violates unique constraint "people_email_key"`, "people_email_key", true},
+		"mysql":     {"Duplicate entry 'a' for key 'people.people_email_key'", "people_email_key", true},
+		"sqlite":    {"UNIQUE constraint failed: people.email", "people.email", true},
+		"prefix":    {`unique constraint "people_email_key"`, "people_email", false},
+		"suffix":    {"UNIQUE constraint failed: people.email_address", "people.email", false},
+		"repeated":  {"people_email_key_2 people_email_key", "people_email_key", true},
+		"not found": {"some other error", "people_email_key", false},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := containsName(test.s, test.name); got != test.expected {
+				t.Errorf("expected %v, got %v", test.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,188 @@
+package sqlp
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/greghart/powerputtygo/queryp"
+)
+
+// CopySource is a source of rows for CopyFrom, read one at a time so rows can be streamed (eg. from
+// a file) rather than held in memory.
+type CopySource interface {
+	// Next advances to the next row, returning false when there are no more or on error.
+	Next() bool
+	// Values returns the values of the current row, in the order of the columns.
+	Values() ([]any, error)
+	// Err returns the error, if any, that stopped Next.
+	Err() error
+}
+
+// CopyFromRows returns a CopySource over rows held in memory.
+func CopyFromRows(rows [][]any) CopySource {
+	return &copyFromRows{rows: rows, i: -1}
+}
+
+type copyFromRows struct {
+	rows [][]any
+	i    int
+}
+
+func (c *copyFromRows) Next() bool {
+	c.i++
+	return c.i < len(c.rows)
+}
+
+func (c *copyFromRows) Values() ([]any, error) { return c.rows[c.i], nil }
+func (c *copyFromRows) Err() error             { return nil }
+
+// CopyFrom inserts the rows of src for columns into table, for mass ingestion. The table (which may
+// be schema qualified) and columns are quoted for the dialect. With lib/pq it uses
+// Postgres' COPY FROM STDIN (as pq.CopyIn does); otherwise it falls back to multi-row INSERTs,
+// chunked as BulkInsertRows does, reading only a chunk of src at a time. pgx's COPY isn't reachable
+// through database/sql, so use Raw with pgx's CopyFrom for it. The rows are inserted in one
+// transaction (the context's, if any), so either all are inserted or none are. Returns the rows
+// inserted.
+func (db *DB) CopyFrom(ctx context.Context, table string, columns []string, src CopySource) (int64, error) {
+	if len(columns) == 0 {
+		return 0, fmt.Errorf("sqlp: copy into %s needs columns", table)
+	}
+	table, columns = db.quoteCopyTarget(table, columns)
+	if db.isDryRun(ctx) {
+		db.logf("sqlp: dry run, skipped copy into %s (%s)", table, strings.Join(columns, ", "))
+		return 0, nil
+	}
+	var inserted int64
+	err := db.RunInTx(ctx, func(ctx context.Context) (err error) {
+		if db.supportsCopyIn() {
+			inserted, err = db.copyIn(ctx, table, columns, src)
+		} else {
+			inserted, err = db.copyInsert(ctx, table, columns, src)
+		}
+		return err
+	})
+	return inserted, err
+}
+
+// quoteCopyTarget quotes table (and its schema, if qualified) and columns for the dialect.
+func (db *DB) quoteCopyTarget(table string, columns []string) (string, []string) {
+	d := db.Dialect()
+	parts := strings.Split(table, ".")
+	for i, part := range parts {
+		parts[i] = d.QuoteIdent(part)
+	}
+	quoted := make([]string, len(columns))
+	for i, column := range columns {
+		quoted[i] = d.QuoteIdent(column)
+	}
+	return strings.Join(parts, "."), quoted
+}
+
+// supportsCopyIn returns whether the driver runs COPY FROM STDIN through prepared statements.
+func (db *DB) supportsCopyIn() bool {
+	return reflect.TypeOf(db.Driver()).String() == "*pq.Driver"
+}
+
+// copyIn copies src with a COPY FROM STDIN statement, which lib/pq runs by sending each execution's
+// values as a row, and flushing on an execution without values. The whole copy is one statement on
+// the transaction, captured as such once it's flushed.
+func (db *DB) copyIn(ctx context.Context, table string, columns []string, src CopySource) (int64, error) {
+	ctx, cancel := db.timeoutContext(ctx)
+	defer cancel()
+	release, err := db.useTx(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer release()
+
+	start := time.Now()
+	q := fmt.Sprintf("COPY %s (%s) FROM STDIN", table, strings.Join(columns, ", "))
+	res, err := db.copyInStmt(ctx, q, len(columns), src)
+	db.captureExec(ctx, start, q, nil, res, err)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// copyInStmt runs the COPY statement q for the rows of src.
+func (db *DB) copyInStmt(ctx context.Context, q string, columns int, src CopySource) (sql.Result, error) {
+	stmt, err := db.queryer(ctx).PrepareContext(ctx, q)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare copy: %w", err)
+	}
+	defer stmt.Close()
+
+	for i := 0; src.Next(); i++ {
+		values, err := copyValues(src, i, columns)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := stmt.ExecContext(ctx, values...); err != nil {
+			return nil, fmt.Errorf("failed to copy row %d: %w", i, err)
+		}
+	}
+	if err := src.Err(); err != nil {
+		return nil, fmt.Errorf("sqlp: copy source failed: %w", err)
+	}
+	res, err := stmt.ExecContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy: %w", err)
+	}
+	return res, nil
+}
+
+// copyInsert copies src with multi-row INSERTs, reading a statement's worth of rows at a time.
+func (db *DB) copyInsert(ctx context.Context, table string, columns []string, src CopySource) (int64, error) {
+	limit, ok := maxParams[db.Dialect()]
+	if !ok {
+		limit = 999
+	}
+	chunk := make([][]any, 0, max(limit/len(columns), 1))
+
+	var inserted int64
+	flush := func() error {
+		if len(chunk) == 0 {
+			return nil
+		}
+		q, args := queryp.InsertValues(table, columns, chunk).Execute(db.Dialect().Placeholderer())
+		n, err := db.ExecRows(ctx, q, args...)
+		inserted += n
+		chunk = chunk[:0]
+		return err
+	}
+	for i := 0; src.Next(); i++ {
+		values, err := copyValues(src, i, len(columns))
+		if err != nil {
+			return 0, err
+		}
+		if chunk = append(chunk, values); len(chunk) == cap(chunk) {
+			if err := flush(); err != nil {
+				return 0, err
+			}
+		}
+	}
+	if err := src.Err(); err != nil {
+		return 0, fmt.Errorf("sqlp: copy source failed: %w", err)
+	}
+	if err := flush(); err != nil {
+		return 0, err
+	}
+	return inserted, nil
+}
+
+// copyValues returns the values of src's current row, the i'th, checking there's one per column.
+func copyValues(src CopySource, i, columns int) ([]any, error) {
+	values, err := src.Values()
+	if err != nil {
+		return nil, fmt.Errorf("sqlp: copy source row %d: %w", i, err)
+	}
+	if len(values) != columns {
+		return nil, fmt.Errorf("sqlp: copy row %d has %d values for %d columns", i, len(values), columns)
+	}
+	return values, nil
+}
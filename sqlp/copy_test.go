@@ -0,0 +1,94 @@
+package sqlp
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/greghart/powerputtygo/errcmp"
+	"github.com/greghart/powerputtygo/queryp"
+)
+
+// failingSource is a CopySource that errors after its rows.
+type failingSource struct {
+	CopySource
+}
+
+func (f failingSource) Err() error { return errors.New("file truncated") }
+
+func TestDB_CopyFrom(t *testing.T) {
+	db, ctx, cleanup := testDB(t)
+	defer cleanup()
+
+	count := func() int {
+		count, err := GetScalar[int](ctx, db, "SELECT COUNT(*) FROM people")
+		errcmp.MustMatch(t, err, "")
+		return count
+	}
+
+	t.Run("without copy support -> chunked inserts", func(t *testing.T) {
+		defer db.MustExec(ctx, "DELETE FROM people")
+		defer func(n int) { maxParams[queryp.Sqlite] = n }(maxParams[queryp.Sqlite])
+		maxParams[queryp.Sqlite] = 5 // 2 rows of 2 columns per statement
+
+		ctx, capture := CaptureQueries(ctx)
+		n, err := db.CopyFrom(ctx, "people", []string{"first_name", "last_name"}, CopyFromRows([][]any{
+			{"John", "Doe"}, {"Jane", "Doe"}, {"Jim", "Doe"}, {"Jill", "Doe"}, {"Jack", "Doe"},
+		}))
+		if err != nil || n != 5 {
+			t.Fatalf("expected 5 inserted, got %d, %v", n, err)
+		}
+		queries := capture.Queries()
+		if len(queries) != 3 ||
+			queries[0].Query != `INSERT INTO "people" ("first_name", "last_name") VALUES (?, ?), (?, ?)` ||
+			queries[2].Query != `INSERT INTO "people" ("first_name", "last_name") VALUES (?, ?)` {
+			t.Errorf("expected 3 statements, got %v", queries)
+		}
+		if count() != 5 {
+			t.Errorf("expected 5 people, got %d", count())
+		}
+	})
+
+	t.Run("qualified table", func(t *testing.T) {
+		defer db.MustExec(ctx, "DELETE FROM people")
+		n, err := db.CopyFrom(ctx, "main.people", []string{"first_name"}, CopyFromRows([][]any{{"John"}}))
+		if err != nil || n != 1 {
+			t.Fatalf("expected 1 inserted, got %d, %v", n, err)
+		}
+	})
+
+	t.Run("dry run -> skipped", func(t *testing.T) {
+		logger := &testLogger{}
+		db := NewDB(db.DB, WithLogger(logger), WithDryRun())
+		n, err := db.CopyFrom(ctx, "people", []string{"first_name"}, CopyFromRows([][]any{{"John"}}))
+		if err != nil || n != 0 {
+			t.Fatalf("expected nothing inserted, got %d, %v", n, err)
+		}
+		if count() != 0 {
+			t.Errorf("expected dry run to not insert")
+		}
+		if logs := logger.String(); !strings.Contains(logs, `skipped copy into "people" ("first_name")`) {
+			t.Errorf("expected dry run copy logged, got %v", logs)
+		}
+	})
+
+	t.Run("failed source -> none inserted", func(t *testing.T) {
+		defer func(n int) { maxParams[queryp.Sqlite] = n }(maxParams[queryp.Sqlite])
+		maxParams[queryp.Sqlite] = 2
+
+		_, err := db.CopyFrom(ctx, "people", []string{"first_name"}, failingSource{CopyFromRows([][]any{
+			{"John"}, {"Jane"}, {"Jim"},
+		})})
+		errcmp.MustMatch(t, err, "copy source failed: file truncated")
+		if count() != 0 {
+			t.Errorf("expected earlier chunks rolled back")
+		}
+	})
+
+	t.Run("bad rows -> err", func(t *testing.T) {
+		_, err := db.CopyFrom(ctx, "people", []string{"first_name"}, CopyFromRows([][]any{{"John", "Doe"}}))
+		errcmp.MustMatch(t, err, "copy row 0 has 2 values for 1 columns")
+		_, err = db.CopyFrom(ctx, "people", nil, CopyFromRows(nil))
+		errcmp.MustMatch(t, err, "copy into people needs columns")
+	})
+}
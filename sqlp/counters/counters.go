@@ -0,0 +1,124 @@
+// Package counters provides windowed counters stored in a table, eg. for rate limiting or quota
+// tracking across processes.
+// Counters are sqlp transaction-aware, so increments can be committed (or rolled back) together
+// with the business writes they count.
+package counters
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/greghart/powerputtygo/queryp"
+	"github.com/greghart/powerputtygo/sqlp"
+)
+
+// DefaultTable is the table counters are stored in, unless given another.
+const DefaultTable = "sqlp_counters"
+
+// Counters are named counters that reset every window (fixed windows, aligned to the Unix epoch).
+type Counters struct {
+	db    *sqlp.DB
+	table string
+}
+
+// New returns counters stored in table (defaulting to DefaultTable), see CreateTable.
+//
+//	limiter := counters.New(db)
+//	ok, err := limiter.Allow(ctx, "login:"+ip, 5, time.Minute)
+func New(db *sqlp.DB, table ...string) *Counters {
+	c := &Counters{db: db, table: DefaultTable}
+	if len(table) > 0 {
+		c.table = table[0]
+	}
+	return c
+}
+
+// CreateTable creates the counters table if it doesn't exist.
+// Intended for migrations and tests.
+func (c *Counters) CreateTable(ctx context.Context) error {
+	_, err := c.db.Exec(
+		ctx,
+		"CREATE TABLE IF NOT EXISTS "+c.table+
+			" (name VARCHAR(255) PRIMARY KEY, total BIGINT NOT NULL, window_start TIMESTAMP NOT NULL)",
+	)
+	return err
+}
+
+// Increment adds n to the counter in the current window, returning its new total.
+// It's a single upsert, so concurrent increments don't lose counts. A window of 0 never resets.
+func (c *Counters) Increment(ctx context.Context, name string, n int64, window time.Duration) (int64, error) {
+	args := queryp.NewArgs().WithPlaceholderer(c.db.Dialect().Placeholderer())
+	q := "INSERT INTO " + c.table + " (name, total, window_start) VALUES (" +
+		args.Add(name) + ", " + args.Add(n) + ", " + args.Add(windowStart(window)) + ")"
+
+	var total int64
+	switch c.db.Dialect() {
+	case queryp.Postgres, queryp.Sqlite:
+		// A later window resets the total (SET expressions all see the old row)
+		q += " ON CONFLICT (name) DO UPDATE SET" +
+			" total = CASE WHEN " + c.table + ".window_start < excluded.window_start" +
+			" THEN excluded.total ELSE " + c.table + ".total + excluded.total END," +
+			" window_start = CASE WHEN " + c.table + ".window_start < excluded.window_start" +
+			" THEN excluded.window_start ELSE " + c.table + ".window_start END" +
+			" RETURNING total"
+		err := c.db.QueryRow(ctx, q, args.Args()...).Scan(&total)
+		return total, err
+	case queryp.MySQL:
+		// MySQL assigns in order, so total must be set while window_start is still the old one
+		q += " ON DUPLICATE KEY UPDATE" +
+			" total = IF(window_start < VALUES(window_start), VALUES(total), total + VALUES(total))," +
+			" window_start = GREATEST(window_start, VALUES(window_start))"
+		err := c.db.RunInTx(ctx, func(ctx context.Context) error {
+			if _, err := c.db.Exec(ctx, q, args.Args()...); err != nil {
+				return err
+			}
+			return c.db.QueryRow(ctx, "SELECT total FROM "+c.table+" WHERE name = ?", name).Scan(&total)
+		})
+		return total, err
+	default:
+		return 0, fmt.Errorf("counters: Increment needs a dialect, and driver %T is unknown (see sqlp.WithDialect)", c.db.Driver())
+	}
+}
+
+// Allow counts an attempt against a limit per window, returning whether it's within the limit.
+// Attempts over the limit still count, so callers retrying in a loop stay limited.
+func (c *Counters) Allow(ctx context.Context, name string, limit int64, window time.Duration) (bool, error) {
+	total, err := c.Increment(ctx, name, 1, window)
+	if err != nil {
+		return false, err
+	}
+	return total <= limit, nil
+}
+
+// Get returns the counter's total in the current window, 0 if it has none.
+func (c *Counters) Get(ctx context.Context, name string, window time.Duration) (int64, error) {
+	args := queryp.NewArgs().WithPlaceholderer(c.db.Dialect().Placeholderer())
+	var total int64
+	err := c.db.QueryRow(
+		ctx,
+		"SELECT total FROM "+c.table+" WHERE name = "+args.Add(name)+" AND window_start >= "+args.Add(windowStart(window)),
+		args.Args()...,
+	).Scan(&total)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	return total, err
+}
+
+// Reset deletes the counter.
+func (c *Counters) Reset(ctx context.Context, name string) error {
+	args := queryp.NewArgs().WithPlaceholderer(c.db.Dialect().Placeholderer())
+	_, err := c.db.Exec(ctx, "DELETE FROM "+c.table+" WHERE name = "+args.Add(name), args.Args()...)
+	return err
+}
+
+// windowStart returns the start of the current window.
+func windowStart(window time.Duration) time.Time {
+	if window <= 0 {
+		return time.Unix(0, 0).UTC()
+	}
+	return time.Now().UTC().Truncate(window)
+}
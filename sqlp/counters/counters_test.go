@@ -0,0 +1,64 @@
+package counters
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/greghart/powerputtygo/sqlp"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestCounters(t *testing.T) {
+	ctx := context.Background()
+	db, err := sqlp.Open("sqlite3", filepath.Join(t.TempDir(), "counters.db"))
+	if err != nil {
+		t.Fatalf("failed to open: %v", err)
+	}
+	defer db.Close()
+	c := New(db)
+	if err := c.CreateTable(ctx); err != nil {
+		t.Fatalf("CreateTable: %v", err)
+	}
+
+	for i, expected := range []bool{true, true, true, false, false} {
+		ok, err := c.Allow(ctx, "login", 3, time.Hour)
+		if err != nil {
+			t.Fatalf("Allow: %v", err)
+		}
+		if ok != expected {
+			t.Errorf("attempt %d: expected allowed %v", i+1, expected)
+		}
+	}
+	if total, err := c.Get(ctx, "login", time.Hour); err != nil || total != 5 {
+		t.Errorf("expected 5 attempts, got %d, %v", total, err)
+	}
+
+	// A new window resets
+	db.MustExec(ctx, "UPDATE sqlp_counters SET window_start = ?", time.Now().UTC().Add(-2*time.Hour).Truncate(time.Hour))
+	if total, err := c.Get(ctx, "login", time.Hour); err != nil || total != 0 {
+		t.Errorf("expected no attempts in new window, got %d, %v", total, err)
+	}
+	if total, err := c.Increment(ctx, "login", 2, time.Hour); err != nil || total != 2 {
+		t.Errorf("expected reset total of 2, got %d, %v", total, err)
+	}
+
+	// Increments roll back with the transaction they're in
+	_ = db.RunInTx(ctx, func(ctx context.Context) error {
+		if _, err := c.Increment(ctx, "login", 10, time.Hour); err != nil {
+			t.Fatalf("Increment: %v", err)
+		}
+		return context.Canceled
+	})
+	if total, _ := c.Get(ctx, "login", time.Hour); total != 2 {
+		t.Errorf("expected rolled back increment, got %d", total)
+	}
+
+	if err := c.Reset(ctx, "login"); err != nil {
+		t.Fatalf("Reset: %v", err)
+	}
+	if total, err := c.Increment(ctx, "login", 1, 0); err != nil || total != 1 {
+		t.Errorf("expected total of 1 after reset, got %d, %v", total, err)
+	}
+}
@@ -3,27 +3,65 @@ package sqlp
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"log"
 	"reflect"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/greghart/powerputtygo/queryp"
+	"github.com/greghart/powerputtygo/sqlp/internal/reflectp"
 )
 
 // DB extends the stdlib sql.DB type to add additional behavior.
 type DB struct {
 	*sql.DB
+
+	logger          Logger
+	detectRowsLeaks bool
+	dryRun          bool
+	txLeakThreshold time.Duration
+	dialect         queryp.Dialect
+	schema          string
+	inflector       func(typeName string) string
+	normalization   reflectp.Normalization
+	maxRows         rowLimit   // See WithMaxRows
+	stmts           *stmtCache // Prepared statements, if enabled with WithStmtCache
+	defaultTimeout  time.Duration
+
+	deleteAllOutsideTests bool
+	idempotencyTableName  string
+
+	txHook                 func(ctx context.Context, outcome TxOutcome, err error)
+	preCommitHooks         []func(ctx context.Context) error
+	savepoints             atomic.Int64 // For unique savepoint names
+	skipReadOnlySavepoints bool
 }
 
 // NewDB builds a new sqlp.DB for when you already have an existing sql.DB.
-func NewDB(db *sql.DB) *DB {
-	return &DB{db}
+func NewDB(db *sql.DB, opts ...Option) *DB {
+	out := &DB{
+		DB:     db,
+		logger: log.Default(),
+	}
+	for _, opt := range opts {
+		opt(out)
+	}
+	return out
 }
 
-func Open(driverName, dataSourceName string) (*DB, error) {
+func Open(driverName, dataSourceName string, opts ...Option) (*DB, error) {
 	db, err := sql.Open(driverName, dataSourceName)
 	if err != nil {
 		return nil, err
 	}
 
-	return NewDB(db), nil
+	return NewDB(db, opts...), nil
 }
 
 ////////////////////////////////////////////////////////////////////////////////
@@ -31,79 +69,507 @@ func Open(driverName, dataSourceName string) (*DB, error) {
 
 // Exec runs ExecContext.
 func (db *DB) Exec(ctx context.Context, query string, args ...any) (sql.Result, error) {
-	return db.queryer(ctx).ExecContext(ctx, query, args...)
+	ctx, cancel := db.timeoutContext(ctx)
+	defer cancel()
+	start := time.Now()
+	var res sql.Result
+	var err error
+	if db.isDryRun(ctx) {
+		res = db.dryRunExec(query, args)
+	} else if release, txErr := db.useTx(ctx); txErr != nil {
+		err = txErr
+	} else {
+		if stmt, done := db.stmt(ctx, query); stmt != nil {
+			res, err = stmt.ExecContext(ctx, args...)
+			done()
+		} else {
+			res, err = db.queryer(ctx).ExecContext(ctx, query, args...)
+		}
+		release()
+	}
+	db.captureExec(ctx, start, query, args, res, err)
+	return res, err
+}
+
+// MustExec runs Exec, panicking on error. Intended for migrations and tests.
+func (db *DB) MustExec(ctx context.Context, query string, args ...any) sql.Result {
+	res, err := db.Exec(ctx, query, args...)
+	if err != nil {
+		panic(err)
+	}
+	return res
+}
+
+// ExecRows runs Exec, returning the number of rows affected.
+func (db *DB) ExecRows(ctx context.Context, query string, args ...any) (int64, error) {
+	res, err := db.Exec(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// ExecID runs Exec, returning the last inserted id.
+// Note not all drivers support this (eg. Postgres, use `RETURNING id` with ExecReturning instead).
+func (db *DB) ExecID(ctx context.Context, query string, args ...any) (int64, error) {
+	res, err := db.Exec(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+// ExecReturning runs a statement with a `RETURNING` clause (eg. `INSERT ... RETURNING id,
+// created_at`), scanning the returned rows into dest as Select does for a pointer to a slice, and as
+// Get does otherwise. Unlike running the statement through Get, it's an Exec-class statement, so
+// it's skipped by dry runs, leaving dest untouched.
+// Note MySQL has no `RETURNING` clause.
+func (db *DB) ExecReturning(ctx context.Context, dest any, query string, args ...any) error {
+	if db.isDryRun(ctx) {
+		db.dryRunExec(query, args)
+		return nil
+	}
+	if t := reflect.TypeOf(dest); t != nil && t.Kind() == reflect.Pointer && t.Elem().Kind() == reflect.Slice {
+		return db.Select(ctx, dest, query, args...)
+	}
+	return db.Get(ctx, dest, query, args...)
+}
+
+// Prepare runs PrepareContext, preparing the statement on the context's transaction if any.
+func (db *DB) Prepare(ctx context.Context, query string) (*sql.Stmt, error) {
+	return db.queryer(ctx).PrepareContext(ctx, query)
+}
+
+// Raw runs fn with the underlying driver connection -- the transaction's if the context has one --
+// as an escape hatch for driver specific features (eg. bulk copy APIs). As with sql.Conn.Raw, the
+// connection must not be used outside of fn.
+func (db *DB) Raw(ctx context.Context, fn func(driverConn any) error) error {
+	if state := db.txState(ctx); state != nil {
+		if state.conn == nil {
+			return errors.New("sqlp: Raw is unavailable in transactions adopted with WithTx")
+		}
+		return state.conn.Raw(fn)
+	}
+	if conn := db.pinnedConn(ctx); conn != nil {
+		return conn.Raw(fn)
+	}
+	conn, err := db.DB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	return conn.Raw(fn)
 }
 
 // Query runs QueryContext.
+// The default timeout, if any (see WithDefaultTimeout), covers reading the rows as well. Rows need
+// the context until they're closed, so its timer is released once the rows are closed and
+// collected; the helpers that read rows in full (Select, SelectFunc, etc.) release it on return.
 func (db *DB) Query(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
-	return db.queryer(ctx).QueryContext(ctx, query, args...)
+	timeoutCtx, cancel := db.timeoutContext(ctx)
+	timed, ctx := timeoutCtx != ctx, timeoutCtx
+	start := time.Now()
+	release, err := db.useTx(ctx)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	var rows *sql.Rows
+	if stmt, done := db.stmt(ctx, query); stmt != nil {
+		rows, err = stmt.QueryContext(ctx, args...)
+		done()
+	} else {
+		rows, err = db.queryer(ctx).QueryContext(ctx, query, args...)
+	}
+	release()
+	if err != nil {
+		cancel()
+	} else {
+		if timed {
+			cancelOnCollect(rows, cancel)
+		}
+		if state := db.txState(ctx); state != nil {
+			state.mu.Lock()
+			state.rows = append(state.rows, rows)
+			state.mu.Unlock()
+		}
+	}
+	db.captureQuery(ctx, start, query, args, rows, err)
+	if err == nil && db.detectRowsLeaks {
+		db.trackRows(rows)
+	}
+	return rows, err
 }
 
 // QueryRow runs QueryRowContext.
+// The default timeout, if any (see WithDefaultTimeout), covers scanning the row as well, and is
+// released once the row is collected (or right away on error).
+// On a contextual transaction, the row is expected to be scanned right away: unlike Query's rows,
+// it isn't tracked until then, as the transaction's other statements can't see when it's scanned.
 func (db *DB) QueryRow(ctx context.Context, query string, args ...any) *sql.Row {
-	return db.queryer(ctx).QueryRowContext(ctx, query, args...)
+	timeoutCtx, cancel := db.timeoutContext(ctx)
+	timed, ctx := timeoutCtx != ctx, timeoutCtx
+	start := time.Now()
+	var row *sql.Row
+	if release, err := db.useTx(ctx); err != nil {
+		// A *sql.Row can't be built with an error, so query with a context that's done with it.
+		row = db.queryer(ctx).QueryRowContext(errContext{ctx, err}, query, args...)
+	} else {
+		if stmt, done := db.stmt(ctx, query); stmt != nil {
+			row = stmt.QueryRowContext(ctx, args...)
+			done()
+		} else {
+			row = db.queryer(ctx).QueryRowContext(ctx, query, args...)
+		}
+		release()
+	}
+	db.captureQuery(ctx, start, query, args, nil, row.Err())
+	if row.Err() != nil {
+		cancel()
+	} else if timed {
+		cancelOnCollect(row, cancel)
+	}
+	return row
+}
+
+// errContext is a context that's done with err.
+type errContext struct {
+	context.Context
+	err error
+}
+
+func (c errContext) Done() <-chan struct{} { return closedDone }
+func (c errContext) Err() error            { return c.err }
+
+var closedDone = func() chan struct{} {
+	done := make(chan struct{})
+	close(done)
+	return done
+}()
+
+// trackRows sets up a finalizer to report rows that are collected while still open.
+func (db *DB) trackRows(rows *sql.Rows) {
+	pcs := make([]uintptr, 16)
+	pcs = pcs[:runtime.Callers(3, pcs)] // skip Callers, trackRows, and Query
+	runtime.SetFinalizer(rows, func(rows *sql.Rows) {
+		// Closed rows (including fully iterated ones) error here.
+		if _, err := rows.Columns(); err != nil {
+			return
+		}
+		db.logf("sqlp: rows were never closed, query called from:\n%s", formatCallers(pcs))
+		rows.Close()
+	})
+}
+
+// formatCallers formats program counters as a readable stack.
+func formatCallers(pcs []uintptr) string {
+	b := strings.Builder{}
+	frames := runtime.CallersFrames(pcs)
+	for {
+		frame, more := frames.Next()
+		fmt.Fprintf(&b, "\t%s\n\t\t%s:%d\n", frame.Function, frame.File, frame.Line)
+		if !more {
+			break
+		}
+	}
+	return b.String()
 }
 
 ////////////////////////////////////////////////////////////////////////////////
 // Transactional APIs
 
+// Queryer is what queries run through, satisfied by both *sql.DB and *sql.Tx.
 type Queryer interface {
 	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
 	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
 	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+}
+
+// txState is the context value of a RunInTx transaction.
+type txState struct {
+	tx   *sql.Tx
+	conn *sql.Conn   // The connection the transaction is on, unless adopted with WithTx
+	busy atomic.Bool // Whether a statement is running on tx, to catch concurrent use
+
+	mu        sync.Mutex
+	preCommit []func(ctx context.Context) error // See BeforeCommit
+	rows      []*sql.Rows                       // Rows of queries on tx, busy until closed
 }
 
 type contextKeyType string
 
-const (
-	ctxKey = contextKeyType("sqlp")
-)
+// txKey is the context key of a database's transaction. It's keyed by the underlying pool, so
+// sqlp.DBs sharing a pool share transactions, while different databases never see each other's.
+type txKey struct {
+	db *sql.DB
+}
 
 // RunInTx runs the callback fxn in a transaction.
-// If context already has a transaction, it will use that one.
+// If context already has a transaction (from an outer RunInTx, or adopted with WithTx), it will use
+// that one, leaving it to its owner to commit.
 // You can return an error from the callback to trigger the transaction to rollback.
 func (db *DB) RunInTx(ctx context.Context, fn func(context.Context) error) error {
-	tx := db.txContext(ctx)
-	// Setup new tx as needed.
-	if tx == nil {
-		_tx, err := db.DB.BeginTx(ctx, nil)
-		if err != nil {
+	if db.txContext(ctx) != nil {
+		return fn(ctx)
+	}
+
+	// Begin on a dedicated connection (the pinned one, if any), so Raw can reach it.
+	conn := db.pinnedConn(ctx)
+	if conn == nil {
+		var err error
+		if conn, err = db.DB.Conn(ctx); err != nil {
 			return err
 		}
-		tx = _tx
-		defer func() {
-			err := tx.Rollback()
-			if err != nil && err != sql.ErrTxDone {
-				// Rolled back due to error, but errored on rollback.
-				fmt.Printf("failed to rollback transaction: %v\n", err)
-			}
-		}()
-		ctx = context.WithValue(ctx, ctxKey, tx)
+		defer conn.Close()
+	}
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	release := db.trackTx(ctx, false)
+	defer func() {
+		release()
+		err := tx.Rollback()
+		if err != nil && err != sql.ErrTxDone {
+			// Rolled back due to error, but errored on rollback.
+			db.logf("failed to rollback transaction: %v\n", err)
+		}
+	}()
+	state := &txState{tx: tx, conn: conn}
+	ctx = context.WithValue(ctx, txKey{db.DB}, state)
+
+	err = fn(ctx)
+	if err == nil && ctx.Err() == nil {
+		err = db.preCommit(ctx, state)
+	}
+	if err != nil || ctx.Err() != nil {
+		if ctx.Err() != nil {
+			err = rollbackTx(tx, canceledTxError(ctx, err))
+			db.txDone(ctx, TxCanceled, err)
+			return err
+		}
+		err = rollbackTx(tx, err)
+		db.txDone(ctx, TxRolledBack, err)
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		err = fmt.Errorf("%w: %w", ErrCommitFailed, err)
+		db.txDone(ctx, TxCommitFailed, err)
+		return err
+	}
+	db.txDone(ctx, TxCommitted, nil)
+	return nil
+}
+
+// rollbackTx rolls back tx after err, joining any failure to do so as ErrRollbackFailed.
+func rollbackTx(tx *sql.Tx, err error) error {
+	if rbErr := tx.Rollback(); rbErr != nil && !errors.Is(rbErr, sql.ErrTxDone) {
+		return errors.Join(err, fmt.Errorf("%w: %w", ErrRollbackFailed, rbErr))
 	}
+	return err
+}
+
+// TxOutcome is how a RunInTx transaction ended, see WithTxHook.
+type TxOutcome int
+
+const (
+	TxCommitted    TxOutcome = iota
+	TxRolledBack             // The callback returned an error
+	TxCanceled               // The context was done before commit, see ErrTxCanceled
+	TxCommitFailed           // The callback succeeded, but the commit failed
+)
+
+func (o TxOutcome) String() string {
+	switch o {
+	case TxCommitted:
+		return "committed"
+	case TxRolledBack:
+		return "rolled back"
+	case TxCanceled:
+		return "canceled"
+	case TxCommitFailed:
+		return "commit failed"
+	}
+	return fmt.Sprintf("TxOutcome(%d)", int(o))
+}
+
+// canceledTxError returns the error of a transaction whose context was done, wrapping
+// ErrTxCanceled, the context's cause, and the callback's error if it's something else.
+func canceledTxError(ctx context.Context, err error) error {
+	cause := context.Cause(ctx)
+	if err == nil || errors.Is(err, cause) {
+		return fmt.Errorf("%w: %w", ErrTxCanceled, cause)
+	}
+	return fmt.Errorf("%w: %w: %w", ErrTxCanceled, cause, err)
+}
 
+// txDone reports how a transaction ended to the hook, if any.
+func (db *DB) txDone(ctx context.Context, outcome TxOutcome, err error) {
+	if db.txHook != nil {
+		db.txHook(ctx, outcome, err)
+	}
+}
+
+// WithTx returns a context with tx adopted as db's transaction, so sqlp helpers (including RunInTx)
+// participate in a transaction started elsewhere, eg. by another library or database/sql directly.
+// Committing or rolling back tx is left to whoever started it.
+func (db *DB) WithTx(ctx context.Context, tx *sql.Tx) context.Context {
+	return context.WithValue(ctx, txKey{db.DB}, &txState{tx: tx})
+}
+
+// RunInSavepoint runs fn in a savepoint of the context's transaction, rolling back to the savepoint
+// if fn errors, so the rest of the transaction can carry on. It errors if the context has no
+// transaction, see RunInTx. Callbacks declared read-only may run without a savepoint, see
+// WithSkipReadOnlySavepoints.
+func (db *DB) RunInSavepoint(ctx context.Context, fn func(context.Context) error) error {
+	if db.txContext(ctx) == nil {
+		return errors.New("sqlp: RunInSavepoint needs a transaction, see RunInTx")
+	}
+	if db.skipSavepoint(ctx) {
+		return fn(ctx)
+	}
+	name := fmt.Sprintf("sqlp_%d", db.savepoints.Add(1))
+	if _, err := db.Exec(ctx, "SAVEPOINT "+name); err != nil {
+		return err
+	}
 	if err := fn(ctx); err != nil {
+		if _, rbErr := db.Exec(ctx, "ROLLBACK TO SAVEPOINT "+name); rbErr != nil {
+			return errors.Join(err, fmt.Errorf("%w: to savepoint: %w", ErrRollbackFailed, rbErr))
+		}
 		return err
 	}
+	_, err := db.Exec(ctx, "RELEASE SAVEPOINT "+name)
+	return err
+}
 
-	return tx.Commit()
+// Tx is an explicit transaction, started by Begin.
+// Note, unlike RunInTx, sqlp helpers don't use it unless given it -- use Tx's methods directly.
+type Tx struct {
+	*sql.Tx
+	release func()
 }
 
-// queryer returns the proper queryer for context, whether a Tx or normal DB.
+// Begin starts an explicit transaction, for when RunInTx's callback style doesn't fit.
+// As with sql.DB.BeginTx, the transaction is rolled back if ctx is done before it's committed.
+func (db *DB) Begin(ctx context.Context, opts *sql.TxOptions) (*Tx, error) {
+	tx, err := db.DB.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &Tx{Tx: tx, release: db.trackTx(ctx, true)}, nil
+}
+
+// Commit commits the transaction, wrapping any failure in ErrCommitFailed.
+func (tx *Tx) Commit() error {
+	tx.release()
+	if err := tx.Tx.Commit(); err != nil {
+		return fmt.Errorf("%w: %w", ErrCommitFailed, err)
+	}
+	return nil
+}
+
+// Rollback aborts the transaction.
+func (tx *Tx) Rollback() error {
+	tx.release()
+	return tx.Tx.Rollback()
+}
+
+// trackTx starts tracking a transaction for leak detection, returning a func to call once it's
+// committed or rolled back.
+// Explicit transactions are also reported if ctx is done before then.
+func (db *DB) trackTx(ctx context.Context, explicit bool) func() {
+	if db.txLeakThreshold <= 0 {
+		return func() {}
+	}
+	pcs := make([]uintptr, 16)
+	pcs = pcs[:runtime.Callers(3, pcs)] // skip Callers, trackTx, and RunInTx/Begin
+	var released atomic.Bool
+	timer := time.AfterFunc(db.txLeakThreshold, func() {
+		db.logf(
+			"sqlp: transaction open longer than %v, began from:\n%s",
+			db.txLeakThreshold, formatCallers(pcs),
+		)
+	})
+	stop := func() bool { return false }
+	if explicit {
+		stop = context.AfterFunc(ctx, func() {
+			if !released.Load() {
+				db.logf("sqlp: context done with unreleased transaction, began from:\n%s", formatCallers(pcs))
+			}
+		})
+	}
+	return func() {
+		released.Store(true)
+		timer.Stop()
+		stop()
+	}
+}
+
+// queryer returns the proper queryer for context, whether a Tx, pinned Conn or normal DB.
 func (db *DB) queryer(ctx context.Context) Queryer {
 	if tx := db.txContext(ctx); tx != nil {
 		return tx
 	}
+	if conn := db.pinnedConn(ctx); conn != nil {
+		return conn
+	}
 	return db.DB
 }
 
+// useTx marks the context's transaction as busy until release is called, erroring if it already is,
+// or if the rows of an earlier query on it are still open (eg. being iterated on another goroutine).
+// A transaction is a single connection, so statements on it from several goroutines at once are a
+// data race in the driver rather than a concurrent fan out.
+func (db *DB) useTx(ctx context.Context) (release func(), err error) {
+	state := db.txState(ctx)
+	if state == nil {
+		return func() {}, nil
+	}
+	if !state.busy.CompareAndSwap(false, true) {
+		return nil, fmt.Errorf("%w: another statement is running on it, run concurrent queries with Parallel outside of the transaction instead", ErrConcurrentTx)
+	}
+	if state.openRows() {
+		state.busy.Store(false)
+		return nil, fmt.Errorf("%w: the rows of another query are still open on it, close them first or run concurrent queries with Parallel outside of the transaction instead", ErrConcurrentTx)
+	}
+	return func() { state.busy.Store(false) }, nil
+}
+
+// openRows returns whether any rows of queries on the transaction are still open, forgetting the
+// closed ones (including fully iterated ones).
+func (s *txState) openRows() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rows = slices.DeleteFunc(s.rows, func(rows *sql.Rows) bool {
+		_, err := rows.Columns() // Closed rows error here, see trackRows
+		return err != nil
+	})
+	return len(s.rows) > 0
+}
+
 // txContext returns contexts current transaction if any.
 func (db *DB) txContext(ctx context.Context) *sql.Tx {
-	if tx := ctx.Value(ctxKey); tx != nil {
-		return tx.(*sql.Tx)
+	if state := db.txState(ctx); state != nil {
+		return state.tx
 	}
 	return nil
 }
 
+// txState returns the state of the context's transaction for db, if any.
+func (db *DB) txState(ctx context.Context) *txState {
+	state, _ := ctx.Value(txKey{db.DB}).(*txState)
+	return state
+}
+
+// TxFromContext returns the transaction of db in context (as started by RunInTx), if any, for
+// advanced callers that need the *sql.Tx itself.
+func TxFromContext(ctx context.Context, db *DB) (*sql.Tx, bool) {
+	tx := db.txContext(ctx)
+	return tx, tx != nil
+}
+
 ////////////////////////////////////////////////////////////////////////////////
 // Reflective APIs
 
@@ -130,8 +596,50 @@ func Select[E any](ctx context.Context, db *DB, query string, args ...any) ([]E,
 	return entities, nil
 }
 
+// SelectFunc runs a query and calls fn with each scanned entity as it's read, stopping at the first
+// error fn returns. Useful to stream large results (eg. to a response writer) without buffering
+// them, or handling rows and scanners directly.
+func SelectFunc[E any](ctx context.Context, db *DB, fn func(e E) error, query string, args ...any) error {
+	ctx, cancel := db.timeoutContext(ctx)
+	defer cancel()
+	rows, err := db.Query(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	scanner, err := newReflectScanner[E](rows, ignoredColumns(ctx), db.normalization)
+	if err != nil {
+		return fmt.Errorf("failed to get reflect scanner: %w", err)
+	}
+	return scanEach(ctx, db, rows, scanner, fn)
+}
+
+// scanEach scans each of rows and calls fn with it, see SelectFunc.
+func scanEach[E any](ctx context.Context, db *DB, rows *sql.Rows, scanner *ReflectScanner[E], fn func(e E) error) error {
+	var n int64
+	for rows.Next() {
+		n++
+		val, err := scanner.Scan()
+		if err != nil {
+			return fmt.Errorf("failed to scan row: %w", err)
+		}
+		if err := fn(val); err != nil {
+			return err
+		}
+		if err := checkSelectContext(ctx, n); err != nil {
+			return err
+		}
+	}
+	db.captureScanned(ctx, rows, n)
+
+	return rows.Err()
+}
+
 // Get runs a query and scans the single row result into dest, using reflection to scan.
 func (db *DB) Get(ctx context.Context, dest any, query string, args ...any) error {
+	ctx, cancel := db.timeoutContext(ctx)
+	defer cancel()
 	rows, err := db.Query(ctx, query, args...)
 	if err != nil {
 		return err
@@ -139,17 +647,35 @@ func (db *DB) Get(ctx context.Context, dest any, query string, args ...any) erro
 	defer rows.Close()
 
 	scanner := NewReflectDestScanner(rows)
+	scanner.ignore = ignoredColumns(ctx)
+	scanner.normalization = db.normalization
 
 	if rows.Next() {
 		err := scanner.Scan(dest)
 		if err != nil {
 			return err
 		}
+		db.captureScanned(ctx, rows, 1)
 	}
 
 	return rows.Err()
 }
 
+// selectCheckRows is how many rows select loops scan between checks of their context, so canceled
+// requests stop processing large results even while the driver keeps delivering buffered rows.
+var selectCheckRows int64 = 1000
+
+// checkSelectContext returns the context's error if it's time to check it after n rows.
+func checkSelectContext(ctx context.Context, n int64) error {
+	if n%selectCheckRows != 0 {
+		return nil
+	}
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("select aborted after %d rows: %w", n, err)
+	}
+	return nil
+}
+
 // Select runs a query and scans the results into dest, using reflection to scan.
 func (db *DB) Select(ctx context.Context, dest any, query string, args ...any) error {
 	// Validate destination types, we want a pointer to a slice of structs (or pointers to structs).
@@ -166,6 +692,8 @@ func (db *DB) Select(ctx context.Context, dest any, query string, args ...any) e
 	destV := reflect.ValueOf(dest).Elem()
 
 	// Run the query
+	ctx, cancel := db.timeoutContext(ctx)
+	defer cancel()
 	rows, err := db.Query(ctx, query, args...)
 	if err != nil {
 		return err
@@ -173,15 +701,30 @@ func (db *DB) Select(ctx context.Context, dest any, query string, args ...any) e
 	defer rows.Close()
 
 	scanner := NewReflectDestScanner(rows)
+	scanner.ignore = ignoredColumns(ctx)
+	scanner.normalization = db.normalization
 
+	limit := db.rowLimit(ctx)
+	var n int64
 	for rows.Next() {
+		if limit.exceeded(n) {
+			if limit.truncate {
+				break
+			}
+			return limit.err()
+		}
+		n++
 		val := reflect.New(elemType)
 		err := scanner.Scan(val.Interface())
 		if err != nil {
 			return fmt.Errorf("failed to scan row: %w", err)
 		}
 		destV.Set(reflect.Append(destV, val.Elem()))
+		if err := checkSelectContext(ctx, n); err != nil {
+			return err
+		}
 	}
+	db.captureScanned(ctx, rows, n)
 
 	return rows.Err()
 }
@@ -2,9 +2,11 @@ package sqlp
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"math"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -32,6 +34,96 @@ func TestDB_Exec(t *testing.T) {
 	}
 }
 
+func TestDB_MustExec(t *testing.T) {
+	db, ctx, cleanup := testDB(t)
+	defer cleanup()
+
+	res := db.MustExec(ctx, "INSERT INTO people (first_name, last_name) VALUES (?, ?)", "John", "Doe")
+	if n, _ := res.RowsAffected(); n != 1 {
+		t.Errorf("rows affected %d, expected 1", n)
+	}
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("expected panic on bad query")
+		}
+	}()
+	db.MustExec(ctx, "INSERT INTO nonexistent (first_name) VALUES (?)", "John")
+}
+
+func TestDB_ExecRows(t *testing.T) {
+	db, ctx, cleanup := testDB(t)
+	defer cleanup()
+	grandchildrenSetup(ctx, db)
+
+	n, err := db.ExecRows(ctx, "UPDATE people SET last_name = ? WHERE last_name = ?", "Smith", "Doe")
+	errcmp.MustMatch(t, err, "")
+	if n != 3 {
+		t.Errorf("rows affected %d, expected 3", n)
+	}
+
+	_, err = db.ExecRows(ctx, "UPDATE nonexistent SET last_name = ?", "Smith")
+	errcmp.MustMatch(t, err, "no such table")
+}
+
+func TestDB_ExecID(t *testing.T) {
+	db, ctx, cleanup := testDB(t)
+	defer cleanup()
+
+	id, err := db.ExecID(ctx, "INSERT INTO people (first_name, last_name) VALUES (?, ?)", "John", "Doe")
+	errcmp.MustMatch(t, err, "")
+	id2, err := db.ExecID(ctx, "INSERT INTO people (first_name, last_name) VALUES (?, ?)", "Jane", "Doe")
+	errcmp.MustMatch(t, err, "")
+	if id == 0 || id2 != id+1 {
+		t.Errorf("got ids %d and %d, expected sequential ids", id, id2)
+	}
+}
+
+func TestDB_ExecReturning(t *testing.T) {
+	db, ctx, cleanup := testDB(t)
+	defer cleanup()
+
+	t.Run("into struct", func(t *testing.T) {
+		p := person{}
+		err := db.ExecReturning(
+			ctx, &p, "INSERT INTO people (first_name, last_name) VALUES (?, ?) RETURNING id, first_name, created_at",
+			"John", "Doe",
+		)
+		errcmp.MustMatch(t, err, "")
+		if p.ID == 0 || p.FirstName != "John" || p.CreatedAt.IsZero() {
+			t.Errorf("expected returned columns scanned, got %+v", p)
+		}
+	})
+
+	t.Run("into slice", func(t *testing.T) {
+		var people []person
+		err := db.ExecReturning(ctx, &people, "UPDATE people SET last_name = ? RETURNING id, last_name", "Smith")
+		errcmp.MustMatch(t, err, "")
+		if len(people) != 1 || people[0].LastName != "Smith" {
+			t.Errorf("expected updated rows scanned, got %+v", people)
+		}
+	})
+
+	t.Run("dry run -> skipped", func(t *testing.T) {
+		db := NewDB(db.DB, WithLogger(&testLogger{}))
+		p := person{}
+		err := db.ExecReturning(DryRun(ctx), &p, "DELETE FROM people RETURNING id")
+		errcmp.MustMatch(t, err, "")
+		if p.ID != 0 {
+			t.Errorf("expected dest untouched, got %+v", p)
+		}
+		count, err := GetScalar[int](ctx, db, "SELECT COUNT(*) FROM people")
+		if err != nil || count != 1 {
+			t.Errorf("expected dry run to not delete, got %v %v", count, err)
+		}
+	})
+
+	t.Run("bad statement -> err", func(t *testing.T) {
+		err := db.ExecReturning(ctx, &person{}, "UPDATE nonexistent SET last_name = ? RETURNING id", "Smith")
+		errcmp.MustMatch(t, err, "no such table")
+	})
+}
+
 func TestDB_Query(t *testing.T) {
 	db, ctx, cleanup := testDB(t)
 	defer cleanup()
@@ -65,6 +157,59 @@ func TestDB_QueryRow(t *testing.T) {
 	}
 }
 
+func TestDB_Prepare(t *testing.T) {
+	db, ctx, cleanup := testDB(t)
+	defer cleanup()
+
+	err := db.RunInTx(ctx, func(ctx context.Context) error {
+		stmt, err := db.Prepare(ctx, "INSERT INTO people (first_name, last_name) VALUES (?, ?)")
+		if err != nil {
+			return err
+		}
+		defer stmt.Close()
+		for _, name := range []string{"John", "Jane"} {
+			if _, err := stmt.ExecContext(ctx, name, "Doe"); err != nil {
+				return err
+			}
+		}
+		return fmt.Errorf("rollback")
+	})
+	errcmp.MustMatch(t, err, "rollback")
+
+	stmt, err := db.Prepare(ctx, "SELECT COUNT(*) FROM people")
+	errcmp.MustMatch(t, err, "")
+	defer stmt.Close()
+	var count int
+	if err := stmt.QueryRowContext(ctx).Scan(&count); err != nil || count != 0 {
+		t.Errorf("expected transaction's prepared inserts rolled back, got %d, %v", count, err)
+	}
+}
+
+func TestDB_Raw(t *testing.T) {
+	db, ctx, cleanup := testDB(t)
+	defer cleanup()
+
+	rawConn := func(ctx context.Context) (conn any) {
+		t.Helper()
+		err := db.Raw(ctx, func(driverConn any) error {
+			conn = driverConn
+			return nil
+		})
+		errcmp.MustMatch(t, err, "")
+		return conn
+	}
+	if conn := rawConn(ctx); fmt.Sprintf("%T", conn) != "*sqlite3.SQLiteConn" {
+		t.Errorf("expected sqlite conn, got %T", conn)
+	}
+	err := db.RunInTx(ctx, func(ctx context.Context) error {
+		if rawConn(ctx) != rawConn(ctx) {
+			t.Errorf("expected transaction's conn each time")
+		}
+		return db.Raw(ctx, func(driverConn any) error { return fmt.Errorf("boom") })
+	})
+	errcmp.MustMatch(t, err, "boom")
+}
+
 func TestDB_Select(t *testing.T) {
 	db, ctx, cleanup := testDB(t)
 	defer cleanup()
@@ -223,6 +368,49 @@ func TestDB_Get(t *testing.T) {
 	})
 }
 
+func TestSelectFunc(t *testing.T) {
+	db, ctx, cleanup := testDB(t)
+	defer cleanup()
+
+	grandparent := grandchildrenSetup(ctx, db)
+
+	t.Run("calls back each row", func(t *testing.T) {
+		var people []person
+		err := SelectFunc(ctx, db, func(p person) error {
+			people = append(people, p)
+			return nil
+		}, "SELECT id, first_name FROM people WHERE last_name = ?", "Doe")
+		if err != nil {
+			t.Fatalf("failed to select: %v", err)
+		}
+		expected := []person{
+			{ID: grandparent.ID, FirstName: "John"},
+			{ID: grandparent.Child.ID, FirstName: "Lil Johnnie"},
+			{ID: grandparent.Child.Child.ID, FirstName: "Lil Lil Johnnie"},
+		}
+		if !cmp.Equal(people, expected, personComparer) {
+			t.Errorf("selected people unexpected:\n%v", cmp.Diff(expected, people, personComparer))
+		}
+	})
+
+	t.Run("callback error -> stops", func(t *testing.T) {
+		var calls int
+		err := SelectFunc(ctx, db, func(p person) error {
+			calls++
+			return fmt.Errorf("stop at %v", p.FirstName)
+		}, "SELECT id, first_name FROM people")
+		errcmp.MustMatch(t, err, "stop at John")
+		if calls != 1 {
+			t.Errorf("expected 1 call, got %v", calls)
+		}
+	})
+
+	t.Run("to person pointer -> err", func(t *testing.T) {
+		err := SelectFunc(ctx, db, func(p *person) error { return nil }, "SELECT id FROM people")
+		errcmp.MustMatch(t, err, "failed to get reflect scanner")
+	})
+}
+
 func TestDB_RunInTx(t *testing.T) {
 	db, ctx, cleanup := testPG(t)
 	defer cleanup()
@@ -299,6 +487,267 @@ func TestDB_RunInTx(t *testing.T) {
 	})
 }
 
+func TestDB_ConcurrentTx(t *testing.T) {
+	db, ctx, cleanup := testDB(t)
+	defer cleanup()
+	grandchildrenSetup(ctx, db)
+
+	err := db.RunInTx(ctx, func(ctx context.Context) error {
+		// Fan out Selects on the transaction, with the first still iterating while the second runs
+		iterating, done := make(chan struct{}), make(chan struct{})
+		errs := make(chan error, 2)
+		go func() {
+			first := true
+			errs <- SelectFunc(ctx, db, func(p person) error {
+				if first {
+					first = false
+					close(iterating)
+					<-done
+				}
+				return nil
+			}, "SELECT * FROM people")
+		}()
+		go func() {
+			<-iterating
+			defer close(done)
+			var people []person
+			err := db.Select(ctx, &people, "SELECT * FROM people")
+			if err == nil {
+				var n int
+				err = db.QueryRow(ctx, "SELECT COUNT(*) FROM people").Scan(&n)
+			}
+			errs <- err
+		}()
+		var concurrent error
+		for range 2 {
+			if err := <-errs; err != nil {
+				concurrent = errors.Join(concurrent, err)
+			}
+		}
+		errcmp.MustMatch(t, concurrent, "concurrent use of transaction: the rows of another query are still open")
+		if !errors.Is(concurrent, ErrConcurrentTx) {
+			t.Errorf("expected ErrConcurrentTx, got %v", concurrent)
+		}
+
+		// Once the rows are closed, the transaction is free again
+		var n int
+		if err := db.QueryRow(ctx, "SELECT COUNT(*) FROM people").Scan(&n); err != nil || n != 3 {
+			t.Errorf("expected 3 people, got %v, %v", n, err)
+		}
+		_, err := db.Exec(ctx, "DELETE FROM people")
+		return err
+	})
+	if err != nil {
+		t.Errorf("failed to run in tx: %v", err)
+	}
+
+	t.Run("query row", func(t *testing.T) {
+		err := db.RunInTx(ctx, func(ctx context.Context) error {
+			rows, err := db.Query(ctx, "SELECT * FROM people")
+			if err != nil {
+				return err
+			}
+			defer rows.Close()
+			var n int
+			err = db.QueryRow(ctx, "SELECT COUNT(*) FROM people").Scan(&n)
+			if !errors.Is(err, ErrConcurrentTx) {
+				t.Errorf("expected ErrConcurrentTx, got %v", err)
+			}
+			return nil
+		})
+		errcmp.MustMatch(t, err, "")
+	})
+}
+
+func TestDB_WithTx(t *testing.T) {
+	db, ctx, cleanup := testDB(t)
+	defer cleanup()
+	count := func() (n int) {
+		t.Helper()
+		if err := db.QueryRow(ctx, "SELECT COUNT(*) FROM people").Scan(&n); err != nil {
+			t.Fatalf("failed to count: %v", err)
+		}
+		return n
+	}
+	insert := "INSERT INTO people (first_name, last_name) VALUES (?, ?)"
+
+	tx, err := db.DB.BeginTx(ctx, nil)
+	errcmp.MustMatch(t, err, "")
+	txCtx := db.WithTx(ctx, tx)
+	if adopted, _ := TxFromContext(txCtx, db); adopted != tx {
+		t.Errorf("expected adopted transaction")
+	}
+	db.MustExec(txCtx, insert, "John", "Doe")
+	err = db.RunInTx(txCtx, func(ctx context.Context) error {
+		_, err := db.Exec(ctx, insert, "Jane", "Doe")
+		return err
+	})
+	errcmp.MustMatch(t, err, "")
+	errcmp.MustMatch(t, db.Raw(txCtx, func(any) error { return nil }), "unavailable in transactions adopted with WithTx")
+
+	// Left to the owner to commit, which rolls back instead
+	errcmp.MustMatch(t, tx.Rollback(), "")
+	if n := count(); n != 0 {
+		t.Errorf("expected adopted transaction rolled back, got %d people", n)
+	}
+
+	t.Run("nested RunInTx joins the outer transaction", func(t *testing.T) {
+		err := db.RunInTx(ctx, func(ctx context.Context) error {
+			err := db.RunInTx(ctx, func(ctx context.Context) error {
+				_, err := db.Exec(ctx, insert, "John", "Doe")
+				return err
+			})
+			errcmp.MustMatch(t, err, "")
+			return fmt.Errorf("rollback")
+		})
+		errcmp.MustMatch(t, err, "rollback")
+		if n := count(); n != 0 {
+			t.Errorf("expected nested insert rolled back with outer, got %d people", n)
+		}
+	})
+}
+
+func TestTxFromContext(t *testing.T) {
+	db, ctx, cleanup := testDB(t)
+	defer cleanup()
+	other, err := Open("sqlite3", filepath.Join(t.TempDir(), "other.db"))
+	errcmp.MustMatch(t, err, "")
+	defer other.Close()
+	other.MustExec(ctx, "CREATE TABLE people (id INTEGER PRIMARY KEY, first_name TEXT)")
+
+	if _, ok := TxFromContext(ctx, db); ok {
+		t.Errorf("expected no transaction outside RunInTx")
+	}
+	err = db.RunInTx(ctx, func(ctx context.Context) error {
+		tx, ok := TxFromContext(ctx, db)
+		if !ok || tx == nil {
+			t.Errorf("expected transaction for db")
+		}
+		if shared, _ := TxFromContext(ctx, NewDB(db.DB)); shared != tx {
+			t.Errorf("expected DBs sharing a pool to share the transaction")
+		}
+		if _, ok := TxFromContext(ctx, other); ok {
+			t.Errorf("expected no transaction for another database")
+		}
+		// Runs outside db's transaction, so is kept despite the rollback
+		other.MustExec(ctx, "INSERT INTO people (first_name) VALUES (?)", "John")
+		return fmt.Errorf("rollback")
+	})
+	errcmp.MustMatch(t, err, "rollback")
+
+	var count int
+	if err := other.QueryRow(ctx, "SELECT COUNT(*) FROM people").Scan(&count); err != nil || count != 1 {
+		t.Errorf("expected other database's insert kept, got %d, %v", count, err)
+	}
+}
+
+func TestDB_RunInSavepoint(t *testing.T) {
+	db, ctx, cleanup := testDB(t)
+	defer cleanup()
+
+	insert := func(ctx context.Context, name string) {
+		db.MustExec(ctx, "INSERT INTO people (first_name, last_name) VALUES (?, ?)", name, "Doe")
+	}
+	err := db.RunInTx(ctx, func(ctx context.Context) error {
+		insert(ctx, "John")
+		err := db.RunInSavepoint(ctx, func(ctx context.Context) error {
+			insert(ctx, "Jane")
+			err := db.RunInSavepoint(ctx, func(ctx context.Context) error {
+				insert(ctx, "Jim")
+				return fmt.Errorf("inner error")
+			})
+			errcmp.MustMatch(t, err, "inner error")
+			return nil
+		})
+		errcmp.MustMatch(t, err, "")
+		err = db.RunInSavepoint(ctx, func(ctx context.Context) error {
+			insert(ctx, "Jill")
+			return fmt.Errorf("outer error")
+		})
+		errcmp.MustMatch(t, err, "outer error")
+		insert(ctx, "Albert")
+		return nil
+	})
+	errcmp.MustMatch(t, err, "")
+
+	var people []person
+	err = db.Select(ctx, &people, "SELECT first_name FROM people ORDER BY id")
+	errcmp.MustMatch(t, err, "")
+	names := []string{}
+	for _, p := range people {
+		names = append(names, p.FirstName)
+	}
+	if expected := []string{"John", "Jane", "Albert"}; !cmp.Equal(names, expected) {
+		t.Errorf("unexpected people:\n%v", cmp.Diff(expected, names))
+	}
+
+	err = db.RunInSavepoint(ctx, func(ctx context.Context) error { return nil })
+	errcmp.MustMatch(t, err, "RunInSavepoint needs a transaction")
+}
+
+func TestDB_RunInTx_FailureErrors(t *testing.T) {
+	db, ctx, cleanup := testDB(t)
+	defer cleanup()
+
+	t.Run("commit failed", func(t *testing.T) {
+		// Deferred foreign keys are only checked on commit
+		conn, err := db.Conn(ctx)
+		errcmp.MustMatch(t, err, "")
+		defer conn.Close()
+		conn.MustExec(ctx, `
+			PRAGMA foreign_keys = ON;
+			DROP TABLE IF EXISTS owners;
+			CREATE TABLE owners (
+				id INTEGER PRIMARY KEY,
+				person_id INTEGER REFERENCES people(id) DEFERRABLE INITIALLY DEFERRED
+			);
+		`)
+		defer conn.MustExec(ctx, "DROP TABLE owners; PRAGMA foreign_keys = OFF")
+
+		err = conn.RunInTx(ctx, func(ctx context.Context) error {
+			_, err := db.Exec(ctx, "INSERT INTO owners (person_id) VALUES (?)", -1)
+			return err
+		})
+		errcmp.MustMatch(t, err, "commit failed: FOREIGN KEY constraint failed")
+		if !errors.Is(err, ErrCommitFailed) {
+			t.Errorf("expected ErrCommitFailed, got %v", err)
+		}
+	})
+
+	t.Run("callback error isn't a commit failure", func(t *testing.T) {
+		err := db.RunInTx(ctx, func(ctx context.Context) error { return fmt.Errorf("business error") })
+		if errors.Is(err, ErrCommitFailed) || errors.Is(err, ErrRollbackFailed) {
+			t.Errorf("expected only the callback's error, got %v", err)
+		}
+	})
+
+	t.Run("rollback failed", func(t *testing.T) {
+		businessErr := fmt.Errorf("business error")
+		err := db.RunInTx(ctx, func(ctx context.Context) error {
+			db.MustExec(ctx, "COMMIT") // ends the transaction behind database/sql's back
+			return businessErr
+		})
+		errcmp.MustMatch(t, err, "rollback failed")
+		if !errors.Is(err, ErrRollbackFailed) || !errors.Is(err, businessErr) {
+			t.Errorf("expected rollback failure joined to callback's error, got %v", err)
+		}
+	})
+
+	t.Run("rollback to savepoint failed", func(t *testing.T) {
+		businessErr := fmt.Errorf("business error")
+		err := db.RunInTx(ctx, func(ctx context.Context) error {
+			return db.RunInSavepoint(ctx, func(ctx context.Context) error {
+				db.MustExec(ctx, fmt.Sprintf("RELEASE SAVEPOINT sqlp_%d", db.savepoints.Load()))
+				return businessErr
+			})
+		})
+		errcmp.MustMatch(t, err, "rollback failed: to savepoint")
+		if !errors.Is(err, ErrRollbackFailed) || !errors.Is(err, businessErr) {
+			t.Errorf("expected rollback failure joined to callback's error, got %v", err)
+		}
+	})
+}
+
 // BenchmarkDB_Methods benchmarks the various scanning methods.
 // Obviously because this is hitting a db, YMMV, but it's a good sanity check that you're not
 // doing something horrible, and can check allocs (eg. custom mapping is almost as good as vanilla,
@@ -0,0 +1,77 @@
+package sqlp
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/greghart/powerputtygo/queryp"
+)
+
+// DeleteWhere deletes the rows matching where, returning the number of rows deleted.
+// A nil where is refused with ErrDeleteAllRefused, see DeleteAll to delete everything.
+//
+//	n, err := repository.DeleteWhere(ctx, queryp.Lt("updated_at", cutoff))
+func (r *Repository[E]) DeleteWhere(ctx context.Context, where queryp.Fragment) (int64, error) {
+	if err := r.checkDeleteWhere("DeleteWhere", where); err != nil {
+		return 0, err
+	}
+	q, args := r.deleteWhere(where)
+	return r.ExecRows(ctx, q, args...)
+}
+
+// DeleteWhereReturning deletes the rows matching where, returning the deleted entities, eg. so
+// cleanup jobs can log exactly what they removed.
+// Postgres and SQLite use `DELETE ... RETURNING *`, while MySQL (which has no RETURNING) selects
+// the rows `FOR UPDATE` before deleting them in the same transaction. In a dry run, the rows that
+// would be deleted are returned. The rows are returned in full, regardless of WithMaxRows or
+// checksums, as they're deleted all the same.
+func (r *Repository[E]) DeleteWhereReturning(ctx context.Context, where queryp.Fragment) ([]E, error) {
+	if err := r.checkDeleteWhere("DeleteWhereReturning", where); err != nil {
+		return nil, err
+	}
+	d := r.Dialect()
+	if (d == queryp.Postgres || d == queryp.Sqlite) && !r.isDryRun(ctx) {
+		q, args := r.deleteWhere(where)
+		return r.selectEntities(ctx, false, q+" RETURNING *", args...)
+	}
+	if d == "" {
+		return nil, r.errUnknownDialect("DeleteWhereReturning")
+	}
+
+	var deleted []E
+	err := r.RunInTx(ctx, func(ctx context.Context) error {
+		selectArgs := queryp.NewArgs().WithPlaceholderer(d.Placeholderer())
+		q := "SELECT * FROM " + r.QualifiedTable() + " WHERE " + r.scope(where)(selectArgs)
+		if d != queryp.Sqlite {
+			q += " FOR UPDATE"
+		}
+		var err error
+		if deleted, err = r.selectEntities(ctx, false, q, selectArgs.Args()...); err != nil {
+			return err
+		}
+		q, args := r.deleteWhere(where)
+		_, err = r.Exec(ctx, q, args...)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return deleted, nil
+}
+
+// deleteWhere renders the DELETE statement for where.
+func (r *Repository[E]) deleteWhere(where queryp.Fragment) (string, []any) {
+	args := queryp.NewArgs().WithPlaceholderer(r.Dialect().Placeholderer())
+	return "DELETE FROM " + r.QualifiedTable() + " WHERE " + r.scope(where)(args), args.Args()
+}
+
+// checkDeleteWhere checks deleting with where is allowed.
+func (r *Repository[E]) checkDeleteWhere(helper string, where queryp.Fragment) error {
+	if err := r.checkWritable(helper); err != nil {
+		return err
+	}
+	if where == nil {
+		return fmt.Errorf("%w: %s of %s without criteria, see DeleteAll", ErrDeleteAllRefused, helper, r.table)
+	}
+	return nil
+}
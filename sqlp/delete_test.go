@@ -0,0 +1,92 @@
+package sqlp
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/greghart/powerputtygo/errcmp"
+	"github.com/greghart/powerputtygo/queryp"
+)
+
+func TestRepository_DeleteWhere(t *testing.T) {
+	db, ctx, cleanup := testDB(t)
+	defer cleanup()
+	repository := NewRepository[person](db, "people")
+	grandparent := grandchildrenSetup(ctx, db)
+	albert := albertSetup(ctx, db)
+
+	n, err := repository.DeleteWhere(ctx, queryp.Eq("first_name", "Albert"))
+	if err != nil || n != 1 {
+		t.Errorf("expected 1 deleted, got %d, %v", n, err)
+	}
+	if p := must(repository.Find(ctx, int(albert.ID))); p != nil {
+		t.Errorf("expected albert deleted, got %v", p)
+	}
+	if p := must(repository.Find(ctx, int(grandparent.ID))); p == nil {
+		t.Errorf("expected others to remain")
+	}
+
+	_, err = repository.DeleteWhere(ctx, nil)
+	errcmp.MustMatch(t, err, "refusing to delete everything: DeleteWhere of people without criteria")
+	_, err = repository.ReadOnly().DeleteWhere(ctx, queryp.Eq("id", 1))
+	errcmp.MustMatch(t, err, "read-only: DeleteWhere of people")
+}
+
+func TestRepository_DeleteWhereReturning(t *testing.T) {
+	tests := map[string]struct {
+		opts []Option
+		ctx  func(context.Context) context.Context
+		left int    // people left after
+		err  string // expected error, if any
+	}{
+		"returning": {left: 1},
+		"pre-select": {
+			// Can't run MySQL here, but can check its locking pre-select is what's run
+			opts: []Option{WithDialect(queryp.MySQL)},
+			err:  "near \"FOR\"",
+		},
+		"dry run": {
+			ctx:  DryRun,
+			left: 3,
+		},
+		"returning past max rows": {
+			opts: []Option{WithMaxRows(1)},
+			left: 1,
+		},
+		"pre-select past max rows": {
+			opts: []Option{WithMaxRows(1, TruncateRows)},
+			ctx:  DryRun,
+			left: 3,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			db, ctx, cleanup := testDB(t)
+			defer cleanup()
+			grandchildrenSetup(ctx, db)
+			if test.ctx != nil {
+				ctx = test.ctx(ctx)
+			}
+			repository := NewRepository[person](NewDB(db.DB, append(test.opts, WithLogger(&testLogger{}))...), "people")
+
+			deleted, err := repository.DeleteWhereReturning(ctx, queryp.Ne("first_name", "John"))
+			if errcmp.MustMatch(t, err, test.err); err != nil {
+				return
+			}
+			names := []string{}
+			for _, p := range deleted {
+				names = append(names, p.FirstName)
+			}
+			slices.Sort(names) // RETURNING order is arbitrary
+			if expected := []string{"Lil Johnnie", "Lil Lil Johnnie"}; !cmp.Equal(names, expected) {
+				t.Errorf("unexpected deleted people:\n%v", cmp.Diff(expected, names))
+			}
+			var left int
+			if err := db.QueryRow(ctx, "SELECT COUNT(*) FROM people").Scan(&left); err != nil || left != test.left {
+				t.Errorf("expected %d people left, got %d, %v", test.left, left, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package sqlp
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/greghart/powerputtygo/queryp"
+)
+
+// Dialect returns the SQL dialect of the database, for the few helpers that generate SQL that
+// differs per database. It's detected from the driver (sqlite3, pq/pgx, mysql) unless set with
+// WithDialect, and empty if unknown.
+func (db *DB) Dialect() queryp.Dialect {
+	if db.dialect != "" {
+		return db.dialect
+	}
+	driver := reflect.TypeOf(db.Driver()).String() // eg. "*sqlite3.SQLiteDriver"
+	switch {
+	case strings.Contains(driver, "sqlite"):
+		return queryp.Sqlite
+	case strings.HasPrefix(driver, "*pq."), strings.Contains(driver, "pgx"), strings.HasPrefix(driver, "*stdlib."):
+		return queryp.Postgres
+	case strings.Contains(driver, "mysql"):
+		return queryp.MySQL
+	}
+	return ""
+}
+
+// errUnknownDialect returns an error for a helper that needs a dialect, when it's unknown.
+func (db *DB) errUnknownDialect(helper string) error {
+	return fmt.Errorf("sqlp: %s needs a dialect, and driver %T is unknown (see WithDialect)", helper, db.Driver())
+}
+
+// Rebind rewrites a query's `?` placeholders into the database's dialect, eg. `$1` for Postgres,
+// so queries written once with `?` run on any of them. See queryp.Rebind.
+func (db *DB) Rebind(query string) string {
+	return queryp.Rebind(db.Dialect().Placeholderer(), query)
+}
@@ -0,0 +1,40 @@
+package sqlp
+
+import (
+	"testing"
+
+	"github.com/greghart/powerputtygo/queryp"
+)
+
+func TestDB_Dialect(t *testing.T) {
+	db, _, cleanup := testDB(t)
+	defer cleanup()
+
+	if d := db.Dialect(); d != queryp.Sqlite {
+		t.Errorf("expected sqlite dialect detected, got %q", d)
+	}
+	if d := NewDB(db.DB, WithDialect(queryp.MySQL)).Dialect(); d != queryp.MySQL {
+		t.Errorf("expected configured dialect, got %q", d)
+	}
+}
+
+func TestDB_Rebind(t *testing.T) {
+	db, ctx, cleanup := testDB(t)
+	defer cleanup()
+	grandchildrenSetup(ctx, db)
+
+	const query = "SELECT COUNT(*) FROM people WHERE last_name = ? AND first_name <> ?"
+	if q := db.Rebind(query); q != query {
+		t.Errorf("expected sqlite query as is, got %q", q)
+	}
+	count, err := GetScalar[int](ctx, db, db.Rebind(query), "Doe", "John")
+	if err != nil || count != 2 {
+		t.Errorf("expected 2 people, got %d, %v", count, err)
+	}
+
+	pg := NewDB(db.DB, WithDialect(queryp.Postgres))
+	expected := "SELECT COUNT(*) FROM people WHERE last_name = $1 AND first_name <> $2"
+	if q := pg.Rebind(query); q != expected {
+		t.Errorf("expected %q, got %q", expected, q)
+	}
+}
@@ -0,0 +1,71 @@
+package sqlp
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/greghart/powerputtygo/queryp"
+	"github.com/greghart/powerputtygo/sqlp/internal/reflectp"
+)
+
+// discriminator scopes a repository to the rows of one type, see WithDiscriminator.
+type discriminator struct {
+	column string
+	value  any
+}
+
+// WithDiscriminator returns a copy of the repository scoped to the rows whose column equals value,
+// for single table inheritance schemas with a repository per type:
+//
+//	dogs := sqlp.NewRepository[dog](db, "animals").WithDiscriminator("type", "dog")
+//	rex, err := dogs.FindBy(ctx, "name", "Rex") // ... WHERE "name" = ? AND "type" = ?
+//
+// Generated queries (Find, FindBy, FindAllBy, Reload, SelectColumns, DeleteWhere, DeleteAll,
+// UpdateWhereIDs and Aggregate) only match those rows, while raw queries (Get, Select, etc.) are
+// left as written. Use Discriminate to set the column of new entities before inserting them.
+func (r *Repository[E]) WithDiscriminator(column string, value any) *Repository[E] {
+	c := *r
+	c.discriminator = &discriminator{column: column, value: value}
+	return &c
+}
+
+// Discriminate sets entity's discriminator column to the repository's value, eg. before inserting
+// it. It does nothing for repositories without a discriminator.
+func (r *Repository[E]) Discriminate(entity *E) error {
+	if r.discriminator == nil {
+		return nil
+	}
+	field, err := r.discriminatorField()
+	if err != nil {
+		return err
+	}
+	v, err := reflect.ValueOf(entity).Elem().FieldByIndexErr(field.Index)
+	if err != nil {
+		return fmt.Errorf("sqlp: discriminator %s: %w", r.discriminator.column, err)
+	}
+	if err := reflectp.Assign(v.Addr().Interface(), r.discriminator.value); err != nil {
+		return fmt.Errorf("sqlp: discriminator %s: %w", r.discriminator.column, err)
+	}
+	return nil
+}
+
+// discriminatorField returns the field of the discriminator column, validating it's one of E's.
+func (r *Repository[E]) discriminatorField() (*reflectp.Field, error) {
+	fields, err := reflectp.FieldsFactory(r.t)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reflect fields for %v: %w", r.t, err)
+	}
+	field, ok := fields.ByColumnName[r.discriminator.column]
+	if !ok || !field.Columnar() {
+		return nil, fmt.Errorf("%w: discriminator %q", ErrUnknownColumn, r.discriminator.column)
+	}
+	return field, nil
+}
+
+// scope returns where limited to the repository's discriminator, if any.
+func (r *Repository[E]) scope(where queryp.Fragment) queryp.Fragment {
+	if r.discriminator == nil {
+		return where
+	}
+	return queryp.And(where, queryp.Eq(r.Dialect().QuoteIdent(r.discriminator.column), r.discriminator.value))
+}
@@ -0,0 +1,105 @@
+package sqlp
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/greghart/powerputtygo/errcmp"
+	"github.com/greghart/powerputtygo/queryp"
+)
+
+func TestRepository_WithDiscriminator(t *testing.T) {
+	db, ctx, cleanup := testDB(t)
+	defer cleanup()
+	setup := func(t *testing.T) {
+		db.MustExec(ctx, "DROP TABLE IF EXISTS animals; CREATE TABLE animals (id INTEGER PRIMARY KEY, type TEXT, name TEXT, nickname TEXT, lives INTEGER)")
+		db.MustExec(ctx, "INSERT INTO animals (type, name, lives) VALUES ('dog', 'Rex', NULL), ('cat', 'Rex', 9), ('dog', 'Fido', NULL)")
+		t.Cleanup(func() { db.MustExec(ctx, "DROP TABLE animals") })
+	}
+	dogs := NewRepository[dog](db, "animals").WithDiscriminator("type", "dog")
+
+	t.Run("scopes finds", func(t *testing.T) {
+		setup(t)
+		rexes, err := dogs.FindAllBy(ctx, "name", "Rex")
+		if err != nil {
+			t.Fatalf("failed to find: %v", err)
+		}
+		expected := []dog{{ID: 1, Type: "dog", Name: "Rex"}}
+		if diff := cmp.Diff(expected, rexes); diff != "" {
+			t.Errorf("found dogs unexpected:\n%v", diff)
+		}
+		if d, err := dogs.Find(ctx, 2); err != nil || d != nil {
+			t.Errorf("expected no dog for a cat's id, got %v, %v", d, err)
+		}
+		n, err := Aggregate[int64](ctx, dogs, Sum("lives"), "name = ?", "Rex")
+		if err != nil || n != 0 {
+			t.Errorf("expected no lives of dogs, got %v, %v", n, err)
+		}
+	})
+
+	t.Run("scopes writes", func(t *testing.T) {
+		setup(t)
+		n, err := dogs.UpdateWhereIDs(ctx, []int64{1, 2}, map[string]any{"nickname": "good"})
+		if err != nil || n != 1 {
+			t.Errorf("expected 1 dog updated, got %v, %v", n, err)
+		}
+		n, err = dogs.DeleteWhere(ctx, queryp.Eq("name", "Rex"))
+		if err != nil || n != 1 {
+			t.Errorf("expected 1 dog deleted, got %v, %v", n, err)
+		}
+		n, err = dogs.DeleteAll(ctx, IUnderstandThisDeletesEverything)
+		if err != nil || n != 1 {
+			t.Errorf("expected 1 dog deleted, got %v, %v", n, err)
+		}
+		cats, err := GetScalar[int64](ctx, db, "SELECT COUNT(*) FROM animals WHERE type = 'cat' AND nickname IS NULL")
+		if err != nil || cats != 1 {
+			t.Errorf("expected cat untouched, got %v, %v", cats, err)
+		}
+		err = dogs.Truncate(ctx, IUnderstandThisDeletesEverything)
+		errcmp.MustMatch(t, err, "would affect rows of other types")
+	})
+
+	t.Run("postgres placeholders", func(t *testing.T) {
+		setup(t)
+		dogs := NewRepository[dog](NewDB(db.DB, WithDialect(queryp.Postgres)), "animals").WithDiscriminator("type", "dog")
+		ctx, capture := CaptureQueries(ctx)
+		rex := must(dogs.Find(ctx, 1))
+		errcmp.MustMatch(t, dogs.Reload(ctx, rex), "")
+		_, err := Aggregate[int64](ctx, dogs, Sum("lives"), "name = $1", "Rex")
+		errcmp.MustMatch(t, err, "")
+		_, err = dogs.UpdateWhereIDs(ctx, []int64{1}, map[string]any{"nickname": "good"})
+		errcmp.MustMatch(t, err, "")
+		_, err = dogs.DeleteAll(ctx, IUnderstandThisDeletesEverything)
+		errcmp.MustMatch(t, err, "")
+
+		var got []string
+		for _, q := range capture.Queries() {
+			got = append(got, q.Query)
+		}
+		expected := []string{
+			`SELECT * FROM animals WHERE (id = $1 AND "type" = $2)`,
+			`SELECT * FROM animals WHERE (id = $1 AND "type" = $2)`,
+			`SELECT SUM(lives) FROM animals WHERE (name = $1 AND "type" = $2)`,
+			`UPDATE animals SET "nickname" = $1 WHERE (id IN ($2) AND "type" = $3)`,
+			`DELETE FROM animals WHERE "type" = $1`,
+		}
+		if diff := cmp.Diff(expected, got); diff != "" {
+			t.Errorf("unexpected queries:\n%v", diff)
+		}
+	})
+
+	t.Run("discriminates entities", func(t *testing.T) {
+		d := dog{Name: "Spot"}
+		if err := dogs.Discriminate(&d); err != nil {
+			t.Fatalf("failed to discriminate: %v", err)
+		}
+		if d.Type != "dog" {
+			t.Errorf("expected dog type, got %q", d.Type)
+		}
+	})
+
+	t.Run("unknown column -> err", func(t *testing.T) {
+		err := NewRepository[dog](db, "animals").WithDiscriminator("kind", "dog").Validate()
+		errcmp.MustMatch(t, err, `unknown column: discriminator "kind"`)
+	})
+}
@@ -0,0 +1,39 @@
+package sqlp
+
+import (
+	"context"
+	"database/sql"
+)
+
+const dryRunKey = contextKeyType("sqlp.dryRun")
+
+// DryRun returns a context where Exec-class statements (Exec, MustExec, ExecRows, ExecID,
+// ExecReturning) are logged but not executed, returning a synthetic result instead.
+// Useful for "what would this job do" runs of batch or migration code. Note queries (including any
+// `RETURNING` statements run through Query rather than ExecReturning) still run, so reads see the
+// real database.
+// See WithDryRun to dry run a whole DB.
+func DryRun(ctx context.Context) context.Context {
+	return context.WithValue(ctx, dryRunKey, true)
+}
+
+// isDryRun returns whether Exec-class statements should be skipped for context.
+func (db *DB) isDryRun(ctx context.Context) bool {
+	if db.dryRun {
+		return true
+	}
+	dryRun, _ := ctx.Value(dryRunKey).(bool)
+	return dryRun
+}
+
+// dryRunExec logs a skipped statement, and returns a synthetic result for it.
+func (db *DB) dryRunExec(query string, args []any) sql.Result {
+	db.logf("sqlp: dry run, skipped exec: %s %v", query, args)
+	return dryRunResult{}
+}
+
+// dryRunResult is the synthetic result of a dry run, with nothing inserted or affected.
+type dryRunResult struct{}
+
+func (dryRunResult) LastInsertId() (int64, error) { return 0, nil }
+func (dryRunResult) RowsAffected() (int64, error) { return 0, nil }
@@ -0,0 +1,57 @@
+package sqlp
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDryRun(t *testing.T) {
+	db, ctx, cleanup := testDB(t)
+	defer cleanup()
+
+	countPeople := func() int {
+		var count int
+		if err := db.QueryRow(ctx, "SELECT COUNT(*) FROM people").Scan(&count); err != nil {
+			t.Fatalf("failed to count: %v", err)
+		}
+		return count
+	}
+
+	t.Run("per context", func(t *testing.T) {
+		logger := &testLogger{}
+		db := NewDB(db.DB, WithLogger(logger))
+		affected, err := db.ExecRows(DryRun(ctx), "INSERT INTO people (first_name) VALUES (?)", "John")
+		if err != nil || affected != 0 {
+			t.Fatalf("expected synthetic result, got %v %v", affected, err)
+		}
+		if count := countPeople(); count != 0 {
+			t.Errorf("expected dry run to not insert, got %v people", count)
+		}
+		if logs := logger.String(); !strings.Contains(logs, "INSERT INTO people (first_name) VALUES (?) [John]") {
+			t.Errorf("expected dry run statement logged, got %v", logs)
+		}
+
+		// Without dry run context, runs as normal
+		db.MustExec(ctx, "INSERT INTO people (first_name) VALUES (?)", "John")
+		if count := countPeople(); count != 1 {
+			t.Errorf("expected insert, got %v people", count)
+		}
+	})
+
+	t.Run("whole DB", func(t *testing.T) {
+		logger := &testLogger{}
+		db := NewDB(db.DB, WithLogger(logger), WithDryRun())
+		id, err := db.ExecID(ctx, "INSERT INTO people (first_name) VALUES (?)", "Jane")
+		if err != nil || id != 0 {
+			t.Fatalf("expected synthetic result, got %v %v", id, err)
+		}
+		if count := countPeople(); count != 1 {
+			t.Errorf("expected dry run to not insert, got %v people", count)
+		}
+		// Reads still work
+		people, err := Select[person](ctx, db, "SELECT id, first_name FROM people")
+		if err != nil || len(people) != 1 {
+			t.Errorf("expected queries to run, got %v %v", people, err)
+		}
+	})
+}
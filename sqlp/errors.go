@@ -0,0 +1,59 @@
+package sqlp
+
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	// ErrNotFound is returned by helpers that require a result, when there is none.
+	ErrNotFound = errors.New("sqlp: not found")
+	// ErrUnknownColumn is returned when a column name doesn't map to a column of an entity.
+	ErrUnknownColumn = errors.New("sqlp: unknown column")
+	// ErrUnknownVariant is returned when a row's discriminator has no registered variant, see
+	// Variants.
+	ErrUnknownVariant = errors.New("sqlp: unknown variant")
+	// ErrDeleteAllRefused is returned by helpers that delete everything, when not allowed to.
+	ErrDeleteAllRefused = errors.New("sqlp: refusing to delete everything")
+	// ErrReadOnly is returned by write helpers of read-only repositories.
+	ErrReadOnly = errors.New("sqlp: repository is read-only")
+	// ErrAlreadyApplied is returned by idempotent helpers, when their key was already applied.
+	ErrAlreadyApplied = errors.New("sqlp: idempotency key already applied")
+	// ErrTxCanceled is returned by RunInTx when its context is done before commit. It wraps the
+	// context's cause (see context.WithCancelCause).
+	ErrTxCanceled = errors.New("sqlp: transaction canceled")
+	// ErrConcurrentTx is returned when a statement is run on a contextual transaction while another
+	// one is still running on it, eg. from several goroutines sharing the transaction's context.
+	ErrConcurrentTx = errors.New("sqlp: concurrent use of transaction")
+	// ErrIntegrity is returned when a row fails an integrity check, eg. its checksum (see
+	// WithChecksum).
+	ErrIntegrity = errors.New("sqlp: integrity check failed")
+	// ErrTooManyRows is returned by selects whose query returns more than the max rows, see
+	// WithMaxRows.
+	ErrTooManyRows = errors.New("sqlp: too many rows")
+	// ErrCommitFailed wraps a transaction's failure to commit, as opposed to its callback's errors,
+	// eg. so retry layers can tell them apart.
+	ErrCommitFailed = errors.New("sqlp: commit failed")
+	// ErrRollbackFailed wraps a failure to roll back a transaction (or to a savepoint), joined to the
+	// error that caused the rollback.
+	ErrRollbackFailed = errors.New("sqlp: rollback failed")
+	// ErrUnknownDatabase is returned by Manager when it has no database of a name.
+	ErrUnknownDatabase = errors.New("sqlp: unknown database")
+	// ErrInvalidConfig is returned when a Config fails to load or validate.
+	ErrInvalidConfig = errors.New("sqlp: invalid config")
+)
+
+// IsDeadlock returns whether err is a deadlock, where the database aborted this transaction to
+// break a cycle of locks. Unlike most errors, deadlocks are safe to retry immediately.
+// Postgres deadlocks are detected by SQLSTATE 40P01 (through the SQLState method of lib/pq and pgx
+// errors), and MySQL deadlocks by error 1213 (whose SQLSTATE is the generic 40001).
+func IsDeadlock(err error) bool {
+	if err == nil {
+		return false
+	}
+	var state interface{ SQLState() string }
+	if errors.As(err, &state) && state.SQLState() == "40P01" {
+		return true
+	}
+	return strings.Contains(err.Error(), "Error 1213")
+}
@@ -0,0 +1,33 @@
+package sqlp
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+type sqlStateError string
+
+func (e sqlStateError) Error() string    { return "sql state " + string(e) }
+func (e sqlStateError) SQLState() string { return string(e) }
+
+func TestIsDeadlock(t *testing.T) {
+	tests := map[string]struct {
+		err      error
+		expected bool
+	}{
+		"postgres":              {sqlStateError("40P01"), true},
+		"wrapped postgres":      {fmt.Errorf("failed to update: %w", sqlStateError("40P01")), true},
+		"serialization failure": {sqlStateError("40001"), false},
+		"mysql":                 {errors.New("Error 1213 (40001): Deadlock found when trying to get lock"), true},
+		"other":                 {errors.New("boom"), false},
+		"nil":                   {nil, false},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := IsDeadlock(test.err); got != test.expected {
+				t.Errorf("expected %v, got %v", test.expected, got)
+			}
+		})
+	}
+}
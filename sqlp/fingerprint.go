@@ -0,0 +1,108 @@
+package sqlp
+
+import (
+	"crypto/sha256"
+	"database/sql/driver"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"reflect"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/greghart/powerputtygo/sqlp/internal/reflectp"
+)
+
+// Fingerprint returns a stable hash of entity's columns, as the scanner reads them, eg. for change
+// detection, ETags, or sync jobs. Excluded columns (eg. "updated_at") are left out, so automatic
+// changes don't change the fingerprint. Unknown excluded columns return ErrUnknownColumn.
+// Values are hashed as their driver values, so eg. an int and int64 of the same value, or times in
+// different locations of the same instant, hash the same.
+//
+//	etag, err := sqlp.Fingerprint(person, "updated_at")
+func Fingerprint[E any](entity E, exclude ...string) (string, error) {
+	return fingerprint(reflect.ValueOf(entity), exclude)
+}
+
+// Fingerprint returns a stable hash of entity's columns, see Fingerprint.
+func (r *Repository[E]) Fingerprint(entity E, exclude ...string) (string, error) {
+	return fingerprint(reflect.ValueOf(entity), exclude)
+}
+
+func fingerprint(v reflect.Value, exclude []string) (string, error) {
+	v = reflect.Indirect(v)
+	fields, err := reflectp.FieldsFactory(v.Type())
+	if err != nil {
+		return "", fmt.Errorf("failed to reflect fields for %v: %w", v.Type(), err)
+	}
+	for _, column := range exclude {
+		if _, ok := fields.ByColumnName[column]; !ok {
+			return "", fmt.Errorf("%w: cannot exclude %q", ErrUnknownColumn, column)
+		}
+	}
+
+	values := map[string]any{}
+	for column, field := range fields.ByColumnName {
+		if !field.Columnar() || slices.Contains(exclude, column) {
+			continue
+		}
+		fv, err := v.FieldByIndexErr(field.Index)
+		if err != nil || !fv.IsValid() {
+			// Nil embedded pointer, so the column would scan as NULL
+			values[column] = nil
+			continue
+		}
+		values[column] = fv.Interface()
+	}
+	return fingerprintValues(values)
+}
+
+// fingerprintValues returns a stable hash of the given column values.
+func fingerprintValues(values map[string]any) (string, error) {
+	columns := make([]string, 0, len(values))
+	for column := range values {
+		columns = append(columns, column)
+	}
+	slices.Sort(columns)
+
+	h := sha256.New()
+	for _, column := range columns {
+		value, err := driver.DefaultParameterConverter.ConvertValue(values[column])
+		if err != nil {
+			return "", fmt.Errorf("sqlp: fingerprint column %s: %w", column, err)
+		}
+		writeFingerprint(h, column, value)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// writeFingerprint writes a column and its value unambiguously, length prefixing and tagging
+// values by type.
+func writeFingerprint(h hash.Hash, column string, value driver.Value) {
+	write := func(tag byte, s string) {
+		h.Write([]byte{tag})
+		h.Write(binary.BigEndian.AppendUint64(nil, uint64(len(s))))
+		h.Write([]byte(s))
+	}
+	write('c', column)
+	switch value := value.(type) {
+	case nil:
+		write('n', "")
+	case int64:
+		write('i', strconv.FormatInt(value, 10))
+	case float64:
+		write('f', strconv.FormatFloat(value, 'g', -1, 64))
+	case bool:
+		write('b', strconv.FormatBool(value))
+	case []byte:
+		write('x', string(value))
+	case string:
+		write('s', value)
+	case time.Time:
+		write('t', value.UTC().Format(time.RFC3339Nano))
+	default:
+		write('v', fmt.Sprintf("%T %v", value, value))
+	}
+}
@@ -0,0 +1,63 @@
+package sqlp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/greghart/powerputtygo/errcmp"
+)
+
+func TestFingerprint(t *testing.T) {
+	now := time.Now()
+	base := person{ID: 1, FirstName: "John", LastName: "Doe", timestamps: timestamps{CreatedAt: now, UpdatedAt: now}}
+	fingerprint := func(p person, exclude ...string) string {
+		t.Helper()
+		return must(Fingerprint(p, exclude...))
+	}
+	expected := fingerprint(base)
+
+	tests := map[string]struct {
+		p       person
+		exclude []string
+		same    bool
+	}{
+		"same columns": {p: base, same: true},
+		"same instant in another location": {
+			p:    func() person { p := base; p.CreatedAt = now.In(time.FixedZone("x", 3600)); return p }(),
+			same: true,
+		},
+		"non columns ignored": {
+			p:    func() person { p := base; p.Child = &person{ID: 2}; p.Children = []person{{}}; return p }(),
+			same: true,
+		},
+		"changed column": {
+			p: func() person { p := base; p.LastName = "Smith"; return p }(),
+		},
+		"null vs empty": {
+			p: func() person { p := base; p.NullString = stringPtr(""); return p }(),
+		},
+		"excluded column changes": {
+			p:       func() person { p := base; p.UpdatedAt = now.Add(time.Hour); return p }(),
+			exclude: []string{"updated_at"},
+			same:    true,
+		},
+		"changed column without exclusion": {
+			p: func() person { p := base; p.UpdatedAt = now.Add(time.Hour); return p }(),
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, expected := fingerprint(test.p, test.exclude...), fingerprint(base, test.exclude...)
+			if (got == expected) != test.same {
+				t.Errorf("expected same fingerprint %v, got %v vs %v", test.same, got, expected)
+			}
+		})
+	}
+
+	repository := NewRepository[person](NewDB(nil), "people")
+	if got := must(repository.Fingerprint(base)); got != expected {
+		t.Errorf("expected repository fingerprint to match, got %v vs %v", got, expected)
+	}
+	_, err := Fingerprint(base, "email")
+	errcmp.MustMatch(t, err, "unknown column: cannot exclude \"email\"")
+}
@@ -0,0 +1,21 @@
+package sqlp
+
+import (
+	"database/sql/driver"
+
+	"github.com/greghart/powerputtygo/sqlp/internal/reflectp"
+)
+
+// Gzip returns v compressed for writing to a column scanned by a `sqlp:",gzip"` field, for writes
+// outside of sqlp's helpers (which compress such fields already).
+//
+//	db.Exec(ctx, "INSERT INTO documents (body) VALUES (?)", sqlp.Gzip(body))
+func Gzip[T ~string | ~[]byte](v T) driver.Valuer {
+	return gzipValue(v)
+}
+
+type gzipValue []byte
+
+func (v gzipValue) Value() (driver.Value, error) {
+	return reflectp.Gzip(v)
+}
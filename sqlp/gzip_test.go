@@ -0,0 +1,67 @@
+package sqlp
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/greghart/powerputtygo/errcmp"
+)
+
+type document struct {
+	ID         int64   `sqlp:"id"`
+	Body       string  `sqlp:"body,gzip"`
+	Attachment *[]byte `sqlp:"attachment,gzip"`
+}
+
+func (document) Table() string { return "documents" }
+
+func TestRepository_Gzip(t *testing.T) {
+	db, ctx, cleanup := testDB(t)
+	defer cleanup()
+	db.MustExec(ctx, "DROP TABLE IF EXISTS documents; CREATE TABLE documents (id INTEGER PRIMARY KEY, body BLOB, attachment BLOB)")
+	defer db.MustExec(ctx, "DROP TABLE documents")
+
+	body := strings.Repeat("all work and no play ", 100)
+	db.MustExec(ctx, "INSERT INTO documents (id, body) VALUES (1, ?)", Gzip(body))
+	db.MustExec(ctx, "INSERT INTO documents (id, body) VALUES (2, ?)", "written before compression")
+
+	r := NewRepository[document](db)
+	docs, err := r.Select(ctx, "SELECT * FROM documents ORDER BY id")
+	if err != nil {
+		t.Fatalf("Select: %v", err)
+	}
+	expected := []document{{ID: 1, Body: body}, {ID: 2, Body: "written before compression"}}
+	if diff := cmp.Diff(expected, docs); diff != "" {
+		t.Errorf("unexpected documents (-want +got):\n%s", diff)
+	}
+
+	type content struct {
+		Body       string `sqlp:"body,gzip"`
+		Attachment []byte `sqlp:"attachment,gzip"`
+	}
+	attachment := []byte{0, 1, 2, 3}
+	if _, err := r.UpdateWhereIDs(ctx, []int64{2}, content{Body: body, Attachment: attachment}); err != nil {
+		t.Fatalf("UpdateWhereIDs: %v", err)
+	}
+	var stored []byte
+	if err := db.QueryRow(ctx, "SELECT body FROM documents WHERE id = 2").Scan(&stored); err != nil {
+		t.Fatalf("QueryRow: %v", err)
+	}
+	if !bytes.HasPrefix(stored, []byte{0x1f, 0x8b}) || len(stored) >= len(body) {
+		t.Errorf("expected body stored compressed, got %d bytes", len(stored))
+	}
+	doc, err := r.Find(ctx, 2)
+	if err != nil {
+		t.Fatalf("Find: %v", err)
+	}
+	if diff := cmp.Diff(&document{ID: 2, Body: body, Attachment: &attachment}, doc); diff != "" {
+		t.Errorf("unexpected document (-want +got):\n%s", diff)
+	}
+
+	type invalid struct {
+		Count int `sqlp:"count,gzip"`
+	}
+	errcmp.MustMatch(t, NewRepository[invalid](db, "invalid").Validate(), "gzip field Count must be a string or []byte")
+}
@@ -0,0 +1,91 @@
+package sqlp
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/greghart/powerputtygo/queryp"
+)
+
+// DefaultIdempotencyTable is the table idempotency keys are recorded in, unless set with
+// WithIdempotencyTable.
+const DefaultIdempotencyTable = "sqlp_idempotency_keys"
+
+// CreateIdempotencyTable creates the idempotency keys table if it doesn't exist.
+// Intended for migrations and tests. Keys are kept forever, so prune old ones by created_at as
+// suits your retry windows.
+func (db *DB) CreateIdempotencyTable(ctx context.Context) error {
+	_, err := db.Exec(
+		ctx,
+		"CREATE TABLE IF NOT EXISTS "+db.idempotencyTable()+
+			" (idempotency_key VARCHAR(255) PRIMARY KEY, created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP)",
+	)
+	return err
+}
+
+// RunIdempotent runs fn in a transaction at most once per key, for payment-like flows whose
+// requests may be retried (by clients, or retry middleware).
+// The key is recorded in the idempotency keys table in the same transaction as fn's writes, so a
+// retried request returns ErrAlreadyApplied instead of applying them twice, while a failed request
+// records nothing and can be retried with the same key. Concurrent requests with the same key wait
+// on each other's transaction.
+//
+//	err := db.RunIdempotent(ctx, r.Header.Get("Idempotency-Key"), func(ctx context.Context) error { ... })
+//	if errors.Is(err, sqlp.ErrAlreadyApplied) { ... } // eg. respond as if it succeeded
+func (db *DB) RunIdempotent(ctx context.Context, key string, fn func(context.Context) error) error {
+	return db.RunInTx(ctx, func(ctx context.Context) error {
+		recorded, err := db.recordIdempotencyKey(ctx, key)
+		if err != nil {
+			return fmt.Errorf("failed to record idempotency key: %w", err)
+		}
+		if !recorded {
+			return fmt.Errorf("%w: %s", ErrAlreadyApplied, key)
+		}
+		return fn(ctx)
+	})
+}
+
+// ExecIdempotent runs Exec at most once per key, see RunIdempotent.
+func (db *DB) ExecIdempotent(ctx context.Context, key string, query string, args ...any) (sql.Result, error) {
+	var res sql.Result
+	err := db.RunIdempotent(ctx, key, func(ctx context.Context) error {
+		var err error
+		res, err = db.Exec(ctx, query, args...)
+		return err
+	})
+	return res, err
+}
+
+// recordIdempotencyKey records key, returning false if it was already recorded.
+func (db *DB) recordIdempotencyKey(ctx context.Context, key string) (bool, error) {
+	table, placeholder := db.idempotencyTable(), "?"
+	var q string
+	switch db.Dialect() {
+	case queryp.Postgres:
+		placeholder = "$1"
+		q = "INSERT INTO " + table + " (idempotency_key) VALUES ($1) ON CONFLICT DO NOTHING"
+	case queryp.Sqlite:
+		q = "INSERT INTO " + table + " (idempotency_key) VALUES (?) ON CONFLICT DO NOTHING"
+	case queryp.MySQL:
+		q = "INSERT IGNORE INTO " + table + " (idempotency_key) VALUES (?)"
+	default:
+		return false, db.errUnknownDialect("RunIdempotent")
+	}
+	if db.isDryRun(ctx) {
+		// Nothing is recorded, so check whether it would have been
+		db.dryRunExec(q, []any{key})
+		var exists int
+		err := db.QueryRow(ctx, "SELECT COUNT(*) FROM "+table+" WHERE idempotency_key = "+placeholder, key).Scan(&exists)
+		return exists == 0, err
+	}
+	recorded, err := db.ExecRows(ctx, q, key)
+	return recorded == 1, err
+}
+
+func (db *DB) idempotencyTable() string {
+	if db.idempotencyTableName == "" {
+		return DefaultIdempotencyTable
+	}
+	return db.idempotencyTableName
+}
@@ -0,0 +1,70 @@
+package sqlp
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/greghart/powerputtygo/errcmp"
+)
+
+func TestDB_RunIdempotent(t *testing.T) {
+	db, ctx, cleanup := testDB(t)
+	defer cleanup()
+	db.MustExec(ctx, "DROP TABLE IF EXISTS "+DefaultIdempotencyTable)
+	if err := db.CreateIdempotencyTable(ctx); err != nil {
+		t.Fatalf("failed to create idempotency table: %v", err)
+	}
+
+	count := func() (n int) {
+		t.Helper()
+		if err := db.QueryRow(ctx, "SELECT COUNT(*) FROM people").Scan(&n); err != nil {
+			t.Fatalf("failed to count: %v", err)
+		}
+		return n
+	}
+	insert := func(ctx context.Context, key string) error {
+		_, err := db.ExecIdempotent(ctx, key, "INSERT INTO people (first_name, last_name) VALUES (?, ?)", "John", "Doe")
+		return err
+	}
+
+	if err := insert(ctx, "a"); err != nil {
+		t.Fatalf("expected first request to apply, got %v", err)
+	}
+	err := insert(ctx, "a")
+	errcmp.MustMatch(t, err, "idempotency key already applied: a")
+	if !errors.Is(err, ErrAlreadyApplied) {
+		t.Errorf("expected ErrAlreadyApplied, got %v", err)
+	}
+	if n := count(); n != 1 {
+		t.Errorf("expected retried request to not apply, got %d people", n)
+	}
+
+	t.Run("failed requests can be retried", func(t *testing.T) {
+		err := db.RunIdempotent(ctx, "b", func(ctx context.Context) error {
+			db.MustExec(ctx, "INSERT INTO people (first_name, last_name) VALUES (?, ?)", "Jane", "Doe")
+			return errors.New("boom")
+		})
+		errcmp.MustMatch(t, err, "boom")
+		if err := insert(ctx, "b"); err != nil {
+			t.Errorf("expected retry to apply, got %v", err)
+		}
+		if n := count(); n != 2 {
+			t.Errorf("expected failed request's writes rolled back, got %d people", n)
+		}
+	})
+
+	t.Run("dry run records nothing", func(t *testing.T) {
+		logger := &testLogger{}
+		db := NewDB(db.DB, WithLogger(logger), WithDryRun())
+		if _, err := db.ExecIdempotent(ctx, "c", "DELETE FROM people"); err != nil {
+			t.Errorf("expected dry run to apply, got %v", err)
+		}
+		_, err := db.ExecIdempotent(ctx, "a", "DELETE FROM people")
+		errcmp.MustMatch(t, err, "already applied")
+		var keys int
+		if err := db.QueryRow(ctx, "SELECT COUNT(*) FROM "+DefaultIdempotencyTable).Scan(&keys); err != nil || keys != 2 {
+			t.Errorf("expected 2 keys recorded, got %d, %v", keys, err)
+		}
+	})
+}
@@ -0,0 +1,27 @@
+package sqlp
+
+import (
+	"context"
+	"slices"
+)
+
+const ignoreColumnsKey = contextKeyType("sqlp.ignoreColumns")
+
+// IgnoreColumns returns a context where reflective scans (Get, Select, and repository reads) leave
+// the named fields as is, even if their columns are in the result, eg. to skip hydrating a huge blob
+// column returned by `SELECT *`. Fields are named by column or Go field name, and names a
+// destination doesn't have are ignored, so the context can be shared across entities.
+// Note the driver still reads ignored columns, so prefer selecting fewer columns (eg. with
+// SelectColumns) where you can.
+// BulkInsert leaves ignored columns out of its inserts too, so the database fills in their defaults.
+//
+//	people, err := repository.Select(sqlp.IgnoreColumns(ctx, "avatar"), "SELECT * FROM people")
+func IgnoreColumns(ctx context.Context, names ...string) context.Context {
+	return context.WithValue(ctx, ignoreColumnsKey, append(ignoredColumns(ctx), names...))
+}
+
+// ignoredColumns returns the fields to ignore for context.
+func ignoredColumns(ctx context.Context) []string {
+	names, _ := ctx.Value(ignoreColumnsKey).([]string)
+	return slices.Clip(names)
+}
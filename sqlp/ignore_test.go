@@ -0,0 +1,40 @@
+package sqlp
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestIgnoreColumns(t *testing.T) {
+	db, ctx, cleanup := testDB(t)
+	defer cleanup()
+	grandparent := grandchildrenSetup(ctx, db)
+
+	ctx = IgnoreColumns(ctx, "last_name")
+	ctx = IgnoreColumns(ctx, "FirstName", "not_a_column") // By field name too, and unknown names are fine
+
+	var p person
+	if err := db.Get(ctx, &p, "SELECT * FROM people WHERE id = ?", grandparent.ID); err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if p.ID != grandparent.ID || p.FirstName != "" || p.LastName != "" {
+		t.Errorf("expected only id scanned, got %+v", p)
+	}
+
+	var people []person
+	if err := db.Select(ctx, &people, "SELECT * FROM people ORDER BY id"); err != nil {
+		t.Fatalf("Select: %v", err)
+	}
+	if len(people) != 3 || people[0].LastName != "" || people[0].CreatedAt.IsZero() {
+		t.Errorf("expected ignored names, with the rest scanned, got %+v", people)
+	}
+
+	found, err := NewRepository[person](db, "people").Find(ctx, int(grandparent.ID))
+	if err != nil {
+		t.Fatalf("Find: %v", err)
+	}
+	if expected := (person{ID: grandparent.ID, timestamps: grandparent.timestamps}); !cmp.Equal(expected, *found, personComparer) {
+		t.Errorf("unexpected person:\n%v", cmp.Diff(expected, *found, personComparer))
+	}
+}
@@ -0,0 +1,76 @@
+package sqlp
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Pluralize is the conventional inflector for WithInflector, inferring snake case plural table
+// names from type names, eg. person -> people, OrderItem -> order_items, HTTPRequest ->
+// http_requests.
+func Pluralize(typeName string) string {
+	words := strings.Split(snakeCase(typeName), "_")
+	words[len(words)-1] = pluralWord(words[len(words)-1])
+	return strings.Join(words, "_")
+}
+
+var (
+	irregularPlurals = map[string]string{
+		"person": "people",
+		"child":  "children",
+		"man":    "men",
+		"woman":  "women",
+		"mouse":  "mice",
+		"goose":  "geese",
+		"foot":   "feet",
+		"tooth":  "teeth",
+		"datum":  "data",
+	}
+	uncountables = map[string]bool{
+		"equipment":   true,
+		"fish":        true,
+		"information": true,
+		"news":        true,
+		"series":      true,
+		"sheep":       true,
+		"species":     true,
+	}
+)
+
+// pluralWord returns the plural of a lower case word.
+func pluralWord(word string) string {
+	if plural, ok := irregularPlurals[word]; ok {
+		return plural
+	}
+	if uncountables[word] {
+		return word
+	}
+	switch {
+	case len(word) > 1 && strings.HasSuffix(word, "y") && !strings.ContainsRune("aeiou", rune(word[len(word)-2])):
+		return word[:len(word)-1] + "ies"
+	case strings.HasSuffix(word, "s"), strings.HasSuffix(word, "x"), strings.HasSuffix(word, "z"),
+		strings.HasSuffix(word, "ch"), strings.HasSuffix(word, "sh"):
+		return word + "es"
+	}
+	return word + "s"
+}
+
+// snakeCase converts a Go identifier to snake case, keeping initialisms together.
+func snakeCase(name string) string {
+	if i := strings.IndexByte(name, '['); i >= 0 {
+		name = name[:i] // generic type arguments
+	}
+	runes := []rune(name)
+	b := strings.Builder{}
+	for i, r := range runes {
+		if unicode.IsUpper(r) && i > 0 {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				b.WriteByte('_')
+			}
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
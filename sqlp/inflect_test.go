@@ -0,0 +1,46 @@
+package sqlp
+
+import (
+	"testing"
+)
+
+func TestPluralize(t *testing.T) {
+	tests := map[string]string{
+		"person":       "people",
+		"Person":       "people",
+		"pet":          "pets",
+		"OrderItem":    "order_items",
+		"GrandChild":   "grand_children",
+		"HTTPRequest":  "http_requests",
+		"Address":      "addresses",
+		"Box":          "boxes",
+		"Batch":        "batches",
+		"Category":     "categories",
+		"Key":          "keys",
+		"Sheep":        "sheep",
+		"V2Record":     "v2_records",
+		"Page[person]": "pages",
+		"sqlpKVEntry":  "sqlp_kv_entries",
+	}
+	for name, expected := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := Pluralize(name); got != expected {
+				t.Errorf("Pluralize(%q) = %q, expected %q", name, got, expected)
+			}
+		})
+	}
+}
+
+func TestWithInflector(t *testing.T) {
+	db := NewDB(nil, WithInflector(Pluralize))
+
+	if table := NewRepository[person](db).Table(); table != "people" {
+		t.Errorf("expected inferred people table, got %q", table)
+	}
+	if table := NewRepository[person](db, "persons").Table(); table != "persons" {
+		t.Errorf("expected given persons table, got %q", table)
+	}
+	if table := NewRepository[person](NewDB(nil)).Table(); table != "" {
+		t.Errorf("expected no table without an inflector, got %q", table)
+	}
+}
@@ -0,0 +1,111 @@
+package reflectp
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// Assign copies a value read from the database (eg. scanned into *any) into dest, like
+// sql.Rows.Scan would have. It covers the conversions drivers need in practice: sql.Scanners,
+// NULLs into nilable types, numbers between widths, and text into strings, numbers and bools.
+func Assign(dest, src any) error {
+	switch d := dest.(type) {
+	case sql.Scanner:
+		return d.Scan(src)
+	case *any:
+		*d = src
+		return nil
+	}
+	dv := reflect.ValueOf(dest)
+	if dv.Kind() != reflect.Pointer || dv.IsNil() {
+		return fmt.Errorf("destination not a pointer: %T", dest)
+	}
+	return assignValue(dv.Elem(), src)
+}
+
+func assignValue(dv reflect.Value, src any) error {
+	if src == nil {
+		switch dv.Kind() {
+		case reflect.Pointer, reflect.Interface, reflect.Slice, reflect.Map:
+			dv.SetZero()
+			return nil
+		}
+		return fmt.Errorf("converting NULL to %v is unsupported", dv.Type())
+	}
+	if dv.Kind() == reflect.Pointer {
+		if dv.IsNil() {
+			dv.Set(reflect.New(dv.Type().Elem()))
+		}
+		return Assign(dv.Interface(), src)
+	}
+	if scanner, ok := dv.Addr().Interface().(sql.Scanner); ok {
+		return scanner.Scan(src)
+	}
+
+	sv := reflect.ValueOf(src)
+	if sv.Type().AssignableTo(dv.Type()) {
+		if b, ok := src.([]byte); ok {
+			src = append([]byte(nil), b...) // drivers may reuse their buffers
+			sv = reflect.ValueOf(src)
+		}
+		dv.Set(sv)
+		return nil
+	}
+	switch src := src.(type) {
+	case []byte:
+		return assignText(dv, string(src))
+	case string:
+		return assignText(dv, src)
+	}
+	switch {
+	case dv.Kind() == reflect.String:
+		dv.SetString(fmt.Sprint(src))
+		return nil
+	case dv.Kind() == reflect.Bool && sv.CanInt():
+		dv.SetBool(sv.Int() != 0)
+		return nil
+	case isNumber(dv.Kind()) && isNumber(sv.Kind()):
+		dv.Set(sv.Convert(dv.Type()))
+		return nil
+	}
+	return fmt.Errorf("converting %T to %v is unsupported", src, dv.Type())
+}
+
+// assignText assigns a textual value, as returned for most types by some drivers (eg. MySQL).
+func assignText(dv reflect.Value, s string) error {
+	var err error
+	switch {
+	case dv.Kind() == reflect.String:
+		dv.SetString(s)
+	case dv.Kind() == reflect.Slice && dv.Type().Elem().Kind() == reflect.Uint8:
+		dv.SetBytes([]byte(s))
+	case dv.Kind() == reflect.Bool:
+		var b bool
+		b, err = strconv.ParseBool(s)
+		dv.SetBool(b)
+	case dv.CanInt():
+		var n int64
+		n, err = strconv.ParseInt(s, 10, dv.Type().Bits())
+		dv.SetInt(n)
+	case dv.CanUint():
+		var n uint64
+		n, err = strconv.ParseUint(s, 10, dv.Type().Bits())
+		dv.SetUint(n)
+	case dv.CanFloat():
+		var n float64
+		n, err = strconv.ParseFloat(s, dv.Type().Bits())
+		dv.SetFloat(n)
+	default:
+		return fmt.Errorf("converting text to %v is unsupported", dv.Type())
+	}
+	if err != nil {
+		return fmt.Errorf("converting %q to %v: %w", s, dv.Type(), err)
+	}
+	return nil
+}
+
+func isNumber(k reflect.Kind) bool {
+	return k >= reflect.Int && k <= reflect.Float64
+}
@@ -0,0 +1,54 @@
+package reflectp
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/greghart/powerputtygo/errcmp"
+)
+
+func TestAssign(t *testing.T) {
+	now := time.Now()
+	tests := map[string]struct {
+		dest     func() any
+		src      any
+		expected any
+		err      string
+	}{
+		"same type":           {dest: func() any { return new(int64) }, src: int64(1), expected: int64(1)},
+		"narrower int":        {dest: func() any { return new(int) }, src: int64(1), expected: 1},
+		"int to float":        {dest: func() any { return new(float64) }, src: int64(1), expected: 1.0},
+		"int to bool":         {dest: func() any { return new(bool) }, src: int64(1), expected: true},
+		"int to string":       {dest: func() any { return new(string) }, src: int64(1), expected: "1"},
+		"bytes to string":     {dest: func() any { return new(string) }, src: []byte("hi"), expected: "hi"},
+		"text to int":         {dest: func() any { return new(int32) }, src: []byte("42"), expected: int32(42)},
+		"text to bool":        {dest: func() any { return new(bool) }, src: "true", expected: true},
+		"time":                {dest: func() any { return new(time.Time) }, src: now, expected: now},
+		"into pointer":        {dest: func() any { return new(*string) }, src: "hi", expected: ptr("hi")},
+		"null into pointer":   {dest: func() any { return new(*string) }, src: nil, expected: (*string)(nil)},
+		"scanner":             {dest: func() any { return new(sql.NullInt64) }, src: int64(1), expected: sql.NullInt64{Int64: 1, Valid: true}},
+		"any":                 {dest: func() any { return new(any) }, src: "hi", expected: any("hi")},
+		"null into value err": {dest: func() any { return new(int) }, src: nil, err: "converting NULL to int is unsupported"},
+		"bad text err":        {dest: func() any { return new(int) }, src: "nope", err: `converting "nope" to int`},
+		"unsupported err":     {dest: func() any { return new(time.Time) }, src: int64(1), err: "converting int64 to time.Time is unsupported"},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			dest := test.dest()
+			err := Assign(dest, test.src)
+			errcmp.MustMatch(t, err, test.err)
+			if test.err != "" {
+				return
+			}
+			got := reflect.ValueOf(dest).Elem().Interface()
+			if diff := cmp.Diff(test.expected, got); diff != "" {
+				t.Errorf("assigned value unexpected:\n%v", diff)
+			}
+		})
+	}
+}
+
+func ptr[T any](v T) *T { return &v }
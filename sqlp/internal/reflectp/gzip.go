@@ -0,0 +1,80 @@
+package reflectp
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"reflect"
+)
+
+// gzipMagic starts all gzip data, letting compressed values be told apart from plain ones.
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// Gzip compresses b.
+func Gzip(b []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(b); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Gunzip decompresses b, or returns it as is if it isn't gzip data (eg. written before a field was
+// compressed).
+func Gunzip(b []byte) ([]byte, error) {
+	if !bytes.HasPrefix(b, gzipMagic) {
+		return b, nil
+	}
+	r, err := gzip.NewReader(bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
+
+// isGzippable returns whether t can be a gzip field.
+func isGzippable(t reflect.Type) bool {
+	return t.Kind() == reflect.String || (t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8)
+}
+
+// gzipScanner scans a possibly compressed value into a gzip field.
+type gzipScanner struct {
+	dest reflect.Value // The field
+}
+
+func (s gzipScanner) Scan(src any) error {
+	var b []byte
+	switch src := src.(type) {
+	case nil:
+		s.dest.SetZero()
+		return nil
+	case []byte:
+		b = src
+	case string:
+		b = []byte(src)
+	default:
+		return fmt.Errorf("cannot scan %T into gzip field", src)
+	}
+	b, err := Gunzip(b)
+	if err != nil {
+		return fmt.Errorf("failed to decompress: %w", err)
+	}
+
+	v := s.dest
+	if v.Kind() == reflect.Pointer {
+		v.Set(reflect.New(v.Type().Elem()))
+		v = v.Elem()
+	}
+	if v.Kind() == reflect.String {
+		v.SetString(string(b))
+	} else {
+		v.SetBytes(bytes.Clone(b))
+	}
+	return nil
+}
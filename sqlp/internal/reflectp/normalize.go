@@ -0,0 +1,66 @@
+package reflectp
+
+import (
+	"reflect"
+	"strconv"
+)
+
+// Normalization loosens how driver values scan into fields, for drivers that return some types
+// differently than others (eg. integers for SQLite booleans).
+type Normalization struct {
+	BoolAsInt     bool // Bool fields scan any integer (including as text), non-zero being true
+	BytesAsString bool // Interface fields scan text returned as []byte as a string
+}
+
+// wrap returns target wrapped to normalize what's scanned into it, if need be.
+func (n Normalization) wrap(target any) any {
+	switch t := target.(type) {
+	case *bool, **bool:
+		if n.BoolAsInt {
+			return boolScanner{dest: reflect.ValueOf(t).Elem()}
+		}
+	case *any:
+		if n.BytesAsString {
+			return bytesScanner{dest: t}
+		}
+	}
+	return target
+}
+
+// boolScanner scans integers into a bool (or *bool) field.
+type boolScanner struct {
+	dest reflect.Value
+}
+
+func (s boolScanner) Scan(src any) error {
+	dest := s.dest.Addr().Interface()
+	var text string
+	switch src := src.(type) {
+	case int64:
+		return Assign(dest, src != 0)
+	case []byte:
+		text = string(src)
+	case string:
+		text = src
+	default:
+		return Assign(dest, src)
+	}
+	if n, err := strconv.ParseInt(text, 10, 64); err == nil {
+		return Assign(dest, n != 0)
+	}
+	return Assign(dest, text) // eg. "true"
+}
+
+// bytesScanner scans text returned as []byte into an interface field as a string.
+type bytesScanner struct {
+	dest *any
+}
+
+func (s bytesScanner) Scan(src any) error {
+	if b, ok := src.([]byte); ok {
+		*s.dest = string(b)
+		return nil
+	}
+	*s.dest = src
+	return nil
+}
@@ -0,0 +1,103 @@
+package reflectp
+
+import (
+	"container/list"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// planCacheSize is how many scan plans are cached, enough for the distinct queries of most apps.
+const planCacheSize = 1024
+
+// plans caches scan plans across results, keyed by struct type and columns, so scanning a query's
+// results doesn't rebuild its targeters every time.
+var plans = newPlanCache(planCacheSize)
+
+type planKey struct {
+	t       reflect.Type
+	columns string // Joined result columns
+	ignore  string // Joined ignored fields
+}
+
+type planEntry struct {
+	key  planKey
+	plan *scanPlan
+}
+
+// planCache is a least recently used cache of scan plans.
+type planCache struct {
+	size    int
+	mu      sync.Mutex
+	entries map[planKey]*list.Element
+	lru     *list.List // Of *planEntry, most recently used first
+	stats   PlanCacheStats
+}
+
+func newPlanCache(size int) *planCache {
+	return &planCache{size: size, entries: map[planKey]*list.Element{}, lru: list.New()}
+}
+
+// PlanCacheStats are stats of the scan plan cache, eg. to check it's large enough.
+type PlanCacheStats struct {
+	Hits      int64
+	Misses    int64
+	Evictions int64
+	Size      int
+}
+
+// HitRate returns the ratio of lookups that were hits, 0 if there were none.
+func (s PlanCacheStats) HitRate() float64 {
+	if s.Hits+s.Misses == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Hits+s.Misses)
+}
+
+// PlanCache returns stats of the scan plan cache.
+func PlanCache() PlanCacheStats {
+	return plans.Stats()
+}
+
+// Stats returns stats of the cache.
+func (c *planCache) Stats() PlanCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stats := c.stats
+	stats.Size = c.lru.Len()
+	return stats
+}
+
+// get returns the plan for scanning cols into f's fields, building it on a miss.
+func (c *planCache) get(f *Fields, cols []string, ignore []string) (*scanPlan, error) {
+	key := planKey{t: f.Type, columns: strings.Join(cols, "\x00"), ignore: strings.Join(ignore, "\x00")}
+	c.mu.Lock()
+	if e, ok := c.entries[key]; ok {
+		c.lru.MoveToFront(e)
+		c.stats.Hits++
+		c.mu.Unlock()
+		return e.Value.(*planEntry).plan, nil
+	}
+	c.stats.Misses++
+	c.mu.Unlock()
+
+	// Build outside the lock, concurrent misses may both build but plans are interchangeable.
+	plan, err := newScanPlan(f, cols, ignore)
+	if err != nil {
+		return plan, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.entries[key]; ok {
+		return e.Value.(*planEntry).plan, nil
+	}
+	c.entries[key] = c.lru.PushFront(&planEntry{key: key, plan: plan})
+	if c.lru.Len() > c.size {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*planEntry).key)
+		c.stats.Evictions++
+	}
+	return plan, nil
+}
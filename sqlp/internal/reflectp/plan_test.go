@@ -0,0 +1,49 @@
+package reflectp
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestPlanCache(t *testing.T) {
+	type Person struct {
+		ID   int    `sqlp:"id"`
+		Name string `sqlp:"name"`
+	}
+	fields, err := FieldsFactory(reflect.TypeOf(Person{}))
+	if err != nil {
+		t.Fatalf("failed to reflect fields: %v", err)
+	}
+
+	c := newPlanCache(2)
+	get := func(cols []string, ignore ...string) *scanPlan {
+		t.Helper()
+		plan, err := c.get(fields, cols, ignore)
+		if err != nil {
+			t.Fatalf("failed to get plan: %v", err)
+		}
+		return plan
+	}
+	plan := get([]string{"id", "name"})
+	if get([]string{"id", "name"}) != plan {
+		t.Errorf("expected same columns to share a plan")
+	}
+	if get([]string{"id", "name"}, "name") == plan {
+		t.Errorf("expected ignored fields to have their own plan")
+	}
+	get([]string{"id", "name"})
+	get([]string{"id"}) // evicts the least recently used, the ignore plan
+	if get([]string{"id", "name"}) != plan {
+		t.Errorf("expected recently used plan to be kept")
+	}
+
+	expected := PlanCacheStats{Hits: 3, Misses: 3, Evictions: 1, Size: 2}
+	if diff := cmp.Diff(expected, c.Stats()); diff != "" {
+		t.Errorf("unexpected stats (-want +got):\n%s", diff)
+	}
+	if rate := c.Stats().HitRate(); rate != 0.5 {
+		t.Errorf("expected hit rate of 0.5, got %v", rate)
+	}
+}
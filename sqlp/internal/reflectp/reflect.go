@@ -3,11 +3,13 @@ package reflectp
 import (
 	"cmp"
 	"database/sql"
+	"database/sql/driver"
 	"fmt"
 	"reflect"
 	"slices"
 	"strings"
 	"sync"
+	"time"
 	"unicode"
 )
 
@@ -16,16 +18,27 @@ import (
 // Key difference is json recursively encodes/decodes, we're handling flat tabular data.
 type Field struct {
 	Column string
+	Name   string // Go field name
 
 	Tag        bool
+	Gzip       bool  // Whether the column is stored compressed, see Value and Gunzip
+	Scale      int64 // Field units per column unit if not 0, eg. time.Millisecond for `duration=ms`
 	Index      []int
 	DirectType reflect.Type // Direct type of field, equal to Type unless pointer
 	Type       reflect.Type
 
+	opts tagOptions
+
 	// Cached sub fields
 	fields *Fields // Fields of the struct, if this is a struct.
 }
 
+// HasOption returns whether the field's sqlp tag has the given option, eg. "auto" for
+// `sqlp:"id,auto"`.
+func (f *Field) HasOption(name string) bool {
+	return f.opts.Contains(name)
+}
+
 // Get the sub fields of this field when it's a struct itself.
 func (f *Field) Fields() *Fields {
 	if f.fields != nil {
@@ -39,6 +52,53 @@ func (f *Field) Fields() *Fields {
 	return nil
 }
 
+// Columnar returns whether the field holds a single column value, as opposed to a struct or
+// collection that is only populated through prefixed sub columns.
+func (f *Field) Columnar() bool {
+	t := f.DirectType
+	if t == timeType || t.Implements(valuerType) || reflect.PointerTo(t).Implements(scannerType) {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.Struct, reflect.Map, reflect.Array, reflect.Chan, reflect.Func:
+		return false
+	case reflect.Slice:
+		return t.Elem().Kind() == reflect.Uint8 // []byte
+	}
+	return true
+}
+
+// Value returns the value to write for the field, given its value v, compressing or scaling it if
+// need be.
+func (f *Field) Value(v reflect.Value) (any, error) {
+	if !f.Gzip && f.Scale == 0 {
+		return v.Interface(), nil
+	}
+	v = reflect.Indirect(v)
+	if !v.IsValid() {
+		return nil, nil
+	}
+	if f.Scale != 0 {
+		if v.CanUint() {
+			return int64(v.Uint()) / f.Scale, nil
+		}
+		if v.CanInt() {
+			return v.Int() / f.Scale, nil
+		}
+		return nil, fmt.Errorf("cannot scale %v", v.Type())
+	}
+	if v.Kind() == reflect.String {
+		return Gzip([]byte(v.String()))
+	}
+	return Gzip(v.Bytes())
+}
+
+var (
+	timeType    = reflect.TypeFor[time.Time]()
+	valuerType  = reflect.TypeFor[driver.Valuer]()
+	scannerType = reflect.TypeFor[sql.Scanner]()
+)
+
 ////////////////////////////////////////////////////////////////////////////////
 
 // Fields represents the fields of a struct.
@@ -125,12 +185,26 @@ func newFields(t reflect.Type, _visited ...map[reflect.Type]bool) (*Fields, erro
 		// Whether to "promote" field: normal go embeds or opt-ins
 		promote := (opts.Contains("promote") || (sf.Anonymous && !tagged)) && ft.Kind() == reflect.Struct
 
+		gzipped := opts.Contains("gzip")
+		if gzipped && !isGzippable(ft) {
+			return nil, fmt.Errorf("gzip field %s must be a string or []byte, got %v", sf.Name, sf.Type)
+		}
+
+		scale, err := parseScale(opts, ft)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", sf.Name, err)
+		}
+
 		field := Field{
 			Column:     column,
+			Name:       sf.Name,
 			Tag:        tagged,
+			Gzip:       gzipped,
+			Scale:      scale,
 			Index:      []int{i},
 			DirectType: ft,
 			Type:       sf.Type,
+			opts:       opts,
 		}
 		if _, ok := visited[ft]; ft.Kind() == reflect.Struct && !ok {
 			// Recursively touch structs to error early.
@@ -163,8 +237,39 @@ func newFields(t reflect.Type, _visited ...map[reflect.Type]bool) (*Fields, erro
 	return &Fields{Type: t, ByColumnName: byColumnName}, nil
 }
 
-func (f *Fields) Rows(rows *sql.Rows) (*FieldsRows, error) {
-	return NewFieldsRows(f, rows)
+// Columns returns the columnar fields of the struct, in field declaration order.
+func (f *Fields) Columns() []*Field {
+	cols := make([]*Field, 0, len(f.ByColumnName))
+	for _, field := range f.ByColumnName {
+		if field.Columnar() {
+			cols = append(cols, field)
+		}
+	}
+	slices.SortFunc(cols, func(a, b *Field) int {
+		return slices.Compare(a.Index, b.Index)
+	})
+	return cols
+}
+
+func (f *Fields) Rows(rows *sql.Rows, ignore ...string) (*FieldsRows, error) {
+	return NewFieldsRows(f, rows, ignore...)
+}
+
+// Lookup returns the fields named by column or Go field name.
+func (f *Fields) Lookup(names []string) map[*Field]bool {
+	found := make(map[*Field]bool, len(names))
+	for _, name := range names {
+		if field, ok := f.ByColumnName[name]; ok {
+			found[field] = true
+			continue
+		}
+		for _, field := range f.ByColumnName {
+			if field.Name == name {
+				found[field] = true
+			}
+		}
+	}
+	return found
 }
 
 ////////////////////////////////////////////////////////////////////////////////
@@ -211,8 +316,16 @@ type targeter func(strct reflect.Value) (fieldPtr any)
 // FieldsRows handles scanning rows into given struct field.
 type FieldsRows struct {
 	*sql.Rows
+	*scanPlan
 	fields  *Fields
 	targets []any
+
+	Normalization Normalization // How to loosen scanned values, if at all
+}
+
+// scanPlan is how to scan a result's columns into a struct, shared by all results with the same
+// columns (see plans).
+type scanPlan struct {
 	// Target the fields in our final struct
 	targeters []targeter
 	// Paths to sub ptr struct fields that should be nil checked.
@@ -221,21 +334,31 @@ type FieldsRows struct {
 	zeroNilFields [][]int
 }
 
-func NewFieldsRows(f *Fields, rows *sql.Rows) (*FieldsRows, error) {
+// NewFieldsRows returns rows scanning into f's fields. Ignored fields (by column or Go field name)
+// are left as is, even if their columns are in the result.
+func NewFieldsRows(f *Fields, rows *sql.Rows, ignore ...string) (*FieldsRows, error) {
 	cols, err := rows.Columns()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get columns: %w", err)
 	}
-	sr := &FieldsRows{
-		Rows:      rows,
-		fields:    f,
-		targets:   make([]any, len(cols)),
+	plan, err := plans.get(f, cols, ignore)
+	return &FieldsRows{
+		Rows:     rows,
+		scanPlan: plan,
+		fields:   f,
+		targets:  make([]any, len(cols)),
+	}, err
+}
+
+// newScanPlan pre-calculates targeters and zero nil-checks for scanning cols into f's fields.
+func newScanPlan(f *Fields, cols []string, ignore []string) (*scanPlan, error) {
+	sr := &scanPlan{
 		targeters: make([]targeter, len(cols)),
 	}
-	// Pre-calculate targeters and zero nil-checks
 	zeroNilsByPath := map[string][]int{}
+	ignored := f.Lookup(ignore)
 	i := 0
-	err = f.traverse(cols, func(field *Field, path []int, isColumn bool) {
+	err := f.traverse(cols, func(field *Field, path []int, isColumn bool) {
 		if !isColumn {
 			if field.Type.Kind() == reflect.Pointer {
 				zeroNilsByPath[strings.Join(strings.Fields(fmt.Sprint(path)), ",")] = path
@@ -250,6 +373,10 @@ func NewFieldsRows(f *Fields, rows *sql.Rows) (*FieldsRows, error) {
 			sr.targeters[i] = func(v reflect.Value) any {
 				return new(any)
 			}
+		case ignored[field]:
+			sr.targeters[i] = func(v reflect.Value) any {
+				return discard{}
+			}
 		case len(path) == 1:
 			// Field direct on our struct, easy targeter
 			sr.targeters[i] = func(v reflect.Value) any {
@@ -276,6 +403,18 @@ func NewFieldsRows(f *Fields, rows *sql.Rows) (*FieldsRows, error) {
 				return v.Addr().Interface()
 			}
 		}
+		if field != nil && field.Scale != 0 && !ignored[field] {
+			target, scale := sr.targeters[i], field.Scale
+			sr.targeters[i] = func(v reflect.Value) any {
+				return scaleScanner{dest: reflect.ValueOf(target(v)).Elem(), scale: scale}
+			}
+		}
+		if field != nil && field.Gzip && !ignored[field] {
+			target := sr.targeters[i]
+			sr.targeters[i] = func(v reflect.Value) any {
+				return gzipScanner{dest: reflect.ValueOf(target(v)).Elem()}
+			}
+		}
 		i++
 	})
 	// Sort sub-structs by deepest path first
@@ -302,24 +441,50 @@ func (sr *FieldsRows) Scan(_val ...reflect.Value) (reflect.Value, error) {
 	for i := range sr.targeters {
 		sr.targets[i] = sr.targeters[i](val)
 	}
+	if sr.Normalization != (Normalization{}) {
+		for i, target := range sr.targets {
+			sr.targets[i] = sr.Normalization.wrap(target)
+		}
+	}
 
 	if err := sr.Rows.Scan(sr.targets...); err != nil {
 		return reflect.Value{}, fmt.Errorf("failed to scan row: %w", err)
 	}
+	if err := sr.zeroNils(val); err != nil {
+		return reflect.Value{}, err
+	}
+	return val, nil
+}
 
-	// Post process, remove any pointer structs that should be nil-d out
+// ScanValues scans already read values of cols (eg. scanned into *any) into a new value of f's
+// type, for callers that need to read a row before knowing which type it's for.
+func (f *Fields) ScanValues(cols []string, values []any, ignore ...string) (reflect.Value, error) {
+	plan, err := plans.get(f, cols, ignore)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	val := reflect.New(f.Type)
+	for i, targeter := range plan.targeters {
+		if err := Assign(targeter(val), values[i]); err != nil {
+			return reflect.Value{}, fmt.Errorf("failed to scan column %s: %w", cols[i], err)
+		}
+	}
+	if err := plan.zeroNils(val); err != nil {
+		return reflect.Value{}, err
+	}
+	return val, nil
+}
+
+// zeroNils post processes a scanned value, removing any pointer structs that should be nil-d out.
+func (sr *scanPlan) zeroNils(val reflect.Value) error {
 	for _, path := range sr.zeroNilFields {
 		v := val
-		issue := false
 		for _i, i := range path {
 			if !reflect.Indirect(v).IsValid() {
-				return reflect.Value{}, fmt.Errorf("failed to nil out field on path %v (%v)\n", path, _i)
+				return fmt.Errorf("failed to nil out field on path %v (%v)\n", path, _i)
 			}
 			v = reflect.Indirect(v).Field(i)
 		}
-		if issue {
-			continue
-		}
 		elem := v.Elem() // trust setup, will be pointers
 		if elem.IsValid() {
 			zeroer, isZeroer := elem.Interface().(isZeroer)
@@ -328,8 +493,7 @@ func (sr *FieldsRows) Scan(_val ...reflect.Value) (reflect.Value, error) {
 			}
 		}
 	}
-
-	return val, nil
+	return nil
 }
 
 ////////////////////////////////////////////////////////////////////////////////
@@ -343,6 +507,19 @@ func parseTag(tag string) (string, tagOptions) {
 	return tag, tagOptions(opt)
 }
 
+// Get returns the value of a `name=value` option.
+func (o tagOptions) Get(optionName string) (string, bool) {
+	s := string(o)
+	for s != "" {
+		var opt string
+		opt, s, _ = strings.Cut(s, ",")
+		if name, value, ok := strings.Cut(opt, "="); ok && name == optionName {
+			return value, true
+		}
+	}
+	return "", false
+}
+
 // Contains reports whether a comma-separated list of options
 // contains a particular substr flag. substr must be surrounded by a
 // string boundary or commas.
@@ -387,6 +564,11 @@ func deref(t reflect.Type) reflect.Type {
 	return t
 }
 
+// discard scans a column into nothing, without copying its value.
+type discard struct{}
+
+func (discard) Scan(any) error { return nil }
+
 type isZeroer interface {
 	IsZero() bool
 }
@@ -1,11 +1,13 @@
 package reflectp
 
 import (
+	"database/sql"
 	"reflect"
 	"testing"
 	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/greghart/powerputtygo/errcmp"
 )
 
 func TestTypeFields(t *testing.T) {
@@ -88,3 +90,58 @@ func TestTypeFields(t *testing.T) {
 		t.Errorf("TypeFields returned unexpected fields:\n%s", cmp.Diff(expected.ByColumnName, fields.ByColumnName, comparer))
 	}
 }
+
+func TestFields_Columns(t *testing.T) {
+	type Timestamps struct {
+		CreatedAt time.Time `sqlp:"created_at"`
+	}
+	type Person struct {
+		ID       int
+		Name     *string        `sqlp:"name"`
+		Nickname sql.NullString `sqlp:"nickname"`
+		Avatar   []byte         `sqlp:"avatar"`
+		Child    *Person        `sqlp:"child"`
+		Children []Person
+		Tags     map[string]string
+		Timestamps
+	}
+
+	fields, err := FieldsFactory(reflect.TypeOf(Person{}))
+	if err != nil {
+		t.Fatalf("failed to reflect fields: %v", err)
+	}
+	var columns []string
+	for _, f := range fields.Columns() {
+		columns = append(columns, f.Column)
+	}
+	expected := []string{"ID", "name", "nickname", "avatar", "created_at"}
+	if !cmp.Equal(columns, expected) {
+		t.Errorf("Columns returned unexpected columns:\n%s", cmp.Diff(expected, columns))
+	}
+}
+
+func TestParseScale(t *testing.T) {
+	tests := map[string]struct {
+		opts     tagOptions
+		t        reflect.Type
+		expected int64
+		err      string
+	}{
+		"none":             {opts: "gzip", t: reflect.TypeFor[int64]()},
+		"duration":         {opts: "duration=ms", t: reflect.TypeFor[time.Duration](), expected: int64(time.Millisecond)},
+		"scale":            {opts: "omitempty,scale=1024", t: reflect.TypeFor[uint32](), expected: 1024},
+		"unknown unit":     {opts: "duration=days", t: reflect.TypeFor[time.Duration](), err: `unknown duration unit "days"`},
+		"duration not int": {opts: "duration=s", t: reflect.TypeFor[int64](), err: "duration option needs a time.Duration, got int64"},
+		"bad scale":        {opts: "scale=0", t: reflect.TypeFor[int64](), err: `scale must be a positive integer, got "0"`},
+		"scale not int":    {opts: "scale=10", t: reflect.TypeFor[float64](), err: "scale option needs an integer type, got float64"},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			scale, err := parseScale(test.opts, test.t)
+			errcmp.MustMatch(t, err, test.err)
+			if scale != test.expected {
+				t.Errorf("expected scale %d, got %d", test.expected, scale)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package reflectp
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// durationUnits are the units of the `duration=<unit>` tag option.
+var durationUnits = map[string]time.Duration{
+	"ns": time.Nanosecond,
+	"us": time.Microsecond,
+	"ms": time.Millisecond,
+	"s":  time.Second,
+	"m":  time.Minute,
+	"h":  time.Hour,
+}
+
+// parseScale returns the scale of an integer field from its tag options, if any:
+//   - `duration=<unit>` for time.Duration fields stored as a count of unit, eg. `duration=ms`
+//   - `scale=<n>` for other integer units, eg. `scale=1024` for a size in bytes stored as KiB
+func parseScale(opts tagOptions, t reflect.Type) (int64, error) {
+	var scale int64
+	if unit, ok := opts.Get("duration"); ok {
+		d, ok := durationUnits[unit]
+		if !ok {
+			return 0, fmt.Errorf("unknown duration unit %q", unit)
+		}
+		if t != reflect.TypeFor[time.Duration]() {
+			return 0, fmt.Errorf("duration option needs a time.Duration, got %v", t)
+		}
+		scale = int64(d)
+	}
+	if s, ok := opts.Get("scale"); ok {
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("scale must be a positive integer, got %q", s)
+		}
+		if !isInteger(t.Kind()) {
+			return 0, fmt.Errorf("scale option needs an integer type, got %v", t)
+		}
+		scale = n
+	}
+	return scale, nil
+}
+
+// scaleScanner scans a column's count of units into a scaled integer field.
+type scaleScanner struct {
+	dest  reflect.Value // The field
+	scale int64
+}
+
+func (s scaleScanner) Scan(src any) error {
+	v := s.dest
+	if src == nil {
+		v.SetZero()
+		return nil
+	}
+	var n int64
+	if err := Assign(&n, src); err != nil {
+		return err
+	}
+	if v.Kind() == reflect.Pointer {
+		v.Set(reflect.New(v.Type().Elem()))
+		v = v.Elem()
+	}
+	if v.CanUint() {
+		v.SetUint(uint64(n * s.scale))
+	} else {
+		v.SetInt(n * s.scale)
+	}
+	return nil
+}
+
+func isInteger(k reflect.Kind) bool {
+	return k >= reflect.Int && k <= reflect.Uint64
+}
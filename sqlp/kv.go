@@ -0,0 +1,138 @@
+package sqlp
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+	"unicode/utf8"
+
+	"github.com/greghart/powerputtygo/queryp"
+)
+
+// DefaultKVTable is the table a KV stores its keys in, unless given another.
+const DefaultKVTable = "sqlp_kv"
+
+// KV is a small key-value store over a table, eg. for app settings and feature flags.
+// Keys can expire, after which they read as missing, and are removed by DeleteExpired.
+type KV struct {
+	db    *DB
+	table string
+}
+
+// NewKV returns a key-value store over table (defaulting to DefaultKVTable), see CreateTable.
+//
+//	settings := sqlp.NewKV(db)
+//	err := settings.Set(ctx, "maintenance", "on", time.Hour)
+//	mode, err := settings.Get(ctx, "maintenance") // ErrNotFound once expired
+func NewKV(db *DB, table ...string) *KV {
+	kv := &KV{db: db, table: DefaultKVTable}
+	if len(table) > 0 {
+		kv.table = table[0]
+	}
+	return kv
+}
+
+// CreateTable creates the key-value table if it doesn't exist.
+// Intended for migrations and tests.
+func (kv *KV) CreateTable(ctx context.Context) error {
+	_, err := kv.db.Exec(
+		ctx,
+		"CREATE TABLE IF NOT EXISTS "+kv.table+
+			" (name VARCHAR(255) PRIMARY KEY, value TEXT NOT NULL, expires_at TIMESTAMP NULL)",
+	)
+	return err
+}
+
+// Get returns the value of key, or ErrNotFound if it's missing or expired.
+func (kv *KV) Get(ctx context.Context, key string) (string, error) {
+	args := kv.args()
+	var value string
+	err := kv.db.QueryRow(
+		ctx,
+		"SELECT value FROM "+kv.table+" WHERE name = "+args.Add(key)+" AND "+kv.live(args),
+		args.Args()...,
+	).Scan(&value)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", fmt.Errorf("%w: %s", ErrNotFound, key)
+		}
+		return "", err
+	}
+	return value, nil
+}
+
+// Set sets the value of key, expiring after ttl if it's positive.
+func (kv *KV) Set(ctx context.Context, key, value string, ttl time.Duration) error {
+	var expiresAt *time.Time
+	if ttl > 0 {
+		at := time.Now().UTC().Add(ttl)
+		expiresAt = &at
+	}
+	args := kv.args()
+	q := "INSERT INTO " + kv.table + " (name, value, expires_at) VALUES (" +
+		args.Add(key) + ", " + args.Add(value) + ", " + args.Add(expiresAt) + ")"
+	switch kv.db.Dialect() {
+	case queryp.Postgres, queryp.Sqlite:
+		q += " ON CONFLICT (name) DO UPDATE SET value = excluded.value, expires_at = excluded.expires_at"
+	case queryp.MySQL:
+		q += " ON DUPLICATE KEY UPDATE value = VALUES(value), expires_at = VALUES(expires_at)"
+	default:
+		return kv.db.errUnknownDialect("KV.Set")
+	}
+	_, err := kv.db.Exec(ctx, q, args.Args()...)
+	return err
+}
+
+// Delete deletes key, if it exists.
+func (kv *KV) Delete(ctx context.Context, key string) error {
+	args := kv.args()
+	_, err := kv.db.Exec(ctx, "DELETE FROM "+kv.table+" WHERE name = "+args.Add(key), args.Args()...)
+	return err
+}
+
+// List returns the values of all unexpired keys starting with prefix.
+func (kv *KV) List(ctx context.Context, prefix string) (map[string]string, error) {
+	args := kv.args()
+	q := "SELECT name, value FROM " + kv.table + " WHERE " + kv.live(args)
+	if prefix != "" {
+		q += " AND SUBSTR(name, 1, " + args.Add(utf8.RuneCountInString(prefix)) + ") = " + args.Add(prefix)
+	}
+	ctx, cancel := kv.db.timeoutContext(ctx)
+	defer cancel()
+	rows, err := kv.db.Query(ctx, q, args.Args()...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	values := map[string]string{}
+	for rows.Next() {
+		var key, value string
+		if err := rows.Scan(&key, &value); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		values[key] = value
+	}
+	return values, rows.Err()
+}
+
+// DeleteExpired deletes expired keys, returning how many there were.
+func (kv *KV) DeleteExpired(ctx context.Context) (int64, error) {
+	args := kv.args()
+	return kv.db.ExecRows(
+		ctx,
+		"DELETE FROM "+kv.table+" WHERE expires_at <= "+args.Add(time.Now().UTC()),
+		args.Args()...,
+	)
+}
+
+func (kv *KV) args() *queryp.Args {
+	return queryp.NewArgs().WithPlaceholderer(kv.db.Dialect().Placeholderer())
+}
+
+// live returns the condition for unexpired keys.
+func (kv *KV) live(args *queryp.Args) string {
+	return "(expires_at IS NULL OR expires_at > " + args.Add(time.Now().UTC()) + ")"
+}
@@ -0,0 +1,63 @@
+package sqlp
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestKV(t *testing.T) {
+	db, ctx, cleanup := testDB(t)
+	defer cleanup()
+	db.MustExec(ctx, "DROP TABLE IF EXISTS "+DefaultKVTable)
+	defer db.MustExec(ctx, "DROP TABLE "+DefaultKVTable)
+
+	kv := NewKV(db)
+	if err := kv.CreateTable(ctx); err != nil {
+		t.Fatalf("CreateTable: %v", err)
+	}
+	for _, set := range []struct {
+		key, value string
+		ttl        time.Duration
+	}{
+		{"flags.dark_mode", "on", 0},
+		{"flags.beta", "off", 0},
+		{"flags.beta", "on", 0}, // overwrites
+		{"motd", "hello", time.Hour},
+		{"flags.expired", "on", time.Nanosecond},
+	} {
+		if err := kv.Set(ctx, set.key, set.value, set.ttl); err != nil {
+			t.Fatalf("Set(%s): %v", set.key, err)
+		}
+	}
+	time.Sleep(time.Millisecond)
+
+	if value, err := kv.Get(ctx, "flags.beta"); err != nil || value != "on" {
+		t.Errorf("expected on, got %q, %v", value, err)
+	}
+	if _, err := kv.Get(ctx, "flags.expired"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected expired key to be not found, got %v", err)
+	}
+	flags, err := kv.List(ctx, "flags.")
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if expected := map[string]string{"flags.dark_mode": "on", "flags.beta": "on"}; !cmp.Equal(expected, flags) {
+		t.Errorf("unexpected flags:\n%v", cmp.Diff(expected, flags))
+	}
+
+	if value, err := kv.Get(ctx, "motd"); err != nil || value != "hello" {
+		t.Errorf("expected unexpired key, got %q, %v", value, err)
+	}
+	if err := kv.Delete(ctx, "motd"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := kv.Get(ctx, "motd"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected deleted key to be not found, got %v", err)
+	}
+	if n, err := kv.DeleteExpired(ctx); err != nil || n != 1 {
+		t.Errorf("expected to delete 1 expired key, got %d, %v", n, err)
+	}
+}
@@ -0,0 +1,225 @@
+// Package leases provides leader election through leases stored in a table, eg. so only one
+// replica of a deployment runs a singleton background worker at a time.
+// Leases expire unless renewed, so a crashed holder is replaced after its ttl. Expiry is judged by
+// the clocks of the replicas, so they're expected to be roughly in sync (well within a ttl).
+package leases
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/greghart/powerputtygo/queryp"
+	"github.com/greghart/powerputtygo/sqlp"
+)
+
+// DefaultTable is the table leases are stored in, unless given another.
+const DefaultTable = "sqlp_leases"
+
+var (
+	// ErrHeld is returned by TryAcquire when another holder has the lease.
+	ErrHeld = errors.New("leases: lease is held")
+	// ErrLost is returned when renewing or releasing a lease that's no longer held, as it expired
+	// and was taken by another holder (or released).
+	ErrLost = errors.New("leases: lease was lost")
+)
+
+// Leases acquires leases as a single holder (eg. one per process).
+type Leases struct {
+	db     *sqlp.DB
+	table  string
+	holder string
+}
+
+// New returns leases stored in table (defaulting to DefaultTable), held by a new random holder,
+// see CreateTable.
+//
+//	lease, err := leases.New(db).TryAcquire(ctx, "billing-worker", time.Minute)
+//	if errors.Is(err, leases.ErrHeld) { ... } // another replica is the leader
+//	lease.KeepAlive(ctx, func(err error) { cancelWorker() })
+//	defer lease.Release(ctx)
+func New(db *sqlp.DB, table ...string) *Leases {
+	l := &Leases{db: db, table: DefaultTable, holder: newHolder()}
+	if len(table) > 0 {
+		l.table = table[0]
+	}
+	return l
+}
+
+func newHolder() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b) // never errors
+	return hex.EncodeToString(b)
+}
+
+// Holder returns the identifier leases are held as.
+func (l *Leases) Holder() string {
+	return l.holder
+}
+
+// CreateTable creates the leases table if it doesn't exist.
+// Intended for migrations and tests.
+func (l *Leases) CreateTable(ctx context.Context) error {
+	_, err := l.db.Exec(
+		ctx,
+		"CREATE TABLE IF NOT EXISTS "+l.table+
+			" (name VARCHAR(255) PRIMARY KEY, holder VARCHAR(255) NOT NULL, expires_at TIMESTAMP NOT NULL)",
+	)
+	return err
+}
+
+// TryAcquire acquires the named lease for ttl, unless another holder has it, returning ErrHeld.
+// Acquiring a lease already held by this holder extends it.
+func (l *Leases) TryAcquire(ctx context.Context, name string, ttl time.Duration) (*Lease, error) {
+	now := time.Now().UTC()
+	expiresAt := now.Add(ttl)
+
+	args := l.args()
+	insert := " INTO " + l.table + " (name, holder, expires_at) VALUES (" +
+		args.Add(name) + ", " + args.Add(l.holder) + ", " + args.Add(expiresAt) + ")"
+	var q string
+	switch l.db.Dialect() {
+	case queryp.Postgres, queryp.Sqlite:
+		q = "INSERT" + insert + " ON CONFLICT DO NOTHING"
+	case queryp.MySQL:
+		q = "INSERT IGNORE" + insert
+	default:
+		return nil, fmt.Errorf("leases: TryAcquire needs a dialect, and driver %T is unknown (see sqlp.WithDialect)", l.db.Driver())
+	}
+	inserted, err := l.db.ExecRows(ctx, q, args.Args()...)
+	if err != nil {
+		return nil, fmt.Errorf("leases: failed to acquire %s: %w", name, err)
+	}
+	if inserted == 0 {
+		// Take it over if it's ours or expired
+		args := l.args()
+		updated, err := l.db.ExecRows(
+			ctx,
+			"UPDATE "+l.table+" SET holder = "+args.Add(l.holder)+", expires_at = "+args.Add(expiresAt)+
+				" WHERE name = "+args.Add(name)+" AND (holder = "+args.Add(l.holder)+" OR expires_at < "+args.Add(now)+")",
+			args.Args()...,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("leases: failed to acquire %s: %w", name, err)
+		}
+		if updated == 0 {
+			return nil, fmt.Errorf("%w: %s", ErrHeld, name)
+		}
+	}
+	return &Lease{leases: l, name: name, ttl: ttl, expiresAt: expiresAt}, nil
+}
+
+func (l *Leases) args() *queryp.Args {
+	return queryp.NewArgs().WithPlaceholderer(l.db.Dialect().Placeholderer())
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// Lease is an acquired lease.
+type Lease struct {
+	leases *Leases
+	name   string
+	ttl    time.Duration
+
+	mu        sync.Mutex
+	expiresAt time.Time
+	stop      context.CancelFunc // Stops KeepAlive, if running
+}
+
+// Name returns the lease's name.
+func (l *Lease) Name() string {
+	return l.name
+}
+
+// ExpiresAt returns when the lease expires, unless renewed.
+func (l *Lease) ExpiresAt() time.Time {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.expiresAt
+}
+
+// Renew extends the lease by its ttl, or returns ErrLost if it's no longer held.
+func (l *Lease) Renew(ctx context.Context) error {
+	expiresAt := time.Now().UTC().Add(l.ttl)
+	args := l.leases.args()
+	updated, err := l.leases.db.ExecRows(
+		ctx,
+		"UPDATE "+l.leases.table+" SET expires_at = "+args.Add(expiresAt)+
+			" WHERE name = "+args.Add(l.name)+" AND holder = "+args.Add(l.leases.holder),
+		args.Args()...,
+	)
+	if err != nil {
+		return fmt.Errorf("leases: failed to renew %s: %w", l.name, err)
+	}
+	if updated == 0 {
+		return fmt.Errorf("%w: %s", ErrLost, l.name)
+	}
+	l.mu.Lock()
+	l.expiresAt = expiresAt
+	l.mu.Unlock()
+	return nil
+}
+
+// KeepAlive renews the lease in the background every third of its ttl, until ctx is done or the
+// lease is released. If the lease is lost, or renewals keep failing until it expires, onLost is
+// called (once) and renewals stop, so the holder can stop its work.
+func (l *Lease) KeepAlive(ctx context.Context, onLost func(error)) {
+	ctx, stop := context.WithCancel(ctx)
+	l.mu.Lock()
+	if l.stop != nil {
+		l.stop()
+	}
+	l.stop = stop
+	l.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(l.ttl / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			err := l.Renew(ctx)
+			if ctx.Err() != nil {
+				return
+			}
+			if err != nil && (errors.Is(err, ErrLost) || !time.Now().Before(l.ExpiresAt())) {
+				stop()
+				if onLost != nil {
+					onLost(err)
+				}
+				return
+			}
+		}
+	}()
+}
+
+// Release stops any KeepAlive and gives up the lease, so another holder can acquire it without
+// waiting for it to expire. Returns ErrLost if it was no longer held.
+func (l *Lease) Release(ctx context.Context) error {
+	l.mu.Lock()
+	if l.stop != nil {
+		l.stop()
+	}
+	l.mu.Unlock()
+
+	args := l.leases.args()
+	deleted, err := l.leases.db.ExecRows(
+		ctx,
+		"DELETE FROM "+l.leases.table+" WHERE name = "+args.Add(l.name)+" AND holder = "+args.Add(l.leases.holder),
+		args.Args()...,
+	)
+	if err != nil {
+		return fmt.Errorf("leases: failed to release %s: %w", l.name, err)
+	}
+	if deleted == 0 {
+		return fmt.Errorf("%w: %s", ErrLost, l.name)
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package leases
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/greghart/powerputtygo/sqlp"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestLeases(t *testing.T) {
+	ctx := context.Background()
+	db, err := sqlp.Open("sqlite3", filepath.Join(t.TempDir(), "leases.db"))
+	if err != nil {
+		t.Fatalf("failed to open: %v", err)
+	}
+	defer db.Close()
+	a, b := New(db), New(db)
+	if err := a.CreateTable(ctx); err != nil {
+		t.Fatalf("CreateTable: %v", err)
+	}
+	expire := func() {
+		t.Helper()
+		db.MustExec(ctx, "UPDATE sqlp_leases SET expires_at = ?", time.Now().UTC().Add(-time.Second))
+	}
+
+	lease, err := a.TryAcquire(ctx, "worker", time.Minute)
+	if err != nil {
+		t.Fatalf("TryAcquire: %v", err)
+	}
+	if _, err := b.TryAcquire(ctx, "worker", time.Minute); !errors.Is(err, ErrHeld) {
+		t.Errorf("expected ErrHeld, got %v", err)
+	}
+	if _, err := a.TryAcquire(ctx, "worker", time.Minute); err != nil {
+		t.Errorf("expected holder to reacquire, got %v", err)
+	}
+	if err := lease.Renew(ctx); err != nil {
+		t.Errorf("Renew: %v", err)
+	}
+
+	// Expired leases are taken over
+	expire()
+	taken, err := b.TryAcquire(ctx, "worker", time.Minute)
+	if err != nil {
+		t.Fatalf("expected to take over expired lease, got %v", err)
+	}
+	if err := lease.Renew(ctx); !errors.Is(err, ErrLost) {
+		t.Errorf("expected ErrLost, got %v", err)
+	}
+	if err := taken.Release(ctx); err != nil {
+		t.Errorf("Release: %v", err)
+	}
+	if err := taken.Release(ctx); !errors.Is(err, ErrLost) {
+		t.Errorf("expected ErrLost releasing twice, got %v", err)
+	}
+}
+
+func TestLease_KeepAlive(t *testing.T) {
+	ctx := context.Background()
+	db, err := sqlp.Open("sqlite3", filepath.Join(t.TempDir(), "leases.db"))
+	if err != nil {
+		t.Fatalf("failed to open: %v", err)
+	}
+	defer db.Close()
+	a, b := New(db), New(db)
+	if err := a.CreateTable(ctx); err != nil {
+		t.Fatalf("CreateTable: %v", err)
+	}
+
+	ttl := 60 * time.Millisecond
+	lease, err := a.TryAcquire(ctx, "worker", ttl)
+	if err != nil {
+		t.Fatalf("TryAcquire: %v", err)
+	}
+	lost := make(chan error, 1)
+	lease.KeepAlive(ctx, func(err error) { lost <- err })
+
+	// Renewals keep it held past its ttl
+	time.Sleep(2 * ttl)
+	if _, err := b.TryAcquire(ctx, "worker", ttl); !errors.Is(err, ErrHeld) {
+		t.Errorf("expected kept alive lease to be held, got %v", err)
+	}
+
+	// Until it's lost
+	db.MustExec(ctx, "UPDATE sqlp_leases SET holder = ?", b.Holder())
+	select {
+	case err := <-lost:
+		if !errors.Is(err, ErrLost) {
+			t.Errorf("expected ErrLost, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("expected onLost to be called")
+	}
+}
@@ -0,0 +1,130 @@
+package sqlp
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Manager holds a service's named databases (eg. "primary", "analytics", "audit"), so they're
+// configured, looked up, health checked and closed in one place.
+//
+//	m := sqlp.NewManager(sqlp.WithLogger(logger))
+//	if _, err := m.Open("primary", "postgres", primaryDSN, sqlp.WithStmtCache(256)); err != nil {
+//		...
+//	}
+//	if _, err := m.Open("analytics", "postgres", analyticsDSN, sqlp.WithDefaultTimeout(time.Minute)); err != nil {
+//		...
+//	}
+//	defer m.Close()
+//	primary, err := m.DB("primary")
+type Manager struct {
+	opts []Option // Applied to every database, before its own options
+
+	mu    sync.RWMutex
+	dbs   map[string]*DB
+	names []string // In the order added
+}
+
+// NewManager returns a manager whose databases are all configured with opts.
+func NewManager(opts ...Option) *Manager {
+	return &Manager{
+		opts: opts,
+		dbs:  make(map[string]*DB),
+	}
+}
+
+// Open opens a database and adds it under name, configured with the manager's options and then
+// opts.
+func (m *Manager) Open(name, driverName, dataSourceName string, opts ...Option) (*DB, error) {
+	db, err := sql.Open(driverName, dataSourceName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", name, err)
+	}
+	out, err := m.Add(name, db, opts...)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return out, nil
+}
+
+// Add adds an existing database under name, configured with the manager's options and then opts.
+// The manager takes ownership of it, closing it on Close.
+func (m *Manager) Add(name string, db *sql.DB, opts ...Option) (*DB, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.dbs[name]; ok {
+		return nil, fmt.Errorf("sqlp: database %s already added to manager", name)
+	}
+	out := NewDB(db, append(append([]Option{}, m.opts...), opts...)...)
+	m.dbs[name] = out
+	m.names = append(m.names, name)
+	return out, nil
+}
+
+// DB returns the database added under name, or ErrUnknownDatabase.
+func (m *Manager) DB(name string) (*DB, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	db, ok := m.dbs[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownDatabase, name)
+	}
+	return db, nil
+}
+
+// MustDB runs DB, panicking on error. Intended for wiring at startup.
+func (m *Manager) MustDB(name string) *DB {
+	db, err := m.DB(name)
+	if err != nil {
+		panic(err)
+	}
+	return db
+}
+
+// Names returns the names of the manager's databases, in the order they were added.
+func (m *Manager) Names() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return append([]string{}, m.names...)
+}
+
+// HealthCheck pings each database concurrently, returning the errors of those that fail, each
+// prefixed by its name.
+func (m *Manager) HealthCheck(ctx context.Context) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	errs := make([]error, len(m.names))
+	var wg sync.WaitGroup
+	for i, name := range m.names {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := m.dbs[name].PingContext(ctx); err != nil {
+				errs[i] = fmt.Errorf("%s: %w", name, err)
+			}
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// Close closes each database, in the reverse order they were added, returning the errors of those
+// that fail to close. The manager is empty afterwards.
+func (m *Manager) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var errs []error
+	for i := len(m.names) - 1; i >= 0; i-- {
+		name := m.names[i]
+		if err := m.dbs[name].Close(); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+	}
+	m.dbs = make(map[string]*DB)
+	m.names = nil
+	return errors.Join(errs...)
+}
@@ -0,0 +1,74 @@
+package sqlp
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/greghart/powerputtygo/errcmp"
+	"github.com/greghart/powerputtygo/queryp"
+)
+
+func TestManager(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	m := NewManager(WithDialect(queryp.Sqlite))
+	primary, err := m.Open("primary", "sqlite3", filepath.Join(dir, "primary.db"))
+	errcmp.MustMatch(t, err, "")
+	analytics, err := m.Open("analytics", "sqlite3", filepath.Join(dir, "analytics.db"), WithMaxRows(1))
+	errcmp.MustMatch(t, err, "")
+
+	t.Run("lookup", func(t *testing.T) {
+		db, err := m.DB("analytics")
+		if err != nil || db != analytics {
+			t.Errorf("expected analytics, got %v, %v", db, err)
+		}
+		_, err = m.DB("audit")
+		errcmp.MustMatch(t, err, "unknown database: audit")
+		if !errors.Is(err, ErrUnknownDatabase) {
+			t.Errorf("expected ErrUnknownDatabase, got %v", err)
+		}
+		if expected := []string{"primary", "analytics"}; !cmp.Equal(m.Names(), expected) {
+			t.Errorf("unexpected names:\n%v", cmp.Diff(expected, m.Names()))
+		}
+		_, err = m.Open("primary", "sqlite3", filepath.Join(dir, "other.db"))
+		errcmp.MustMatch(t, err, "database primary already added")
+	})
+
+	t.Run("options", func(t *testing.T) {
+		if primary.Dialect() != queryp.Sqlite || analytics.Dialect() != queryp.Sqlite {
+			t.Errorf("expected shared options on every database")
+		}
+		const query = "SELECT 1 UNION ALL SELECT 2"
+		if _, err := SelectScalars[int](ctx, primary, query); err != nil {
+			t.Errorf("expected primary without max rows, got %v", err)
+		}
+		_, err := SelectScalars[int](ctx, analytics, query)
+		errcmp.MustMatch(t, err, "more than 1 rows")
+	})
+
+	t.Run("health check", func(t *testing.T) {
+		errcmp.MustMatch(t, m.HealthCheck(ctx), "")
+
+		closed, err := sql.Open("sqlite3", filepath.Join(dir, "audit.db"))
+		errcmp.MustMatch(t, err, "")
+		closed.Close()
+		_, err = m.Add("audit", closed)
+		errcmp.MustMatch(t, err, "")
+		errcmp.MustMatch(t, m.HealthCheck(ctx), "audit: sql: database is closed")
+	})
+
+	t.Run("close", func(t *testing.T) {
+		errcmp.MustMatch(t, m.Close(), "")
+		if err := primary.PingContext(ctx); err == nil {
+			t.Errorf("expected primary closed")
+		}
+		if len(m.Names()) != 0 {
+			t.Errorf("expected manager emptied, got %v", m.Names())
+		}
+	})
+}
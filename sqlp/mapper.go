@@ -31,3 +31,25 @@ func MergeMappers[E, T any](m1 Mapper[E], m2 Mapper[T], ns string, get func(*E)
 	}
 	return out
 }
+
+// TypedMapper powers typed mappings of column names to struct fields, for high row count scans.
+// Columns are scanned into typed buffers that are reused across rows, then assigned to the entity,
+// so scanning a row doesn't set up a target per column (see TypedScanner).
+type TypedMapper[E any] map[string]TypedMapping[E]
+
+// TypedMapping is a typed mapping of a column, see Typed.
+// It returns a new buffer to scan the column into, and a function assigning the buffer to an entity.
+type TypedMapping[E any] func() (target any, assign func(e *E))
+
+// Typed returns a typed mapping that scans a column into a T, then sets it on the entity.
+//
+//	mapper := sqlp.TypedMapper[person]{
+//		"id":         sqlp.Typed(func(p *person, v int64) { p.ID = v }),
+//		"first_name": sqlp.Typed(func(p *person, v string) { p.FirstName = v }),
+//	}
+func Typed[E, T any](set func(e *E, v T)) TypedMapping[E] {
+	return func() (any, func(e *E)) {
+		buffer := new(T)
+		return buffer, func(e *E) { set(e, *buffer) }
+	}
+}
@@ -0,0 +1,196 @@
+package sqlp
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// SelectMaps runs a query and scans each row into a map by column name, for ad hoc tooling without
+// a struct. Values are as the driver returns them, except text the driver returns as bytes (eg.
+// MySQL) is converted by its column's database type, to integers, floats, bools or strings.
+func (db *DB) SelectMaps(ctx context.Context, query string, args ...any) ([]map[string]any, error) {
+	ctx, cancel := db.timeoutContext(ctx)
+	defer cancel()
+	rows, err := db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	scan, err := mapScanner(rows)
+	if err != nil {
+		return nil, err
+	}
+	limit := db.rowLimit(ctx)
+	var out []map[string]any
+	for rows.Next() {
+		if limit.exceeded(int64(len(out))) {
+			if limit.truncate {
+				break
+			}
+			return nil, limit.err()
+		}
+		m, err := scan()
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, m)
+		if err := checkSelectContext(ctx, int64(len(out))); err != nil {
+			return nil, err
+		}
+	}
+	db.captureScanned(ctx, rows, int64(len(out)))
+
+	return out, rows.Err()
+}
+
+// GetMap runs a query and scans the first row into a map by column name, see SelectMaps.
+// Returns a nil map if there are no rows.
+func (db *DB) GetMap(ctx context.Context, query string, args ...any) (map[string]any, error) {
+	ctx, cancel := db.timeoutContext(ctx)
+	defer cancel()
+	rows, err := db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	scan, err := mapScanner(rows)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]any
+	if rows.Next() {
+		if m, err = scan(); err != nil {
+			return nil, err
+		}
+		db.captureScanned(ctx, rows, 1)
+	}
+	return m, rows.Err()
+}
+
+// QueryJSON runs a query and streams its rows to w as a JSON array of objects by column name, in
+// the order of the query's columns, eg. for quick HTTP endpoints. Values are as SelectMaps scans
+// them, so binary values are base64 encoded. Rows are written as they're scanned, so on error w
+// may have been written a partial array.
+func (db *DB) QueryJSON(ctx context.Context, w io.Writer, query string, args ...any) error {
+	ctx, cancel := db.timeoutContext(ctx)
+	defer cancel()
+	rows, err := db.Query(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	cols, err := rows.Columns()
+	if err != nil {
+		return fmt.Errorf("failed to get columns: %w", err)
+	}
+	keys := make([][]byte, len(cols))
+	for i, col := range cols {
+		if keys[i], err = json.Marshal(col); err != nil {
+			return fmt.Errorf("failed to encode column %s: %w", col, err)
+		}
+	}
+	scan, err := mapScanner(rows)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte('[')
+	var n int64
+	for rows.Next() {
+		m, err := scan()
+		if err != nil {
+			return err
+		}
+		if n > 0 {
+			buf.WriteByte(',')
+		}
+		buf.WriteByte('{')
+		for i, col := range cols {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			buf.Write(keys[i])
+			buf.WriteByte(':')
+			v, err := json.Marshal(m[col])
+			if err != nil {
+				return fmt.Errorf("failed to encode column %s: %w", col, err)
+			}
+			buf.Write(v)
+		}
+		buf.WriteByte('}')
+		if _, err := w.Write(buf.Bytes()); err != nil {
+			return fmt.Errorf("failed to write row: %w", err)
+		}
+		buf.Reset()
+		n++
+		if err := checkSelectContext(ctx, n); err != nil {
+			return err
+		}
+	}
+	db.captureScanned(ctx, rows, n)
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if _, err := w.Write(append(buf.Bytes(), ']')); err != nil {
+		return fmt.Errorf("failed to write row: %w", err)
+	}
+	return nil
+}
+
+// mapScanner returns a function scanning the current row of rows into a map.
+func mapScanner(rows *sql.Rows) (func() (map[string]any, error), error) {
+	cols, err := columnInfo(rows)
+	if err != nil {
+		return nil, err
+	}
+	values := make([]any, len(cols))
+	targets := make([]any, len(cols))
+	for i := range values {
+		targets[i] = &values[i]
+	}
+	return func() (map[string]any, error) {
+		if err := rows.Scan(targets...); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		m := make(map[string]any, len(cols))
+		for i, col := range cols {
+			v, err := mapValue(col, values[i])
+			if err != nil {
+				return nil, fmt.Errorf("failed to scan column %s: %w", col.Name, err)
+			}
+			m[col.Name] = v
+		}
+		return m, nil
+	}, nil
+}
+
+// mapValue converts a text value returned as bytes by its column's database type.
+func mapValue(col Column, v any) (any, error) {
+	b, ok := v.([]byte)
+	if !ok {
+		return v, nil
+	}
+	t := strings.ToUpper(col.DatabaseTypeName)
+	switch {
+	case strings.Contains(t, "BLOB"), strings.Contains(t, "BINARY"), t == "BYTEA", t == "BIT":
+		return b, nil
+	case strings.Contains(t, "INT"):
+		return strconv.ParseInt(string(b), 10, 64)
+	case t == "FLOAT", t == "DOUBLE", t == "REAL":
+		return strconv.ParseFloat(string(b), 64)
+	case strings.HasPrefix(t, "BOOL"):
+		return strconv.ParseBool(string(b))
+	}
+	return string(b), nil // including decimals, to keep their precision
+}
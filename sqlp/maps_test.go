@@ -0,0 +1,122 @@
+package sqlp
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/greghart/powerputtygo/errcmp"
+)
+
+func TestDB_SelectMaps(t *testing.T) {
+	db, ctx, cleanup := testDB(t)
+	defer cleanup()
+
+	grandparent := grandchildrenSetup(ctx, db)
+
+	t.Run("select maps", func(t *testing.T) {
+		maps, err := db.SelectMaps(ctx, "SELECT id, first_name, parent_id FROM people ORDER BY id LIMIT 2")
+		if err != nil {
+			t.Fatalf("failed to select: %v", err)
+		}
+		expected := []map[string]any{
+			{"id": grandparent.ID, "first_name": "John", "parent_id": nil},
+			{"id": grandparent.Child.ID, "first_name": "Lil Johnnie", "parent_id": grandparent.ID},
+		}
+		if diff := cmp.Diff(expected, maps); diff != "" {
+			t.Errorf("selected maps unexpected:\n%v", diff)
+		}
+	})
+
+	t.Run("get map", func(t *testing.T) {
+		m, err := db.GetMap(ctx, "SELECT first_name, last_name FROM people WHERE id = ?", grandparent.ID)
+		if err != nil {
+			t.Fatalf("failed to get: %v", err)
+		}
+		expected := map[string]any{"first_name": "John", "last_name": "Doe"}
+		if diff := cmp.Diff(expected, m); diff != "" {
+			t.Errorf("gotten map unexpected:\n%v", diff)
+		}
+	})
+
+	t.Run("get map without rows", func(t *testing.T) {
+		m, err := db.GetMap(ctx, "SELECT first_name FROM people WHERE id = ?", -1)
+		if err != nil || m != nil {
+			t.Errorf("expected nil map, got %v, %v", m, err)
+		}
+	})
+
+	t.Run("bad query -> err", func(t *testing.T) {
+		_, err := db.SelectMaps(ctx, "SELECT nope FROM people")
+		errcmp.MustMatch(t, err, "no such column: nope")
+	})
+}
+
+func TestDB_QueryJSON(t *testing.T) {
+	db, ctx, cleanup := testDB(t)
+	defer cleanup()
+
+	grandparent := grandchildrenSetup(ctx, db)
+
+	t.Run("query json", func(t *testing.T) {
+		var b strings.Builder
+		err := db.QueryJSON(
+			ctx, &b, "SELECT id, first_name, parent_id FROM people ORDER BY id LIMIT 2",
+		)
+		if err != nil {
+			t.Fatalf("failed to query: %v", err)
+		}
+		expected := fmt.Sprintf(
+			`[{"id":%d,"first_name":"John","parent_id":null},`+
+				`{"id":%d,"first_name":"Lil Johnnie","parent_id":%d}]`,
+			grandparent.ID, grandparent.Child.ID, grandparent.ID,
+		)
+		if diff := cmp.Diff(expected, b.String()); diff != "" {
+			t.Errorf("queried json unexpected:\n%v", diff)
+		}
+	})
+
+	t.Run("query json without rows", func(t *testing.T) {
+		var b strings.Builder
+		if err := db.QueryJSON(ctx, &b, "SELECT id FROM people WHERE id = ?", -1); err != nil {
+			t.Fatalf("failed to query: %v", err)
+		}
+		if b.String() != "[]" {
+			t.Errorf("expected empty array, got %s", b.String())
+		}
+	})
+
+	t.Run("bad query -> err", func(t *testing.T) {
+		var b strings.Builder
+		err := db.QueryJSON(ctx, &b, "SELECT nope FROM people")
+		errcmp.MustMatch(t, err, "no such column: nope")
+	})
+}
+
+func TestMapValue(t *testing.T) {
+	tests := map[string]struct {
+		typeName string
+		v        any
+		expected any
+	}{
+		"not bytes":  {typeName: "INT", v: int64(1), expected: int64(1)},
+		"int text":   {typeName: "BIGINT", v: []byte("42"), expected: int64(42)},
+		"float text": {typeName: "DOUBLE", v: []byte("1.5"), expected: 1.5},
+		"bool text":  {typeName: "BOOLEAN", v: []byte("true"), expected: true},
+		"decimal":    {typeName: "DECIMAL", v: []byte("1.10"), expected: "1.10"},
+		"varchar":    {typeName: "VARCHAR", v: []byte("hi"), expected: "hi"},
+		"blob":       {typeName: "BLOB", v: []byte{0xff}, expected: []byte{0xff}},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := mapValue(Column{DatabaseTypeName: test.typeName}, test.v)
+			if err != nil {
+				t.Fatalf("failed to convert: %v", err)
+			}
+			if diff := cmp.Diff(test.expected, got); diff != "" {
+				t.Errorf("value unexpected:\n%v", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package sqlp
+
+import (
+	"context"
+	"fmt"
+)
+
+// MaxRowsOption configures what happens to results over the max rows, see WithMaxRows.
+type MaxRowsOption int
+
+const (
+	// TruncateRows returns the first max rows of larger results, rather than erroring.
+	TruncateRows MaxRowsOption = iota + 1
+)
+
+// rowLimit is the max rows of a select, see WithMaxRows.
+type rowLimit struct {
+	max      int64 // No limit if 0
+	truncate bool
+}
+
+func newRowLimit(max int64, opts []MaxRowsOption) rowLimit {
+	limit := rowLimit{max: max}
+	for _, opt := range opts {
+		if opt == TruncateRows {
+			limit.truncate = true
+		}
+	}
+	return limit
+}
+
+// exceeded returns whether there's another row after n rows that's over the limit.
+func (l rowLimit) exceeded(n int64) bool {
+	return l.max > 0 && n >= l.max
+}
+
+func (l rowLimit) err() error {
+	return fmt.Errorf("%w: query returned more than %d rows", ErrTooManyRows, l.max)
+}
+
+const maxRowsKey = contextKeyType("sqlp.maxRows")
+
+// MaxRows returns a context where selects return at most max rows, overriding the DB's (see
+// WithMaxRows), eg. MaxRows(ctx, 0) to lift the limit for a known large export.
+func MaxRows(ctx context.Context, max int64, opts ...MaxRowsOption) context.Context {
+	return context.WithValue(ctx, maxRowsKey, newRowLimit(max, opts))
+}
+
+// rowLimit returns the max rows of selects for context.
+func (db *DB) rowLimit(ctx context.Context) rowLimit {
+	if limit, ok := ctx.Value(maxRowsKey).(rowLimit); ok {
+		return limit
+	}
+	return db.maxRows
+}
@@ -0,0 +1,58 @@
+package sqlp
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/greghart/powerputtygo/errcmp"
+)
+
+func TestWithMaxRows(t *testing.T) {
+	db, ctx, cleanup := testDB(t)
+	defer cleanup()
+	grandchildrenSetup(ctx, db)
+
+	t.Run("over max -> err", func(t *testing.T) {
+		db := NewDB(db.DB, WithMaxRows(2))
+		var people []person
+		err := db.Select(ctx, &people, "SELECT * FROM people")
+		errcmp.MustMatch(t, err, "too many rows: query returned more than 2 rows")
+		if !errors.Is(err, ErrTooManyRows) {
+			t.Errorf("expected ErrTooManyRows, got %v", err)
+		}
+
+		_, err = NewRepository[person](db, "people").Select(ctx, "SELECT * FROM people")
+		errcmp.MustMatch(t, err, "more than 2 rows")
+		_, err = db.SelectMaps(ctx, "SELECT * FROM people")
+		errcmp.MustMatch(t, err, "more than 2 rows")
+		_, err = SelectScalars[string](ctx, db, "SELECT first_name FROM people")
+		errcmp.MustMatch(t, err, "more than 2 rows")
+	})
+
+	t.Run("at max", func(t *testing.T) {
+		db := NewDB(db.DB, WithMaxRows(3))
+		people, err := Select[person](ctx, db, "SELECT * FROM people")
+		if err != nil || len(people) != 3 {
+			t.Errorf("expected 3 people, got %d, %v", len(people), err)
+		}
+	})
+
+	t.Run("truncate", func(t *testing.T) {
+		db := NewDB(db.DB, WithMaxRows(2, TruncateRows))
+		names, err := SelectScalars[string](ctx, db, "SELECT first_name FROM people ORDER BY id")
+		errcmp.MustMatch(t, err, "")
+		if len(names) != 2 || names[0] != "John" || names[1] != "Lil Johnnie" {
+			t.Errorf("expected first 2 names, got %v", names)
+		}
+	})
+
+	t.Run("per call", func(t *testing.T) {
+		db := NewDB(db.DB, WithMaxRows(2))
+		people, err := Select[person](MaxRows(ctx, 0), db, "SELECT * FROM people")
+		if err != nil || len(people) != 3 {
+			t.Errorf("expected limit lifted, got %d, %v", len(people), err)
+		}
+		_, err = Select[person](MaxRows(ctx, 1), NewDB(db.DB), "SELECT * FROM people")
+		errcmp.MustMatch(t, err, "more than 1 rows")
+	})
+}
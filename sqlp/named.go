@@ -0,0 +1,90 @@
+package sqlp
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+
+	"github.com/greghart/powerputtygo/queryp"
+	"github.com/greghart/powerputtygo/sqlp/internal/reflectp"
+)
+
+// NamedExec runs Exec with a query using `:name` params, bound from params -- a map, or a struct
+// whose params are named by its sql tags.
+//
+//	db.NamedExec(ctx, "UPDATE people SET first_name = :first_name WHERE id = :id", p)
+func (db *DB) NamedExec(ctx context.Context, query string, params any) (sql.Result, error) {
+	q, args, err := db.named(query, params)
+	if err != nil {
+		return nil, err
+	}
+	return db.Exec(ctx, q, args...)
+}
+
+// NamedQuery runs Query with a query using `:name` params, see NamedExec.
+func (db *DB) NamedQuery(ctx context.Context, query string, params any) (*sql.Rows, error) {
+	q, args, err := db.named(query, params)
+	if err != nil {
+		return nil, err
+	}
+	return db.Query(ctx, q, args...)
+}
+
+// NamedSelect runs Select with a query using `:name` params, see NamedExec.
+func (db *DB) NamedSelect(ctx context.Context, dest any, query string, params any) error {
+	q, args, err := db.named(query, params)
+	if err != nil {
+		return err
+	}
+	return db.Select(ctx, dest, q, args...)
+}
+
+// named binds a named query's params with the database's placeholders.
+func (db *DB) named(query string, params any) (string, []any, error) {
+	m, err := namedParams(params)
+	if err != nil {
+		return "", nil, err
+	}
+	q, args := queryp.Named(query).Params(m).WithPlaceholderer(db.Dialect().Placeholderer()).Execute()
+	return q, args, nil
+}
+
+// namedParams returns params by name, from a map or a struct's columns.
+func namedParams(params any) (map[string]any, error) {
+	if m, ok := params.(map[string]any); ok {
+		return m, nil
+	}
+	m := map[string]any{}
+	if params == nil {
+		return m, nil
+	}
+	v := reflect.Indirect(reflect.ValueOf(params))
+	switch {
+	case v.Kind() == reflect.Map && v.Type().Key().Kind() == reflect.String:
+		for _, key := range v.MapKeys() {
+			m[key.String()] = v.MapIndex(key).Interface()
+		}
+	case v.Kind() == reflect.Struct:
+		fields, err := reflectp.FieldsFactory(v.Type())
+		if err != nil {
+			return nil, fmt.Errorf("failed to reflect fields for %v: %w", v.Type(), err)
+		}
+		for column, field := range fields.ByColumnName {
+			if !field.Columnar() {
+				continue
+			}
+			fv, err := v.FieldByIndexErr(field.Index)
+			if err != nil || !fv.IsValid() {
+				m[column] = nil // nil embedded pointer
+				continue
+			}
+			if m[column], err = field.Value(fv); err != nil {
+				return nil, fmt.Errorf("sqlp: named param %s: %w", column, err)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("sqlp: named params must be a map or struct, got %T", params)
+	}
+	return m, nil
+}
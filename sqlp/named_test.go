@@ -0,0 +1,69 @@
+package sqlp
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/greghart/powerputtygo/errcmp"
+)
+
+func TestDB_Named(t *testing.T) {
+	db, ctx, cleanup := testDB(t)
+	defer cleanup()
+
+	grandparent := grandchildrenSetup(ctx, db)
+
+	t.Run("exec with struct params", func(t *testing.T) {
+		p := person{ID: grandparent.ID, FirstName: "Johnny"}
+		_, err := db.NamedExec(ctx, "UPDATE people SET first_name = :first_name WHERE id = :id", p)
+		if err != nil {
+			t.Fatalf("failed to exec: %v", err)
+		}
+		var name string
+		if err := db.QueryRow(ctx, "SELECT first_name FROM people WHERE id = ?", grandparent.ID).Scan(&name); err != nil {
+			t.Fatalf("failed to query: %v", err)
+		}
+		if name != "Johnny" {
+			t.Errorf("expected Johnny, got %v", name)
+		}
+	})
+
+	t.Run("select with map params", func(t *testing.T) {
+		var people []person
+		err := db.NamedSelect(ctx, &people, "SELECT id, first_name FROM people WHERE id IN (:child, :grandchild) ORDER BY id", map[string]any{
+			"child":      grandparent.Child.ID,
+			"grandchild": grandparent.Child.Child.ID,
+		})
+		if err != nil {
+			t.Fatalf("failed to select: %v", err)
+		}
+		expected := []person{
+			{ID: grandparent.Child.ID, FirstName: "Lil Johnnie"},
+			{ID: grandparent.Child.Child.ID, FirstName: "Lil Lil Johnnie"},
+		}
+		if !cmp.Equal(people, expected, personComparer) {
+			t.Errorf("selected people unexpected:\n%v", cmp.Diff(expected, people, personComparer))
+		}
+	})
+
+	t.Run("query with typed map params", func(t *testing.T) {
+		rows, err := db.NamedQuery(ctx, "SELECT COUNT(*) FROM people WHERE last_name = :last_name", map[string]string{"last_name": "Doe"})
+		if err != nil {
+			t.Fatalf("failed to query: %v", err)
+		}
+		defer rows.Close()
+		var n int
+		rows.Next()
+		if err := rows.Scan(&n); err != nil {
+			t.Fatalf("failed to scan: %v", err)
+		}
+		if n != 3 {
+			t.Errorf("expected 3 people, got %v", n)
+		}
+	})
+
+	t.Run("unsupported params -> err", func(t *testing.T) {
+		_, err := db.NamedExec(ctx, "DELETE FROM people WHERE id = :id", 1)
+		errcmp.MustMatch(t, err, "named params must be a map or struct, got int")
+	})
+}
@@ -0,0 +1,185 @@
+package sqlp
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/greghart/powerputtygo/queryp"
+)
+
+// Option configures optional behavior of a DB.
+type Option func(*DB)
+
+// Logger is the minimal logging interface used by sqlp, satisfied by *log.Logger.
+type Logger interface {
+	Printf(format string, v ...any)
+}
+
+// WithLogger sets the logger used for any warnings sqlp reports (defaults to log.Default()).
+func WithLogger(l Logger) Option {
+	return func(db *DB) {
+		if l != nil {
+			db.logger = l
+		}
+	}
+}
+
+// WithRowsLeakDetection enables a debug check that logs the call site of any *sql.Rows returned by
+// Query that are garbage collected without being closed or fully iterated.
+// Leaked rows hold onto a connection, so enough of them will exhaust the pool.
+// Note this relies on finalizers, so detection happens whenever GC runs, and only for rows that
+// aren't otherwise kept alive (eg. by a cancelable context). Not recommended for production.
+func WithRowsLeakDetection() Option {
+	return func(db *DB) {
+		db.detectRowsLeaks = true
+	}
+}
+
+// WithTxLeakDetection enables a debug check that logs where a transaction began (through RunInTx or
+// Begin) when it's been open longer than threshold, or when an explicit transaction's context is
+// done before it was committed or rolled back.
+// Long lived transactions hold locks and connections, and are a common cause of incidents.
+func WithTxLeakDetection(threshold time.Duration) Option {
+	return func(db *DB) {
+		db.txLeakThreshold = threshold
+	}
+}
+
+// WithDryRun logs Exec-class statements instead of executing them, see DryRun.
+func WithDryRun() Option {
+	return func(db *DB) {
+		db.dryRun = true
+	}
+}
+
+// WithDefaultTimeout sets a timeout applied to each statement (Exec, Query and QueryRow, and so the
+// helpers built on them) whose context has no deadline, so call sites needn't remember to set one.
+// Contexts with a deadline keep theirs, eg. for a longer running report. Rows from Query (and a row
+// from QueryRow) hold the timeout until they're closed and collected, so close them promptly.
+func WithDefaultTimeout(timeout time.Duration) Option {
+	return func(db *DB) {
+		db.defaultTimeout = timeout
+	}
+}
+
+// WithDialect sets the SQL dialect of the database, when it can't be detected from the driver.
+func WithDialect(d queryp.Dialect) Option {
+	return func(db *DB) {
+		db.dialect = d
+	}
+}
+
+// WithSchema sets the default schema of repositories, which qualify their table with it in
+// generated SQL (eg. "analytics"."people"). See Repository.WithSchema.
+func WithSchema(schema string) Option {
+	return func(db *DB) {
+		db.schema = schema
+	}
+}
+
+// WithDeleteAllOutsideTests allows Repository.DeleteAll and Truncate to run outside of tests.
+// They still require IUnderstandThisDeletesEverything.
+func WithDeleteAllOutsideTests() Option {
+	return func(db *DB) {
+		db.deleteAllOutsideTests = true
+	}
+}
+
+// WithIdempotencyTable sets the table idempotency keys are recorded in (defaults to
+// DefaultIdempotencyTable), see RunIdempotent.
+func WithIdempotencyTable(table string) Option {
+	return func(db *DB) {
+		db.idempotencyTableName = table
+	}
+}
+
+// WithTxHook sets a hook called with how each RunInTx transaction ended, eg. so observability layers
+// can tell commit failures from business rollbacks. Transactions joined from the context are
+// reported by their owner instead.
+func WithTxHook(hook func(ctx context.Context, outcome TxOutcome, err error)) Option {
+	return func(db *DB) {
+		db.txHook = hook
+	}
+}
+
+// WithPreCommitHook adds a hook run in each RunInTx transaction that's about to commit, after its
+// callback returned nil, eg. for invariant checks across a transaction's writes. Returning an error
+// rolls the transaction back instead. See BeforeCommit to register hooks per transaction.
+func WithPreCommitHook(hook func(ctx context.Context) error) Option {
+	return func(db *DB) {
+		db.preCommitHooks = append(db.preCommitHooks, hook)
+	}
+}
+
+// WithInflector sets how repositories infer their table from their entity's type name, when it's
+// neither given to NewRepository nor declared on the entity, eg. WithInflector(sqlp.Pluralize).
+func WithInflector(inflector func(typeName string) string) Option {
+	return func(db *DB) {
+		db.inflector = inflector
+	}
+}
+
+// WithSkipReadOnlySavepoints runs RunInSavepoint callbacks declared read-only (see ReadOnly)
+// directly in the surrounding transaction, without a savepoint, eg. for read-heavy nested helpers.
+// Note on Postgres, a failed statement aborts the whole transaction unless it ran in a savepoint, so
+// only declare callbacks read-only if their errors aren't recovered from.
+func WithSkipReadOnlySavepoints() Option {
+	return func(db *DB) {
+		db.skipReadOnlySavepoints = true
+	}
+}
+
+// WithMaxRows makes selects that scan results into memory (Select, repository selects, SelectMaps,
+// SelectScalars and SelectVariants) return ErrTooManyRows when their query returns more than max
+// rows, or with TruncateRows, just the first max rows. This guards services against unbounded
+// queries flooding memory. See MaxRows to set it per call.
+func WithMaxRows(max int64, opts ...MaxRowsOption) Option {
+	return func(db *DB) {
+		db.maxRows = newRowLimit(max, opts)
+	}
+}
+
+// Normalization loosens how driver values scan into entity fields, see WithNormalization.
+type Normalization int
+
+const (
+	// BoolAsInt scans any integer (including as text) into bool fields, non-zero being true, eg. for
+	// SQLite booleans stored as integers other than 0 and 1.
+	BoolAsInt Normalization = iota + 1
+	// BytesAsString scans text returned as []byte into interface (any) fields as a string.
+	BytesAsString
+)
+
+// WithNormalization loosens how reflective scanning converts driver values, so entity fields can be
+// plain bools and strings across drivers.
+func WithNormalization(normalizations ...Normalization) Option {
+	return func(db *DB) {
+		for _, n := range normalizations {
+			switch n {
+			case BoolAsInt:
+				db.normalization.BoolAsInt = true
+			case BytesAsString:
+				db.normalization.BytesAsString = true
+			}
+		}
+	}
+}
+
+// WithStmtCache caches up to size prepared statements, so repeated queries (eg. a repository's
+// generated ones) are only prepared once per connection, rather than on every Exec and Query.
+// See DB.StmtCacheStats for its hit rate.
+func WithStmtCache(size int) Option {
+	return func(db *DB) {
+		db.stmts = newStmtCache(size)
+	}
+}
+
+// logf logs through the configured logger, falling back to the default logger.
+func (db *DB) logf(format string, v ...any) {
+	if db.logger == nil {
+		log.Printf(format, v...)
+		return
+	}
+	db.logger.Printf(format, v...)
+}
@@ -0,0 +1,332 @@
+package sqlp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/greghart/powerputtygo/errcmp"
+)
+
+func TestWithRowsLeakDetection(t *testing.T) {
+	logger := &testLogger{}
+	db, ctx, cleanup := testDB(t)
+	defer cleanup()
+	db = NewDB(db.DB, WithLogger(logger), WithRowsLeakDetection())
+	grandchildrenSetup(ctx, db)
+
+	// Closed and fully iterated rows are not leaks
+	rows, err := db.Query(context.Background(), "SELECT id FROM people")
+	if err != nil {
+		t.Fatalf("failed to query: %v", err)
+	}
+	rows.Close()
+	rows, err = db.Query(context.Background(), "SELECT id FROM people")
+	if err != nil {
+		t.Fatalf("failed to query: %v", err)
+	}
+	for rows.Next() {
+	}
+	rows = nil // nolint:ineffassign
+	waitForGC(func() bool { return false })
+	if logs := logger.String(); logs != "" {
+		t.Fatalf("expected no leaks reported, got %v", logs)
+	}
+
+	// Note a background context, otherwise database/sql itself keeps rows alive until ctx is done.
+	leakRows(t, db)
+	waitForGC(func() bool { return logger.String() != "" })
+	if logs := logger.String(); !strings.Contains(logs, "rows were never closed") || !strings.Contains(logs, "leakRows") {
+		t.Errorf("expected leak reported from leakRows, got %v", logs)
+	}
+}
+
+func TestWithTxLeakDetection(t *testing.T) {
+	db, ctx, cleanup := testDB(t)
+	defer cleanup()
+
+	t.Run("long RunInTx", func(t *testing.T) {
+		logger := &testLogger{}
+		db := NewDB(db.DB, WithLogger(logger), WithTxLeakDetection(10*time.Millisecond))
+		err := db.RunInTx(ctx, func(ctx context.Context) error {
+			time.Sleep(50 * time.Millisecond)
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("failed to run in tx: %v", err)
+		}
+		if logs := logger.String(); !strings.Contains(logs, "transaction open longer than 10ms") ||
+			!strings.Contains(logs, "TestWithTxLeakDetection") {
+			t.Errorf("expected long transaction reported, got %v", logs)
+		}
+	})
+
+	t.Run("quick transactions", func(t *testing.T) {
+		logger := &testLogger{}
+		db := NewDB(db.DB, WithLogger(logger), WithTxLeakDetection(10*time.Millisecond))
+		if err := db.RunInTx(ctx, func(ctx context.Context) error { return nil }); err != nil {
+			t.Fatalf("failed to run in tx: %v", err)
+		}
+		txCtx, cancel := context.WithCancel(ctx)
+		tx, err := db.Begin(txCtx, nil)
+		if err != nil {
+			t.Fatalf("failed to begin: %v", err)
+		}
+		if err := tx.Commit(); err != nil {
+			t.Fatalf("failed to commit: %v", err)
+		}
+		cancel()
+		time.Sleep(30 * time.Millisecond)
+		if logs := logger.String(); logs != "" {
+			t.Errorf("expected nothing reported, got %v", logs)
+		}
+	})
+
+	t.Run("unreleased Begin", func(t *testing.T) {
+		logger := &testLogger{}
+		db := NewDB(db.DB, WithLogger(logger), WithTxLeakDetection(time.Minute))
+		txCtx, cancel := context.WithCancel(ctx)
+		if _, err := db.Begin(txCtx, nil); err != nil {
+			t.Fatalf("failed to begin: %v", err)
+		}
+		cancel()
+		for i := 0; i < 20 && logger.String() == ""; i++ {
+			time.Sleep(5 * time.Millisecond)
+		}
+		if logs := logger.String(); !strings.Contains(logs, "context done with unreleased transaction") {
+			t.Errorf("expected unreleased transaction reported, got %v", logs)
+		}
+	})
+}
+
+func TestWithTxHook(t *testing.T) {
+	ctx := context.Background()
+	// Deferred foreign keys are checked on commit, to fail commits
+	sqlDB, err := Open("sqlite3", filepath.Join(t.TempDir(), "hook.db")+"?_foreign_keys=on")
+	errcmp.MustMatch(t, err, "")
+	defer sqlDB.Close()
+	sqlDB.MustExec(ctx, `
+		CREATE TABLE parents (id INTEGER PRIMARY KEY);
+		CREATE TABLE children (id INTEGER PRIMARY KEY, parent_id INTEGER REFERENCES parents (id) DEFERRABLE INITIALLY DEFERRED)
+	`)
+	errCause := errors.New("shutting down")
+
+	tests := map[string]struct {
+		fn       func(ctx context.Context, db *DB) error
+		cancel   bool
+		outcome  TxOutcome
+		expected string
+	}{
+		"committed": {
+			fn: func(ctx context.Context, db *DB) error { return nil },
+		},
+		"rolled back": {
+			fn:       func(ctx context.Context, db *DB) error { return errors.New("boom") },
+			outcome:  TxRolledBack,
+			expected: "boom",
+		},
+		"canceled": {
+			fn: func(ctx context.Context, db *DB) error {
+				_, err := db.Exec(ctx, "INSERT INTO parents (id) VALUES (1)")
+				return err
+			},
+			cancel:   true,
+			outcome:  TxCanceled,
+			expected: "transaction canceled: shutting down",
+		},
+		"commit failed": {
+			fn: func(ctx context.Context, db *DB) error {
+				_, err := db.Exec(ctx, "INSERT INTO children (parent_id) VALUES (404)")
+				return err
+			},
+			outcome:  TxCommitFailed,
+			expected: "FOREIGN KEY constraint failed",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var outcomes []string
+			db := NewDB(sqlDB.DB, WithTxHook(func(ctx context.Context, outcome TxOutcome, err error) {
+				outcomes = append(outcomes, fmt.Sprintf("%v: %v", outcome, err))
+			}))
+			ctx, cancel := context.WithCancelCause(ctx)
+			defer cancel(nil)
+			err := db.RunInTx(ctx, func(ctx context.Context) error {
+				// Joined transactions are reported once, by their owner
+				return db.RunInTx(ctx, func(ctx context.Context) error {
+					if test.cancel {
+						cancel(errCause)
+					}
+					return test.fn(ctx, db)
+				})
+			})
+			errcmp.MustMatch(t, err, test.expected)
+			if test.cancel && (!errors.Is(err, ErrTxCanceled) || !errors.Is(err, errCause)) {
+				t.Errorf("expected ErrTxCanceled with cause, got %v", err)
+			}
+			if expected := []string{fmt.Sprintf("%v: %v", test.outcome, err)}; !cmp.Equal(outcomes, expected) {
+				t.Errorf("unexpected outcomes:\n%v", cmp.Diff(expected, outcomes))
+			}
+		})
+	}
+}
+
+func TestWithNormalization(t *testing.T) {
+	db, ctx, cleanup := testDB(t)
+	defer cleanup()
+	db.MustExec(ctx, "DROP TABLE IF EXISTS flags; CREATE TABLE flags (id INTEGER PRIMARY KEY, active INTEGER, maybe INTEGER, extra BLOB)")
+	db.MustExec(ctx, "INSERT INTO flags (active, maybe, extra) VALUES (2, -1, X'6869'), ('0', NULL, NULL)")
+	defer db.MustExec(ctx, "DROP TABLE flags")
+
+	type flag struct {
+		ID     int64 `sqlp:"id"`
+		Active bool  `sqlp:"active"`
+		Maybe  *bool `sqlp:"maybe"`
+		Extra  any   `sqlp:"extra"`
+	}
+
+	var flags []flag
+	err := db.Select(ctx, &flags, "SELECT * FROM flags ORDER BY id")
+	errcmp.MustMatch(t, err, "couldn't convert 2 into type bool")
+
+	db = NewDB(db.DB, WithNormalization(BoolAsInt, BytesAsString))
+	if err := db.Select(ctx, &flags, "SELECT * FROM flags ORDER BY id"); err != nil {
+		t.Fatalf("failed to select: %v", err)
+	}
+	yes := true
+	expected := []flag{
+		{ID: 1, Active: true, Maybe: &yes, Extra: "hi"},
+		{ID: 2},
+	}
+	if diff := cmp.Diff(expected, flags); diff != "" {
+		t.Errorf("selected flags unexpected:\n%v", diff)
+	}
+
+	repository := NewRepository[flag](db, "flags")
+	got, err := repository.Find(ctx, 1)
+	if err != nil {
+		t.Fatalf("failed to find: %v", err)
+	}
+	if diff := cmp.Diff(expected[0], *got); diff != "" {
+		t.Errorf("found flag unexpected:\n%v", diff)
+	}
+}
+
+func leakRows(t *testing.T, db *DB) {
+	t.Helper()
+	_, err := db.Query(context.Background(), "SELECT id FROM people") // nolint:sqlclosecheck
+	if err != nil {
+		t.Fatalf("failed to query: %v", err)
+	}
+}
+
+// waitForGC runs GC until done returns true, or a short timeout.
+func waitForGC(done func() bool) {
+	for i := 0; i < 20 && !done(); i++ {
+		runtime.GC()
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// testLogger collects logs for assertions, safe for use across goroutines.
+type testLogger struct {
+	mu   sync.Mutex
+	logs []string
+}
+
+func (l *testLogger) Printf(format string, v ...any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.logs = append(l.logs, fmt.Sprintf(format, v...))
+}
+
+func (l *testLogger) String() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return strings.Join(l.logs, "\n")
+}
+
+func TestWithSkipReadOnlySavepoints(t *testing.T) {
+	db, ctx, cleanup := testDB(t)
+	defer cleanup()
+	grandchildrenSetup(ctx, db)
+
+	savepoints := func(db *DB, ctx context.Context) (int, int) {
+		ctx, capture := CaptureQueries(ctx)
+		var count int
+		err := db.RunInTx(ctx, func(ctx context.Context) error {
+			return db.RunInSavepoint(ReadOnly(ctx), func(ctx context.Context) error {
+				return db.QueryRow(ctx, "SELECT COUNT(*) FROM people").Scan(&count)
+			})
+		})
+		errcmp.MustMatch(t, err, "")
+		n := 0
+		for _, q := range capture.Queries() {
+			if strings.Contains(q.Query, "SAVEPOINT") {
+				n++
+			}
+		}
+		return n, count
+	}
+
+	if n, count := savepoints(db, ctx); n != 2 || count != 3 {
+		t.Errorf("expected savepoint without option, got %d statements, %d people", n, count)
+	}
+	skipping := NewDB(db.DB, WithSkipReadOnlySavepoints())
+	if n, count := savepoints(skipping, ctx); n != 0 || count != 3 {
+		t.Errorf("expected no savepoint with option, got %d statements, %d people", n, count)
+	}
+
+	// Callbacks not declared read-only still get one
+	ctx, capture := CaptureQueries(ctx)
+	err := skipping.RunInTx(ctx, func(ctx context.Context) error {
+		return skipping.RunInSavepoint(ctx, func(ctx context.Context) error { return nil })
+	})
+	errcmp.MustMatch(t, err, "")
+	if queries := capture.Queries(); len(queries) != 2 {
+		t.Errorf("expected savepoint for undeclared callback, got %v", queries)
+	}
+}
+
+func TestWithDefaultTimeout(t *testing.T) {
+	db, ctx, cleanup := testDB(t)
+	defer cleanup()
+	db = NewDB(db.DB, WithDefaultTimeout(time.Millisecond))
+
+	const slow = `
+		WITH RECURSIVE c(x) AS (SELECT 1 UNION ALL SELECT x + 1 FROM c WHERE x < 1000000)
+		SELECT COUNT(*) FROM c
+	`
+	t.Run("applied without deadline", func(t *testing.T) {
+		ctx := context.Background()
+		_, err := db.Exec(ctx, slow)
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("expected exec to time out, got %v", err)
+		}
+		_, err = GetScalar[int](ctx, db, slow)
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("expected query to time out, got %v", err)
+		}
+		var count int
+		err = db.QueryRow(ctx, slow).Scan(&count)
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("expected query row to time out, got %v", err)
+		}
+	})
+
+	t.Run("deadline kept", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(ctx, time.Minute)
+		defer cancel()
+		count, err := GetScalar[int](ctx, db, slow)
+		if err != nil || count != 1000000 {
+			t.Errorf("expected query to finish, got %d, %v", count, err)
+		}
+	})
+}
@@ -0,0 +1,61 @@
+package sqlp
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/greghart/powerputtygo/sqlp/internal/reflectp"
+)
+
+// OrderBy builds an ORDER BY clause from user supplied sort fields, validated against the columns
+// of E, so sort params from requests can't inject SQL or reference unknown columns.
+// Sort fields are column names, descending when prefixed with `-` (eg. "-created_at"), or
+// suffixed with " asc"/" desc". Unknown columns return ErrUnknownColumn, and no sort fields return
+// an empty string.
+//
+//	orderBy, err := sqlp.OrderBy[person](r.URL.Query()["sort"]...) // "ORDER BY last_name DESC, id ASC"
+func OrderBy[E any](sorts ...string) (string, error) {
+	return orderBy(reflect.TypeFor[E](), sorts)
+}
+
+// OrderBy builds an ORDER BY clause validated against the repository's entity, see OrderBy.
+func (r *Repository[E]) OrderBy(sorts ...string) (string, error) {
+	return orderBy(r.t, sorts)
+}
+
+func orderBy(t reflect.Type, sorts []string) (string, error) {
+	if len(sorts) == 0 {
+		return "", nil
+	}
+	fields, err := reflectp.FieldsFactory(t)
+	if err != nil {
+		return "", fmt.Errorf("failed to reflect fields for %v: %w", t, err)
+	}
+
+	terms := make([]string, len(sorts))
+	for i, sort := range sorts {
+		col, dir := parseSort(sort)
+		field, ok := fields.ByColumnName[col]
+		if !ok || !field.Columnar() {
+			return "", fmt.Errorf("%w: cannot sort by %q", ErrUnknownColumn, sort)
+		}
+		terms[i] = col + " " + dir
+	}
+	return "ORDER BY " + strings.Join(terms, ", "), nil
+}
+
+// parseSort parses a sort field into its column and direction.
+func parseSort(sort string) (string, string) {
+	sort = strings.TrimSpace(sort)
+	if col, ok := strings.CutPrefix(sort, "-"); ok {
+		return col, "DESC"
+	}
+	if col, dir, ok := strings.Cut(sort, " "); ok {
+		dir = strings.ToUpper(strings.TrimSpace(dir))
+		if dir == "ASC" || dir == "DESC" {
+			return col, dir
+		}
+	}
+	return sort, "ASC"
+}
@@ -0,0 +1,43 @@
+package sqlp
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestOrderBy(t *testing.T) {
+	tests := map[string]struct {
+		sorts    []string
+		expected string
+		err      error
+	}{
+		"no sorts -> empty":        {nil, "", nil},
+		"ascending by default":     {[]string{"first_name"}, "ORDER BY first_name ASC", nil},
+		"descending with prefix":   {[]string{"-created_at"}, "ORDER BY created_at DESC", nil},
+		"explicit directions":      {[]string{"last_name desc", "id ASC"}, "ORDER BY last_name DESC, id ASC", nil},
+		"unknown column -> err":    {[]string{"first_name", "password"}, "", ErrUnknownColumn},
+		"injection attempt -> err": {[]string{"id; DROP TABLE people"}, "", ErrUnknownColumn},
+		"bad direction -> err":     {[]string{"id sideways"}, "", ErrUnknownColumn},
+		"struct field -> err":      {[]string{"child"}, "", ErrUnknownColumn},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			q, err := OrderBy[person](test.sorts...)
+			if !errors.Is(err, test.err) {
+				t.Fatalf("got error %v, expected %v", err, test.err)
+			}
+			if q != test.expected {
+				t.Errorf("got %q, expected %q", q, test.expected)
+			}
+		})
+	}
+
+	t.Run("Repository", func(t *testing.T) {
+		db, _, cleanup := testDB(t)
+		defer cleanup()
+		q, err := NewRepository[person](db, "people").OrderBy("-id")
+		if err != nil || q != "ORDER BY id DESC" {
+			t.Errorf("got %q (%v), expected ORDER BY id DESC", q, err)
+		}
+	})
+}
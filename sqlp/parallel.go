@@ -0,0 +1,60 @@
+package sqlp
+
+import (
+	"context"
+	"sync"
+)
+
+// Parallel runs independent fetchers concurrently, returning the first error any of them returns.
+// The first error cancels the context given to the rest. Fetchers gather their own results, eg.
+//
+//	var people []person
+//	var pets []pet
+//	err := sqlp.Parallel(ctx,
+//		func(ctx context.Context) (err error) {
+//			people, err = sqlp.Select[person](ctx, db, "SELECT * FROM people")
+//			return err
+//		},
+//		func(ctx context.Context) (err error) {
+//			pets, err = sqlp.Select[pet](ctx, db, "SELECT * FROM pets")
+//			return err
+//		},
+//	)
+//
+// Fetchers always run outside of any contextual transaction, each on their own connection, since a
+// transaction's connection can't run queries concurrently.
+func Parallel(ctx context.Context, fetchers ...func(ctx context.Context) error) error {
+	ctx, cancel := context.WithCancelCause(noTxContext{ctx})
+	defer cancel(nil)
+
+	var wg sync.WaitGroup
+	var once sync.Once
+	var first error
+	for _, fetch := range fetchers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := fetch(ctx); err != nil {
+				once.Do(func() {
+					first = err
+					cancel(err)
+				})
+			}
+		}()
+	}
+	wg.Wait()
+	return first
+}
+
+// noTxContext hides any transactions (and pinned connections) of its parent context.
+type noTxContext struct {
+	context.Context
+}
+
+func (ctx noTxContext) Value(key any) any {
+	switch key.(type) {
+	case txKey, connKey:
+		return nil
+	}
+	return ctx.Context.Value(key)
+}
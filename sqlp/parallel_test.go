@@ -0,0 +1,64 @@
+package sqlp
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/greghart/powerputtygo/errcmp"
+)
+
+func TestParallel(t *testing.T) {
+	db, ctx, cleanup := testDB(t)
+	defer cleanup()
+
+	grandchildrenSetup(ctx, db)
+
+	t.Run("gathers results", func(t *testing.T) {
+		var people []person
+		var pets []pet
+		err := Parallel(ctx,
+			func(ctx context.Context) (err error) {
+				people, err = Select[person](ctx, db, "SELECT id, first_name FROM people")
+				return err
+			},
+			func(ctx context.Context) (err error) {
+				pets, err = Select[pet](ctx, db, "SELECT id, name FROM pets")
+				return err
+			},
+		)
+		if err != nil {
+			t.Fatalf("failed to fetch: %v", err)
+		}
+		if len(people) != 3 || len(pets) != 1 {
+			t.Errorf("expected 3 people and 1 pet, got %v and %v", people, pets)
+		}
+	})
+
+	t.Run("first error -> cancels rest", func(t *testing.T) {
+		err := Parallel(ctx,
+			func(ctx context.Context) error {
+				return errors.New("boom")
+			},
+			func(ctx context.Context) error {
+				<-ctx.Done()
+				return ctx.Err()
+			},
+		)
+		errcmp.MustMatch(t, err, "boom")
+	})
+
+	t.Run("outside of transaction", func(t *testing.T) {
+		err := db.RunInTx(ctx, func(ctx context.Context) error {
+			return Parallel(ctx, func(ctx context.Context) error {
+				if _, ok := TxFromContext(ctx, db); ok {
+					return errors.New("expected no transaction")
+				}
+				return nil
+			})
+		})
+		if err != nil {
+			t.Errorf("failed to fetch: %v", err)
+		}
+	})
+}
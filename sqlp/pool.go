@@ -0,0 +1,132 @@
+package sqlp
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"time"
+)
+
+// PoolMonitor periodically samples a DB's pool stats, to surface connection wait times (pool
+// saturation) before they turn into tail latency.
+//
+//	monitor := &sqlp.PoolMonitor{Interval: 10 * time.Second, Threshold: 50 * time.Millisecond}
+//	go monitor.Run(ctx, db)
+//	...
+//	monitor.Histogram() // eg. export to metrics
+//
+// database/sql only reports total wait counts and durations, so waits are measured per interval:
+// each interval's waits are counted in the histogram bucket of their average wait.
+type PoolMonitor struct {
+	Interval  time.Duration // How often to sample stats, defaults to 10s
+	Threshold time.Duration // Average wait over an interval to call OnSaturated at, never if 0
+	// OnSaturated is called with samples over Threshold, defaulting to logging a warning.
+	OnSaturated func(PoolSample)
+
+	mu        sync.Mutex
+	last      sql.DBStats
+	histogram WaitHistogram
+}
+
+// PoolSample is the pool activity over one interval of a PoolMonitor.
+type PoolSample struct {
+	Stats        sql.DBStats   // Stats at the end of the interval
+	WaitCount    int64         // Connections waited for during the interval
+	WaitDuration time.Duration // Total time waited during the interval
+}
+
+// AverageWait returns the average time waited for a connection during the interval.
+func (s PoolSample) AverageWait() time.Duration {
+	if s.WaitCount == 0 {
+		return 0
+	}
+	return s.WaitDuration / time.Duration(s.WaitCount)
+}
+
+// WaitBuckets are the upper bounds of WaitHistogram buckets, with a final bucket for anything
+// longer.
+var WaitBuckets = [...]time.Duration{
+	time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	5 * time.Second,
+}
+
+// WaitHistogram counts connection waits by duration, one count per WaitBuckets bound plus a final
+// count for longer waits.
+type WaitHistogram [len(WaitBuckets) + 1]int64
+
+// Run samples db's stats every interval until ctx is done.
+func (m *PoolMonitor) Run(ctx context.Context, db *DB) {
+	interval := m.Interval
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+	m.mu.Lock()
+	m.last = db.Stats()
+	m.mu.Unlock()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.record(db, db.Stats())
+		}
+	}
+}
+
+// Histogram returns the connection waits recorded so far.
+func (m *PoolMonitor) Histogram() WaitHistogram {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.histogram
+}
+
+// record records the interval ending with stats.
+func (m *PoolMonitor) record(db *DB, stats sql.DBStats) PoolSample {
+	m.mu.Lock()
+	sample := PoolSample{
+		Stats:        stats,
+		WaitCount:    stats.WaitCount - m.last.WaitCount,
+		WaitDuration: stats.WaitDuration - m.last.WaitDuration,
+	}
+	m.last = stats
+	if sample.WaitCount > 0 && sample.WaitDuration == 0 {
+		// Waits are counted when they start, but their duration only once they finish, so count
+		// waits in progress in a later interval instead.
+		m.last.WaitCount -= sample.WaitCount
+		sample.WaitCount = 0
+	}
+	if sample.WaitCount > 0 {
+		m.histogram[waitBucket(sample.AverageWait())] += sample.WaitCount
+	}
+	m.mu.Unlock()
+
+	if m.Threshold > 0 && sample.WaitCount > 0 && sample.AverageWait() >= m.Threshold {
+		if m.OnSaturated != nil {
+			m.OnSaturated(sample)
+		} else {
+			db.logf(
+				"sqlp: connection pool saturated, %d waits averaging %v (%d/%d connections in use)",
+				sample.WaitCount, sample.AverageWait(), stats.InUse, stats.MaxOpenConnections,
+			)
+		}
+	}
+	return sample
+}
+
+func waitBucket(d time.Duration) int {
+	for i, bound := range WaitBuckets {
+		if d <= bound {
+			return i
+		}
+	}
+	return len(WaitBuckets)
+}
@@ -0,0 +1,95 @@
+package sqlp
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestPoolMonitor(t *testing.T) {
+	db, _, cleanup := testDB(t)
+	defer cleanup()
+
+	t.Run("records waits per interval", func(t *testing.T) {
+		logger := &testLogger{}
+		db := NewDB(db.DB, WithLogger(logger))
+		monitor := &PoolMonitor{Threshold: 50 * time.Millisecond}
+
+		// 10 waits averaging 2ms, under threshold
+		sample := monitor.record(db, sql.DBStats{WaitCount: 10, WaitDuration: 20 * time.Millisecond})
+		if sample.WaitCount != 10 || sample.AverageWait() != 2*time.Millisecond {
+			t.Errorf("unexpected sample: %+v", sample)
+		}
+		if logs := logger.String(); logs != "" {
+			t.Errorf("expected no warning under threshold, got %v", logs)
+		}
+		// 2 more waits averaging 100ms, over threshold
+		sample = monitor.record(db, sql.DBStats{WaitCount: 12, WaitDuration: 220 * time.Millisecond, MaxOpenConnections: 4, InUse: 4})
+		if sample.WaitCount != 2 || sample.AverageWait() != 100*time.Millisecond {
+			t.Errorf("unexpected sample: %+v", sample)
+		}
+		if logs := logger.String(); !strings.Contains(logs, "connection pool saturated, 2 waits averaging 100ms (4/4 connections in use)") {
+			t.Errorf("expected saturation warning, got %v", logs)
+		}
+		// A wait in progress is counted once it's done
+		monitor.record(db, sql.DBStats{WaitCount: 13, WaitDuration: 220 * time.Millisecond})
+		sample = monitor.record(db, sql.DBStats{WaitCount: 13, WaitDuration: 223 * time.Millisecond})
+		if sample.WaitCount != 1 || sample.AverageWait() != 3*time.Millisecond {
+			t.Errorf("unexpected sample: %+v", sample)
+		}
+
+		expected := WaitHistogram{0, 11, 0, 0, 2}
+		if got := monitor.Histogram(); got != expected {
+			t.Errorf("expected histogram %v, got %v", expected, got)
+		}
+	})
+
+	t.Run("runs against pool", func(t *testing.T) {
+		var mu sync.Mutex
+		var saturated []PoolSample
+		monitor := &PoolMonitor{
+			Interval:  5 * time.Millisecond,
+			Threshold: time.Millisecond,
+			OnSaturated: func(s PoolSample) {
+				mu.Lock()
+				defer mu.Unlock()
+				saturated = append(saturated, s)
+			},
+		}
+		db.SetMaxOpenConns(1)
+		defer db.SetMaxOpenConns(0)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func() {
+			monitor.Run(ctx, db)
+			close(done)
+		}()
+		time.Sleep(10 * time.Millisecond) // let Run take its baseline
+
+		// Hold the only connection, so the query has to wait for it
+		conn, err := db.Conn(ctx)
+		if err != nil {
+			t.Fatalf("failed to get conn: %v", err)
+		}
+		go func() {
+			time.Sleep(20 * time.Millisecond)
+			conn.Close()
+		}()
+		if _, err := db.Exec(ctx, "SELECT 1"); err != nil {
+			t.Fatalf("failed to exec: %v", err)
+		}
+		time.Sleep(20 * time.Millisecond)
+		cancel()
+		<-done
+
+		mu.Lock()
+		defer mu.Unlock()
+		if len(saturated) != 1 || saturated[0].WaitCount != 1 {
+			t.Errorf("expected one saturated sample with one wait, got %+v", saturated)
+		}
+	})
+}
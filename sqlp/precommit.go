@@ -0,0 +1,50 @@
+package sqlp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// BeforeCommit registers fn to run when the context's transaction is about to commit, after its
+// RunInTx callback returned nil, eg. to check aggregate invariants or flush pending writes. fn runs
+// in the transaction, and returning an error rolls it back instead, with RunInTx returning the
+// error. Callbacks of nested RunInTx calls register on the outermost transaction, so they run at its
+// commit. It errors if the context has no transaction, or one adopted with WithTx (whose commit sqlp
+// doesn't see).
+func (db *DB) BeforeCommit(ctx context.Context, fn func(ctx context.Context) error) error {
+	state := db.txState(ctx)
+	if state == nil {
+		return errors.New("sqlp: BeforeCommit needs a transaction, see RunInTx")
+	}
+	if state.conn == nil {
+		return errors.New("sqlp: BeforeCommit is unavailable in transactions adopted with WithTx")
+	}
+	state.mu.Lock()
+	defer state.mu.Unlock()
+	state.preCommit = append(state.preCommit, fn)
+	return nil
+}
+
+// preCommit runs the pre-commit hooks of db (see WithPreCommitHook) and then those registered on
+// the transaction (see BeforeCommit), in order, stopping at the first error.
+// Hooks may register more hooks, which run in turn.
+func (db *DB) preCommit(ctx context.Context, state *txState) error {
+	for _, hook := range db.preCommitHooks {
+		if err := hook(ctx); err != nil {
+			return fmt.Errorf("pre-commit hook vetoed commit: %w", err)
+		}
+	}
+	for i := 0; ; i++ {
+		state.mu.Lock()
+		if i >= len(state.preCommit) {
+			state.mu.Unlock()
+			return nil
+		}
+		hook := state.preCommit[i]
+		state.mu.Unlock()
+		if err := hook(ctx); err != nil {
+			return fmt.Errorf("pre-commit hook vetoed commit: %w", err)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package sqlp
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/greghart/powerputtygo/errcmp"
+)
+
+func TestDB_BeforeCommit(t *testing.T) {
+	db, ctx, cleanup := testDB(t)
+	defer cleanup()
+
+	insert := func(ctx context.Context, name string) error {
+		_, err := db.Exec(ctx, "INSERT INTO people (first_name, last_name) VALUES (?, ?)", name, "Doe")
+		return err
+	}
+	count := func() int {
+		count, err := GetScalar[int](ctx, db, "SELECT COUNT(*) FROM people")
+		errcmp.MustMatch(t, err, "")
+		return count
+	}
+
+	t.Run("hooks run before commit, in the transaction", func(t *testing.T) {
+		defer db.MustExec(ctx, "DELETE FROM people")
+		var ran []string
+		err := db.RunInTx(ctx, func(ctx context.Context) error {
+			errcmp.MustMatch(t, db.BeforeCommit(ctx, func(ctx context.Context) error {
+				ran = append(ran, "outer")
+				return insert(ctx, "Flushed")
+			}), "")
+			return db.RunInTx(ctx, func(ctx context.Context) error {
+				return db.BeforeCommit(ctx, func(ctx context.Context) error {
+					ran = append(ran, "nested")
+					return nil
+				})
+			})
+		})
+		errcmp.MustMatch(t, err, "")
+		if expected := []string{"outer", "nested"}; !cmp.Equal(ran, expected) {
+			t.Errorf("unexpected hooks run:\n%v", cmp.Diff(expected, ran))
+		}
+		if count() != 1 {
+			t.Errorf("expected hook's insert committed")
+		}
+	})
+
+	t.Run("hook error -> rolled back", func(t *testing.T) {
+		invariant := errors.New("people must have pets")
+		err := db.RunInTx(ctx, func(ctx context.Context) error {
+			if err := insert(ctx, "John"); err != nil {
+				return err
+			}
+			return db.BeforeCommit(ctx, func(ctx context.Context) error { return invariant })
+		})
+		errcmp.MustMatch(t, err, "pre-commit hook vetoed commit: people must have pets")
+		if !errors.Is(err, invariant) {
+			t.Errorf("expected hook's error wrapped, got %v", err)
+		}
+		if count() != 0 {
+			t.Errorf("expected insert rolled back")
+		}
+	})
+
+	t.Run("callback error -> hooks don't run", func(t *testing.T) {
+		ran := false
+		err := db.RunInTx(ctx, func(ctx context.Context) error {
+			errcmp.MustMatch(t, db.BeforeCommit(ctx, func(ctx context.Context) error {
+				ran = true
+				return nil
+			}), "")
+			return errors.New("callback error")
+		})
+		errcmp.MustMatch(t, err, "callback error")
+		if ran {
+			t.Errorf("expected hook not to run")
+		}
+	})
+
+	t.Run("db hook", func(t *testing.T) {
+		db := NewDB(db.DB, WithPreCommitHook(func(ctx context.Context) error {
+			count, err := GetScalar[int](ctx, db, "SELECT COUNT(*) FROM people WHERE last_name = ''")
+			if err == nil && count > 0 {
+				err = errors.New("people need a last name")
+			}
+			return err
+		}))
+		err := db.RunInTx(ctx, func(ctx context.Context) error {
+			_, err := db.Exec(ctx, "INSERT INTO people (first_name, last_name) VALUES (?, ?)", "John", "")
+			return err
+		})
+		errcmp.MustMatch(t, err, "people need a last name")
+		if count() != 0 {
+			t.Errorf("expected insert rolled back")
+		}
+	})
+
+	t.Run("without transaction -> err", func(t *testing.T) {
+		err := db.BeforeCommit(ctx, func(ctx context.Context) error { return nil })
+		errcmp.MustMatch(t, err, "BeforeCommit needs a transaction")
+
+		tx, err := db.DB.BeginTx(ctx, nil)
+		errcmp.MustMatch(t, err, "")
+		defer tx.Rollback()
+		err = db.BeforeCommit(db.WithTx(ctx, tx), func(ctx context.Context) error { return nil })
+		errcmp.MustMatch(t, err, "adopted with WithTx")
+	})
+}
@@ -0,0 +1,72 @@
+package sqlp
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/greghart/powerputtygo/queryp"
+	"github.com/greghart/powerputtygo/sqlp/internal/reflectp"
+)
+
+// SelectOnly selects just the given columns of table's rows matching where (or all rows if where
+// is nil) into dest, a pointer to a slice of structs as with Select, leaving other fields zero.
+// This avoids `SELECT *` on wide tables. Columns are validated against the struct's columns,
+// returning ErrUnknownColumn if any aren't one.
+//
+//	err := db.SelectOnly(ctx, &people, []string{"id", "first_name"}, "people", queryp.Eq("last_name", "Doe"))
+func (db *DB) SelectOnly(
+	ctx context.Context, dest any, columns []string, table string, where queryp.Fragment,
+) error {
+	t := reflect.TypeOf(dest)
+	if t.Kind() != reflect.Pointer || t.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("select given %T, wanted a pointer to a slice", dest)
+	}
+	q, args, err := db.selectOnly(t.Elem().Elem(), columns, table, where)
+	if err != nil {
+		return err
+	}
+	return db.Select(ctx, dest, q, args...)
+}
+
+// SelectColumns selects just the given columns of the rows matching where (or all rows if where is
+// nil), leaving other fields zero, see DB.SelectOnly.
+//
+//	people, err := repository.SelectColumns(ctx, []string{"id", "first_name"}, nil)
+func (r *Repository[E]) SelectColumns(ctx context.Context, columns []string, where queryp.Fragment) ([]E, error) {
+	q, args, err := r.selectOnly(r.t, columns, r.QualifiedTable(), r.scope(where))
+	if err != nil {
+		return nil, err
+	}
+	return r.Select(ctx, q, args...)
+}
+
+// selectOnly renders the SELECT of columns of t from table, validating them against t.
+func (db *DB) selectOnly(t reflect.Type, columns []string, from string, where queryp.Fragment) (string, []any, error) {
+	if len(columns) == 0 {
+		return "", nil, fmt.Errorf("sqlp: no columns to select")
+	}
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	fields, err := reflectp.FieldsFactory(t)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to reflect fields for %v: %w", t, err)
+	}
+	d := db.Dialect()
+	quoted := make([]string, len(columns))
+	for i, column := range columns {
+		if field, ok := fields.ByColumnName[column]; !ok || !field.Columnar() {
+			return "", nil, fmt.Errorf("%w: cannot select %q", ErrUnknownColumn, column)
+		}
+		quoted[i] = d.QuoteIdent(column)
+	}
+
+	args := queryp.NewArgs().WithPlaceholderer(d.Placeholderer())
+	q := "SELECT " + strings.Join(quoted, ", ") + " FROM " + from
+	if where != nil {
+		q += " WHERE " + where(args)
+	}
+	return q, args.Args(), nil
+}
@@ -0,0 +1,62 @@
+package sqlp
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/greghart/powerputtygo/errcmp"
+	"github.com/greghart/powerputtygo/queryp"
+)
+
+func TestDB_SelectOnly(t *testing.T) {
+	db, ctx, cleanup := testDB(t)
+	defer cleanup()
+	grandparent := grandchildrenSetup(ctx, db)
+
+	var people []person
+	err := db.SelectOnly(ctx, &people, []string{"id", "first_name"}, "people", queryp.Eq("first_name", "John"))
+	if err != nil {
+		t.Fatalf("SelectOnly: %v", err)
+	}
+	expected := []person{{ID: grandparent.ID, FirstName: "John"}} // last_name and timestamps left zero
+	if diff := cmp.Diff(expected, people, personComparer); diff != "" {
+		t.Errorf("unexpected people (-want +got):\n%s", diff)
+	}
+
+	var all []person
+	if err := db.SelectOnly(ctx, &all, []string{"first_name"}, "people", nil); err != nil || len(all) != 3 {
+		t.Errorf("expected all 3 people, got %v, %v", all, err)
+	}
+
+	tests := map[string]struct {
+		dest    any
+		columns []string
+		err     string
+	}{
+		"unknown column":    {&people, []string{"email"}, `unknown column: cannot select "email"`},
+		"struct column":     {&people, []string{"child"}, `unknown column: cannot select "child"`},
+		"injection attempt": {&people, []string{"id FROM people; --"}, "unknown column: cannot select"},
+		"no columns":        {&people, nil, "no columns to select"},
+		"not a slice":       {&person{}, []string{"id"}, "wanted a pointer to a slice"},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			errcmp.MustMatch(t, db.SelectOnly(ctx, tc.dest, tc.columns, "people", nil), tc.err)
+		})
+	}
+}
+
+func TestRepository_SelectColumns(t *testing.T) {
+	db, ctx, cleanup := testDB(t)
+	defer cleanup()
+	grandchildrenSetup(ctx, db)
+
+	people, err := NewRepository[person](db, "people").SelectColumns(ctx, []string{"last_name"}, queryp.Like("first_name", "Lil%"))
+	if err != nil {
+		t.Fatalf("SelectColumns: %v", err)
+	}
+	expected := []person{{LastName: "Doe"}, {LastName: "Doe"}}
+	if diff := cmp.Diff(expected, people, personComparer); diff != "" {
+		t.Errorf("unexpected people (-want +got):\n%s", diff)
+	}
+}
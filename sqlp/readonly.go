@@ -0,0 +1,32 @@
+package sqlp
+
+import (
+	"context"
+)
+
+const readOnlyKey = contextKeyType("sqlp.readOnly")
+
+// ReadOnly returns a context declaring that callbacks run with it only read, so with
+// WithSkipReadOnlySavepoints, RunInSavepoint runs them directly in the surrounding transaction,
+// saving the round trips of creating and releasing a savepoint.
+//
+//	err := db.RunInSavepoint(sqlp.ReadOnly(ctx), func(ctx context.Context) error {
+//		people, err = repository.FindAllBy(ctx, "last_name", "Doe")
+//		return err
+//	})
+//
+// Nothing stops such a callback from writing, but if it then errors, its writes aren't rolled back.
+func ReadOnly(ctx context.Context) context.Context {
+	return context.WithValue(ctx, readOnlyKey, true)
+}
+
+// isReadOnly returns whether callbacks of context are declared read-only, see ReadOnly.
+func isReadOnly(ctx context.Context) bool {
+	readOnly, _ := ctx.Value(readOnlyKey).(bool)
+	return readOnly
+}
+
+// skipSavepoint returns whether RunInSavepoint can run a callback without a savepoint.
+func (db *DB) skipSavepoint(ctx context.Context) bool {
+	return db.skipReadOnlySavepoints && isReadOnly(ctx)
+}
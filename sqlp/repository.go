@@ -2,47 +2,278 @@ package sqlp
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"reflect"
 
+	"github.com/greghart/powerputtygo/queryp"
 	"github.com/greghart/powerputtygo/sqlp/internal/reflectp"
 )
 
 // Repository provides a data access layer for a specific entity
 type Repository[E any] struct {
 	*DB
-	entity E
-	table  string
-	t      reflect.Type
+	entity        E
+	table         string
+	schema        string
+	t             reflect.Type
+	template      *queryp.Template
+	query         string // Canned SELECT the repository reads from instead of table, if any
+	readOnly      bool
+	checksum      *checksum
+	discriminator *discriminator
+	dependents    []dependent // See WithDependent
+
+	constraintErrors map[string]error
 }
 
-func NewRepository[E any](db *DB, table string) *Repository[E] {
+// NewRepository returns a repository for E over table.
+// The table can be omitted when E declares it, so generated SQL can't drift from the entity:
+//   - With a Table() string method (see Tabler)
+//   - With a `sqlp-table` tag on a blank field, eg. `_ struct{} sqlp-table:"people"`
+//
+// Otherwise, it's inferred from E's type name by the DB's inflector, if any (see WithInflector).
+func NewRepository[E any](db *DB, table ...string) *Repository[E] {
 	var entity E
+	t := reflect.TypeOf(entity)
+	name := ""
+	if len(table) > 0 {
+		name = table[0]
+	} else {
+		name = tableOf(t)
+	}
+	if name == "" && t != nil && db.inflector != nil {
+		name = db.inflector(t.Name())
+	}
 	return &Repository[E]{
 		DB:     db,
 		entity: entity,
-		table:  table,
-		t:      reflect.TypeOf(entity),
+		table:  name,
+		schema: db.schema,
+		t:      t,
+	}
+}
+
+// NewQueryRepository returns a read-only repository for E over a canned SELECT, as if it were a
+// table named name, eg. for read models that still want Find, Aggregate and friends.
+//
+//	summaries := sqlp.NewQueryRepository[summary](db, "summaries", "SELECT parent_id AS id, COUNT(*) AS children FROM people GROUP BY parent_id")
+//	s, err := summaries.Find(ctx, 1) // SELECT * FROM (SELECT ...) AS "summaries" WHERE id = 1
+func NewQueryRepository[E any](db *DB, name, query string) *Repository[E] {
+	r := NewRepository[E](db, name)
+	r.query = query
+	r.readOnly = true
+	return r
+}
+
+// Tabler is implemented by entities that declare their table name.
+type Tabler interface {
+	Table() string
+}
+
+// tableOf returns the table declared by an entity type, if any.
+func tableOf(t reflect.Type) string {
+	if t == nil {
+		return ""
+	}
+	if t.Implements(tablerType) {
+		return reflect.Zero(t).Interface().(Tabler).Table()
+	}
+	if pt := reflect.PointerTo(t); pt.Implements(tablerType) {
+		return reflect.New(t).Interface().(Tabler).Table()
+	}
+	if t.Kind() == reflect.Struct {
+		for i := 0; i < t.NumField(); i++ {
+			if table := t.Field(i).Tag.Get("sqlp-table"); table != "" {
+				return table
+			}
+		}
+	}
+	return ""
+}
+
+var tablerType = reflect.TypeFor[Tabler]()
+
+// Table returns the repository's table name.
+func (r *Repository[E]) Table() string {
+	return r.table
+}
+
+// WithSchema returns a copy of the repository over the table in schema (defaulting to the DB's
+// schema, see WithSchema), so the same entity can be used against multiple schemas.
+func (r *Repository[E]) WithSchema(schema string) *Repository[E] {
+	c := *r
+	c.schema = schema
+	return &c
+}
+
+// ReadOnly returns a read-only copy of the repository, whose write methods return ErrReadOnly,
+// eg. for repositories over database views.
+func (r *Repository[E]) ReadOnly() *Repository[E] {
+	c := *r
+	c.readOnly = true
+	return &c
+}
+
+// IsReadOnly returns whether the repository is read-only, see ReadOnly and NewQueryRepository.
+func (r *Repository[E]) IsReadOnly() bool {
+	return r.readOnly
+}
+
+// checkWritable returns ErrReadOnly if the repository is read-only.
+func (r *Repository[E]) checkWritable(helper string) error {
+	if r.readOnly {
+		return fmt.Errorf("%w: %s of %s", ErrReadOnly, helper, r.table)
+	}
+	return nil
+}
+
+// QualifiedTable returns the table name as used in generated SQL: qualified by schema and quoted
+// for the dialect if there's a schema, otherwise as is.
+// Repositories over a canned SELECT return it as an aliased subquery.
+func (r *Repository[E]) QualifiedTable() string {
+	if r.query != "" {
+		return "(" + r.query + ") AS " + r.Dialect().QuoteIdent(r.table)
+	}
+	if r.schema == "" {
+		return r.table
 	}
+	d := r.Dialect()
+	return d.QuoteIdent(r.schema) + "." + d.QuoteIdent(r.table)
+}
+
+// WithTemplate registers the template used by Include/Build queries.
+// The template is expected to render joins (and their aliased columns) per included association,
+// which are then scanned reflectively like any other query, eg.
+//
+//	{{if .Includes "child"}}LEFT JOIN people child ON child.parent_id = p.id{{end}}
+func (r *Repository[E]) WithTemplate(t *queryp.Template) *Repository[E] {
+	r.template = t
+	return r
 }
 
 // Runs reflection process to ensure entity is setup correctly
 func (r *Repository[E]) Validate() error {
-	_, err := reflectp.FieldsFactory(r.t)
-	return err
+	if r.table == "" {
+		return fmt.Errorf("no table for %v, pass one to NewRepository, declare it on the entity or use WithInflector", r.t)
+	}
+	if _, err := reflectp.FieldsFactory(r.t); err != nil {
+		return err
+	}
+	if r.discriminator != nil {
+		if _, err := r.discriminatorField(); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // Find retrieves an entity by its ID, assuming `id` is the primary key.
 // Note, this is setup for reference as much as usage. Such methods are trivial to write yourself,
 // rather than unnecessarily complicate struct tags to tag pks and other fields.
 func (r *Repository[E]) Find(ctx context.Context, id int) (*E, error) {
+	where, args := r.where(queryp.Eq("id", id))
 	return r.Get(
 		ctx,
-		"SELECT * FROM "+r.table+" WHERE id = ?",
-		id,
+		"SELECT * FROM "+r.QualifiedTable()+" WHERE "+where,
+		args...,
 	)
 }
 
+// FindBy retrieves the first entity whose column equals value (or IS NULL for a nil value).
+// The column is validated against E's columns, returning ErrUnknownColumn if it isn't one, so
+// these tiny lookups can't typo a column or inject SQL.
+//
+//	p, err := repository.FindBy(ctx, "email", email) // SELECT * FROM people WHERE "email" = $1 LIMIT 1
+func (r *Repository[E]) FindBy(ctx context.Context, column string, value any) (*E, error) {
+	cond, err := r.whereEq(column, value)
+	if err != nil {
+		return nil, err
+	}
+	where, args := r.where(cond)
+	return r.Get(ctx, "SELECT * FROM "+r.QualifiedTable()+" WHERE "+where+" LIMIT 1", args...)
+}
+
+// FindAllBy retrieves all entities whose column equals value, see FindBy.
+func (r *Repository[E]) FindAllBy(ctx context.Context, column string, value any) ([]E, error) {
+	cond, err := r.whereEq(column, value)
+	if err != nil {
+		return nil, err
+	}
+	where, args := r.where(cond)
+	return r.Select(ctx, "SELECT * FROM "+r.QualifiedTable()+" WHERE "+where, args...)
+}
+
+// whereEq returns a condition for column equalling value, validating column is one of E's.
+func (r *Repository[E]) whereEq(column string, value any) (queryp.Fragment, error) {
+	fields, err := reflectp.FieldsFactory(r.t)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reflect fields for %v: %w", r.t, err)
+	}
+	if field, ok := fields.ByColumnName[column]; !ok || !field.Columnar() {
+		return nil, fmt.Errorf("%w: cannot find by %q", ErrUnknownColumn, column)
+	}
+	quoted := r.Dialect().QuoteIdent(column)
+	if value == nil {
+		return queryp.Raw(quoted + " IS NULL"), nil
+	}
+	return queryp.Eq(quoted, value), nil
+}
+
+// where renders where, limited to the repository's scope, with the dialect's placeholders. A nil
+// where renders just the scope, so needs a discriminator.
+func (r *Repository[E]) where(where queryp.Fragment) (string, []any) {
+	args := queryp.NewArgs().WithPlaceholderer(r.Dialect().Placeholderer())
+	return r.scope(where)(args), args.Args()
+}
+
+// Reload re-selects entity's row by its ID (assuming `id` is the primary key, as with Find), eg. after
+// writes or external changes. The entity is replaced entirely, so anything not selected (such as
+// associations) is cleared. Returns ErrNotFound if the row no longer exists.
+// To skip the extra round trip after your own writes, where the dialect has `RETURNING` (Postgres
+// and SQLite), scan the written row straight back instead:
+//
+//	err := repository.ExecReturning(ctx, &p, "UPDATE people SET ... WHERE id = $1 RETURNING *", p.ID)
+func (r *Repository[E]) Reload(ctx context.Context, entity *E) error {
+	id, err := r.idOf(*entity)
+	if err != nil {
+		return err
+	}
+	where, args := r.where(queryp.Eq("id", id))
+	reloaded, err := r.Get(ctx, "SELECT * FROM "+r.QualifiedTable()+" WHERE "+where, args...)
+	if err != nil {
+		return err
+	}
+	if reloaded == nil {
+		return fmt.Errorf("%w: %s %v", ErrNotFound, r.table, id)
+	}
+	*entity = *reloaded
+	return nil
+}
+
+// idOf returns the value of entity's `id` column.
+func (r *Repository[E]) idOf(entity E) (any, error) {
+	fields, err := reflectp.FieldsFactory(r.t)
+	if err != nil {
+		return nil, err
+	}
+	field, ok := fields.ByColumnName["id"]
+	if !ok {
+		return nil, fmt.Errorf("%w: %v has no id", ErrUnknownColumn, r.t)
+	}
+	v, err := reflect.ValueOf(entity).FieldByIndexErr(field.Index)
+	if err != nil {
+		return nil, fmt.Errorf("sqlp: %v id: %w", r.t, err)
+	}
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil, fmt.Errorf("sqlp: %v id is nil", r.t)
+		}
+		v = v.Elem()
+	}
+	return v.Interface(), nil
+}
+
 func (r *Repository[E]) Get(ctx context.Context, q string, args ...any) (*E, error) {
 	var entity *E
 	entities, err := r.Select(ctx, q, args...)
@@ -54,26 +285,195 @@ func (r *Repository[E]) Get(ctx context.Context, q string, args ...any) (*E, err
 }
 
 func (r *Repository[E]) Select(ctx context.Context, q string, args ...any) ([]E, error) {
+	return r.selectEntities(ctx, true, q, args...)
+}
+
+// selectEntities is Select, only applying the row limit and verifying checksums if checked. Rows
+// that are already deleted (eg. by `DELETE ... RETURNING`) must be returned as is, all of them.
+func (r *Repository[E]) selectEntities(ctx context.Context, checked bool, q string, args ...any) ([]E, error) {
 	var entities []E
+	ctx, cancel := r.timeoutContext(ctx)
+	defer cancel()
 	rows, err := r.DB.Query(ctx, q, args...)
 	if err != nil {
-		return nil, err
+		return nil, r.TranslateError(err)
 	}
 	defer rows.Close()
 
 	// Prepare row scanning
-	scanner, err := NewReflectScanner[E](rows)
+	scanner, err := newReflectScanner[E](rows, ignoredColumns(ctx), r.normalization)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get reflect scanner: %w", err)
 	}
+	var verify func(entity E) error
+	var limit rowLimit
+	if checked {
+		if verify, err = r.rowsVerifier(ctx, rows); err != nil {
+			return nil, err
+		}
+		limit = r.rowLimit(ctx)
+	}
+	for rows.Next() {
+		if limit.exceeded(int64(len(entities))) {
+			if limit.truncate {
+				break
+			}
+			return nil, limit.err()
+		}
+		val, err := scanner.Scan()
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		if verify != nil {
+			if err := verify(val); err != nil {
+				return nil, err
+			}
+		}
+		entities = append(entities, val)
+		if err := checkSelectContext(ctx, int64(len(entities))); err != nil {
+			return nil, err
+		}
+	}
+	r.captureScanned(ctx, rows, int64(len(entities)))
+
+	return entities, r.TranslateError(rows.Err())
+}
 
+// GetWith is like Get, but scans using the given mapper instead of reflection.
+func (r *Repository[E]) GetWith(ctx context.Context, mapper Mapper[E], q string, args ...any) (*E, error) {
+	var entity *E
+	entities, err := r.SelectWith(ctx, mapper, q, args...)
+	if len(entities) > 0 {
+		e := entities[0] // copy out of array
+		entity = &e
+	}
+	return entity, err
+}
+
+// SelectWith is like Select, but scans using the given mapper instead of reflection.
+// Useful for hot queries that want to skip reflection, while the rest of the repository stays
+// reflective.
+func (r *Repository[E]) SelectWith(ctx context.Context, mapper Mapper[E], q string, args ...any) ([]E, error) {
+	var entities []E
+	ctx, cancel := r.timeoutContext(ctx)
+	defer cancel()
+	rows, err := r.DB.Query(ctx, q, args...)
+	if err != nil {
+		return nil, r.TranslateError(err)
+	}
+	defer rows.Close()
+
+	scanner := NewMappingScanner(rows, mapper)
+	verify, err := r.rowsVerifier(ctx, rows)
+	if err != nil {
+		return nil, err
+	}
+	limit := r.rowLimit(ctx)
 	for rows.Next() {
+		if limit.exceeded(int64(len(entities))) {
+			if limit.truncate {
+				break
+			}
+			return nil, limit.err()
+		}
 		val, err := scanner.Scan()
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan row: %w", err)
 		}
+		if verify != nil {
+			if err := verify(val); err != nil {
+				return nil, err
+			}
+		}
 		entities = append(entities, val)
+		if err := checkSelectContext(ctx, int64(len(entities))); err != nil {
+			return nil, err
+		}
+	}
+	r.captureScanned(ctx, rows, int64(len(entities)))
+
+	return entities, r.TranslateError(rows.Err())
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// RepositoryQuery builds a query against a repository's registered template.
+// Its results are scanned as Select scans any other query, one entity per row, into nested structs
+// for included to-one associations. Nothing aggregates rows, so a one-to-many include returns its
+// parent once per child row; aggregate those yourself, eg. with a mapperp mapper.
+type RepositoryQuery[E any] struct {
+	r       *Repository[E]
+	builder *queryp.TemplateBuilder
+}
+
+// Build starts a query against the registered template.
+func (r *Repository[E]) Build() *RepositoryQuery[E] {
+	q := &RepositoryQuery[E]{r: r}
+	if r.template != nil {
+		q.builder = r.template.Build().Placeholderer(r.Dialect().Placeholderer())
 	}
+	return q
+}
+
+// Include starts a query against the registered template, including the given associations.
+func (r *Repository[E]) Include(associations ...string) *RepositoryQuery[E] {
+	return r.Build().Include(associations...)
+}
+
+// Include marks associations to be included in the template.
+func (q *RepositoryQuery[E]) Include(associations ...string) *RepositoryQuery[E] {
+	if q.builder != nil {
+		q.builder.Include(associations...)
+	}
+	return q
+}
+
+// Param sets a named parameter value.
+func (q *RepositoryQuery[E]) Param(key string, val any) *RepositoryQuery[E] {
+	if q.builder != nil {
+		q.builder.Param(key, val)
+	}
+	return q
+}
 
-	return entities, rows.Err()
+// Params sets multiple named parameters at a time (additive with existing ones).
+func (q *RepositoryQuery[E]) Params(params map[string]any) *RepositoryQuery[E] {
+	if q.builder != nil {
+		q.builder.Params(params)
+	}
+	return q
+}
+
+// Placeholderer sets how to replace named parameters (defaults to the repository's dialect).
+func (q *RepositoryQuery[E]) Placeholderer(p queryp.Placeholderer) *RepositoryQuery[E] {
+	if q.builder != nil {
+		q.builder.Placeholderer(p)
+	}
+	return q
+}
+
+// Execute executes the template, returning the query and its arguments.
+func (q *RepositoryQuery[E]) Execute() (string, []any, error) {
+	if q.builder == nil {
+		return "", nil, errors.New("sqlp: repository has no template, see WithTemplate")
+	}
+	return q.builder.Execute()
+}
+
+// Get executes the template and gets the first entity, if any.
+func (q *RepositoryQuery[E]) Get(ctx context.Context) (*E, error) {
+	query, args, err := q.Execute()
+	if err != nil {
+		return nil, err
+	}
+	return q.r.Get(ctx, query, args...)
+}
+
+// Select executes the template and selects all entities.
+func (q *RepositoryQuery[E]) Select(ctx context.Context) ([]E, error) {
+	query, args, err := q.Execute()
+	if err != nil {
+		return nil, err
+	}
+	return q.r.Select(ctx, query, args...)
 }
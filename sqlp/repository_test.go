@@ -1,9 +1,14 @@
 package sqlp
 
 import (
+	"context"
+	"errors"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/greghart/powerputtygo/errcmp"
+	"github.com/greghart/powerputtygo/queryp"
 )
 
 func TestRepository_Validate(t *testing.T) {
@@ -143,3 +148,380 @@ func TestRepository_Select(t *testing.T) {
 		}
 	})
 }
+
+func TestRepository_SelectWith(t *testing.T) {
+	db, ctx, cleanup := testDB(t)
+	defer cleanup()
+
+	repository := NewRepository[person](db, "people")
+
+	grandparent := grandchildrenSetup(ctx, db)
+	albert := albertSetup(ctx, db)
+
+	t.Run("simple one table query", func(t *testing.T) {
+		people, err := repository.SelectWith(ctx, personMapper(t), "SELECT id, first_name, last_name FROM people")
+		if err != nil {
+			t.Fatalf("failed to select: %v", err)
+		}
+		expected := []person{
+			{ID: grandparent.ID, FirstName: "John", LastName: "Doe"},
+			{ID: grandparent.Child.ID, FirstName: "Lil Johnnie", LastName: "Doe"},
+			{ID: grandparent.Child.Child.ID, FirstName: "Lil Lil Johnnie", LastName: "Doe"},
+			albert,
+		}
+		if !cmp.Equal(people, expected, personComparer) {
+			t.Errorf("selected people unexpected:\n%v", cmp.Diff(expected, people, personComparer))
+		}
+	})
+
+	t.Run("unmapped column -> err", func(t *testing.T) {
+		_, err := repository.SelectWith(ctx, personMapper(t), "SELECT id, parent_id FROM people")
+		errcmp.MustMatch(t, err, "failed to get mapping for parent_id")
+	})
+
+	t.Run("canceled while scanning -> err", func(t *testing.T) {
+		defer func(n int64) { selectCheckRows = n }(selectCheckRows)
+		selectCheckRows = 1
+
+		// Only flip Err, so the driver keeps delivering rows like it would with buffered results
+		ctx := &canceledContext{Context: ctx}
+		mapper := Mapper[person]{
+			"id": func(p *person) any {
+				ctx.canceled = true
+				return &p.ID
+			},
+		}
+		people, err := repository.SelectWith(ctx, mapper, "SELECT id FROM people")
+		errcmp.MustMatch(t, err, "select aborted after 1 rows: context canceled")
+		if people != nil {
+			t.Errorf("expected no people, got %v", people)
+		}
+	})
+}
+
+// canceledContext reports context.Canceled from Err once canceled, without closing Done.
+type canceledContext struct {
+	context.Context
+	canceled bool
+}
+
+func (ctx *canceledContext) Err() error {
+	if ctx.canceled {
+		return context.Canceled
+	}
+	return ctx.Context.Err()
+}
+
+func TestRepository_GetWith(t *testing.T) {
+	db, ctx, cleanup := testDB(t)
+	defer cleanup()
+
+	repository := NewRepository[person](db, "people")
+
+	grandparent := grandchildrenSetup(ctx, db)
+
+	p, err := repository.GetWith(ctx, personMapper(t), "SELECT id, first_name, last_name FROM people WHERE id = ?", grandparent.ID)
+	if err != nil {
+		t.Fatalf("failed to get: %v", err)
+	}
+	expected := person{ID: grandparent.ID, FirstName: "John", LastName: "Doe"}
+	if !cmp.Equal(*p, expected, personComparer) {
+		t.Errorf("gotten person unexpected:\n%v", cmp.Diff(expected, *p, personComparer))
+	}
+}
+
+func TestRepository_FindBy(t *testing.T) {
+	db, ctx, cleanup := testDB(t)
+	defer cleanup()
+
+	repository := NewRepository[person](db, "people")
+	grandparent := grandchildrenSetup(ctx, db)
+
+	p, err := repository.FindBy(ctx, "first_name", "John")
+	if err != nil || p == nil || p.ID != grandparent.ID {
+		t.Errorf("expected John, got %v, %v", p, err)
+	}
+	p, err = repository.FindBy(ctx, "first_name", "Nobody")
+	if err != nil || p != nil {
+		t.Errorf("expected nothing, got %v, %v", p, err)
+	}
+
+	people, err := repository.FindAllBy(ctx, "last_name", "Doe")
+	if err != nil || len(people) != 3 {
+		t.Errorf("expected 3 Does, got %v, %v", people, err)
+	}
+
+	db.MustExec(ctx, "INSERT INTO pets (name, type) VALUES (?, ?), (?, NULL)", "Rex", "dog", "Unknown")
+	pets, err := NewRepository[pet](db, "pets").FindAllBy(ctx, "type", nil)
+	if err != nil || len(pets) != 1 || pets[0].Name != "Unknown" {
+		t.Errorf("expected nil to find NULLs, got %v, %v", pets, err)
+	}
+
+	t.Run("postgres placeholders", func(t *testing.T) {
+		pg := NewRepository[person](NewDB(db.DB, WithDialect(queryp.Postgres)), "people").
+			WithDiscriminator("last_name", "Doe")
+		ctx, capture := CaptureQueries(ctx)
+		_, err := pg.FindBy(ctx, "first_name", "John")
+		errcmp.MustMatch(t, err, "")
+		_, err = pg.FindAllBy(ctx, "first_name", "John")
+		errcmp.MustMatch(t, err, "")
+		queries := capture.Queries()
+		expected := `SELECT * FROM people WHERE ("first_name" = $1 AND "last_name" = $2)`
+		if len(queries) != 2 || queries[0].Query != expected+" LIMIT 1" || queries[1].Query != expected {
+			t.Errorf("expected Postgres placeholders, got %v", queries)
+		}
+	})
+
+	tests := map[string]string{
+		"unknown column":    "email",
+		"struct column":     "child",
+		"injection attempt": "id = 1 OR 1",
+	}
+	for name, column := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := repository.FindBy(ctx, column, 1)
+			errcmp.MustMatch(t, err, "unknown column: cannot find by")
+			_, err = repository.FindAllBy(ctx, column, 1)
+			errcmp.MustMatch(t, err, "unknown column: cannot find by")
+		})
+	}
+}
+
+func TestRepository_Reload(t *testing.T) {
+	db, ctx, cleanup := testDB(t)
+	defer cleanup()
+
+	repository := NewRepository[person](db, "people")
+	albert := albertSetup(ctx, db)
+
+	p := person{ID: albert.ID, FirstName: "stale"}
+	db.MustExec(ctx, "UPDATE people SET last_name = ? WHERE id = ?", "Newton", albert.ID)
+	if err := repository.Reload(ctx, &p); err != nil {
+		t.Fatalf("failed to reload: %v", err)
+	}
+	expected := person{ID: albert.ID, FirstName: "Albert", LastName: "Newton"}
+	if !cmp.Equal(p, expected, personComparer) {
+		t.Errorf("reloaded person unexpected:\n%v", cmp.Diff(expected, p, personComparer))
+	}
+
+	pgCtx, capture := CaptureQueries(ctx)
+	pg := NewRepository[person](NewDB(db.DB, WithDialect(queryp.Postgres)), "people")
+	errcmp.MustMatch(t, pg.Reload(pgCtx, &p), "")
+	if queries := capture.Queries(); len(queries) != 1 || queries[0].Query != "SELECT * FROM people WHERE id = $1" {
+		t.Errorf("expected Postgres placeholders, got %v", queries)
+	}
+
+	db.MustExec(ctx, "DELETE FROM people WHERE id = ?", albert.ID)
+	err := repository.Reload(ctx, &p)
+	errcmp.MustMatch(t, err, "not found: people")
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+
+	pets := NewRepository[struct {
+		Name string `sqlp:"name"`
+	}](db, "pets")
+	errcmp.MustMatch(t, pets.Reload(ctx, &struct {
+		Name string `sqlp:"name"`
+	}{}), "unknown column")
+}
+
+func TestRepository_Include(t *testing.T) {
+	db, ctx, cleanup := testDB(t)
+	defer cleanup()
+
+	tmpl := queryp.Must(queryp.NewTemplate(`
+		SELECT p.id, p.first_name, p.last_name
+		{{- if .Includes "child"}},
+			COALESCE(child.id, 0) AS child_id,
+			COALESCE(child.first_name, "") AS child_first_name,
+			COALESCE(child.last_name, "") AS child_last_name
+		{{- end}}
+		{{- if .Includes "child_pet"}},
+			COALESCE(child_pet.id, 0) AS child_pet_id,
+			COALESCE(child_pet.name, "") AS child_pet_name,
+			child_pet.type AS child_pet_type
+		{{- end}}
+		FROM people p
+		{{if .Includes "child"}}LEFT JOIN people child ON p.id = child.parent_id{{end}}
+		{{if .Includes "child_pet"}}LEFT JOIN pets child_pet ON child.id = child_pet.parent_id{{end}}
+		WHERE p.parent_id IS NULL {{if .Param "id"}}AND p.id = :id{{end}}
+		ORDER BY p.id ASC
+	`)).DependsOn("child_pet", "child")
+	repository := NewRepository[person](db, "people").WithTemplate(tmpl)
+
+	grandparent := grandchildrenSetup(ctx, db)
+	albert := albertSetup(ctx, db)
+
+	t.Run("no includes", func(t *testing.T) {
+		people, err := repository.Build().Select(ctx)
+		if err != nil {
+			t.Fatalf("failed to select: %v", err)
+		}
+		expected := []person{
+			{ID: grandparent.ID, FirstName: "John", LastName: "Doe"},
+			albert,
+		}
+		if !cmp.Equal(people, expected, personComparer) {
+			t.Errorf("selected people unexpected:\n%v", cmp.Diff(expected, people, personComparer))
+		}
+	})
+
+	t.Run("includes with dependencies", func(t *testing.T) {
+		p, err := repository.Include("child_pet").Param("id", grandparent.ID).Get(ctx)
+		if err != nil {
+			t.Fatalf("failed to get: %v", err)
+		}
+		expected := grandparent
+		expected.Child = &person{
+			ID: grandparent.Child.ID, FirstName: "Lil Johnnie", LastName: "Doe",
+			Pet: grandparent.Child.Pet,
+		}
+		if !cmp.Equal(*p, expected, personComparer) {
+			t.Errorf("gotten person unexpected:\n%v", cmp.Diff(expected, *p, personComparer))
+		}
+	})
+
+	t.Run("dialect placeholders", func(t *testing.T) {
+		pg := NewRepository[person](NewDB(db.DB, WithDialect(queryp.Postgres)), "people").WithTemplate(tmpl)
+		q, args, err := pg.Build().Param("id", grandparent.ID).Execute()
+		errcmp.MustMatch(t, err, "")
+		if !strings.Contains(q, "AND p.id = $1") || len(args) != 1 {
+			t.Errorf("expected Postgres placeholders, got %v %v", q, args)
+		}
+	})
+
+	t.Run("no template -> err", func(t *testing.T) {
+		_, err := NewRepository[person](db, "people").Include("child").Select(ctx)
+		errcmp.MustMatch(t, err, "repository has no template")
+	})
+}
+
+type tablerPerson struct {
+	ID int64 `sqlp:"id"`
+}
+
+func (tablerPerson) Table() string { return "people" }
+
+type ptrTablerPerson struct {
+	ID int64 `sqlp:"id"`
+}
+
+func (*ptrTablerPerson) Table() string { return "ptr_people" }
+
+func TestNewRepository_table(t *testing.T) {
+	db, ctx, cleanup := testDB(t)
+	defer cleanup()
+
+	type taggedPerson struct {
+		_  struct{} `sqlp-table:"tagged_people"`
+		ID int64    `sqlp:"id"`
+	}
+	tests := map[string]struct {
+		table    string
+		expected string
+	}{
+		"explicit":         {NewRepository[tablerPerson](db, "others").Table(), "others"},
+		"Table method":     {NewRepository[tablerPerson](db).Table(), "people"},
+		"Table ptr method": {NewRepository[ptrTablerPerson](db).Table(), "ptr_people"},
+		"struct tag":       {NewRepository[taggedPerson](db).Table(), "tagged_people"},
+		"none":             {NewRepository[person](db).Table(), ""},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if test.table != test.expected {
+				t.Errorf("expected table %q, got %q", test.expected, test.table)
+			}
+		})
+	}
+
+	errcmp.MustMatch(t, NewRepository[person](db).Validate(), "no table for sqlp.person")
+	if err := NewRepository[taggedPerson](db).Validate(); err != nil {
+		t.Errorf("expected tagged entity valid, got %v", err)
+	}
+
+	// Generated SQL uses the declared table
+	id := must(db.ExecID(ctx, "INSERT INTO people (first_name) VALUES (?)", "John"))
+	p, err := NewRepository[tablerPerson](db).Find(ctx, int(id))
+	if err != nil || p == nil || p.ID != id {
+		t.Errorf("expected to find person %v, got %v, %v", id, p, err)
+	}
+}
+
+func TestRepository_WithSchema(t *testing.T) {
+	db, ctx, cleanup := testDB(t)
+	defer cleanup()
+
+	// Attached databases are SQLite's schemas, but are per connection
+	db.SetMaxOpenConns(1)
+	defer db.SetMaxOpenConns(0)
+	db.MustExec(ctx, "ATTACH DATABASE ':memory:' AS analytics")
+	defer db.MustExec(ctx, "DETACH DATABASE analytics")
+	db.MustExec(ctx, "CREATE TABLE analytics.people (id INTEGER PRIMARY KEY, first_name TEXT, last_name TEXT)")
+	db.MustExec(ctx, "INSERT INTO analytics.people (first_name, last_name) VALUES (?, ?)", "Albert", "Einstein")
+	db.MustExec(ctx, "INSERT INTO people (first_name, last_name) VALUES (?, ?)", "John", "Doe")
+
+	people := NewRepository[person](db, "people")
+	analytics := people.WithSchema("analytics")
+	if q := analytics.QualifiedTable(); q != `"analytics"."people"` {
+		t.Errorf("unexpected qualified table %v", q)
+	}
+	if q := people.QualifiedTable(); q != "people" {
+		t.Errorf("expected original repository unqualified, got %v", q)
+	}
+	if p, err := analytics.Find(ctx, 1); err != nil || p == nil || p.FirstName != "Albert" {
+		t.Errorf("expected Albert from analytics schema, got %v, %v", p, err)
+	}
+	if p, err := people.Find(ctx, 1); err != nil || p == nil || p.FirstName != "John" {
+		t.Errorf("expected John from default schema, got %v, %v", p, err)
+	}
+
+	// DB wide default, quoted per dialect
+	mysql := NewDB(db.DB, WithSchema("analytics"), WithDialect(queryp.MySQL))
+	if q := NewRepository[person](mysql, "people").QualifiedTable(); q != "`analytics`.`people`" {
+		t.Errorf("unexpected qualified table %v", q)
+	}
+}
+
+func TestRepository_ReadOnly(t *testing.T) {
+	db, ctx, cleanup := testDB(t)
+	defer cleanup()
+	grandchildrenSetup(ctx, db)
+
+	db.MustExec(ctx, "DROP VIEW IF EXISTS parents; CREATE VIEW parents AS SELECT * FROM people WHERE parent_id IS NULL")
+	defer db.MustExec(ctx, "DROP VIEW parents")
+
+	tests := map[string]*Repository[person]{
+		"view": NewRepository[person](db, "parents").ReadOnly(),
+		"query": NewQueryRepository[person](
+			db, "parents", "SELECT * FROM people WHERE parent_id IS NULL",
+		),
+	}
+	for name, repository := range tests {
+		t.Run(name, func(t *testing.T) {
+			if !repository.IsReadOnly() {
+				t.Errorf("expected read-only repository")
+			}
+			if p, err := repository.Find(ctx, 1); err != nil || p == nil || p.FirstName != "John" {
+				t.Errorf("expected John, got %v, %v", p, err)
+			}
+			if p, err := repository.Find(ctx, 2); err != nil || p != nil {
+				t.Errorf("expected child to be filtered out, got %v, %v", p, err)
+			}
+			if max, err := Aggregate[int64](ctx, repository, Max("id"), ""); err != nil || max != 1 {
+				t.Errorf("expected max parent id 1, got %v, %v", max, err)
+			}
+
+			_, err := repository.DeleteAll(ctx, IUnderstandThisDeletesEverything)
+			errcmp.MustMatch(t, err, "repository is read-only: DeleteAll of parents")
+			if !errors.Is(err, ErrReadOnly) {
+				t.Errorf("expected ErrReadOnly, got %v", err)
+			}
+			errcmp.MustMatch(t, repository.Truncate(ctx, IUnderstandThisDeletesEverything), "read-only: Truncate of parents")
+		})
+	}
+
+	if NewRepository[person](db, "people").IsReadOnly() {
+		t.Errorf("expected repositories to be writable by default")
+	}
+}
@@ -0,0 +1,87 @@
+package sqlp
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// GetScalar runs a single column query and returns the value of its first row, eg. for counts.
+// Returns ErrNotFound if there are no rows.
+//
+//	n, err := sqlp.GetScalar[int64](ctx, db, "SELECT COUNT(*) FROM people")
+func GetScalar[T any](ctx context.Context, db *DB, query string, args ...any) (T, error) {
+	var v T
+	ctx, cancel := db.timeoutContext(ctx)
+	defer cancel()
+	rows, err := db.Query(ctx, query, args...)
+	if err != nil {
+		return v, err
+	}
+	defer rows.Close()
+
+	if err := checkScalar(rows); err != nil {
+		return v, err
+	}
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return v, err
+		}
+		return v, fmt.Errorf("%w: no rows for scalar", ErrNotFound)
+	}
+	if err := rows.Scan(&v); err != nil {
+		return v, fmt.Errorf("failed to scan row: %w", err)
+	}
+	db.captureScanned(ctx, rows, 1)
+	return v, rows.Err()
+}
+
+// SelectScalars runs a single column query and returns its values, eg. for lists of ids.
+//
+//	ids, err := sqlp.SelectScalars[int64](ctx, db, "SELECT id FROM people WHERE parent_id = ?", id)
+func SelectScalars[T any](ctx context.Context, db *DB, query string, args ...any) ([]T, error) {
+	ctx, cancel := db.timeoutContext(ctx)
+	defer cancel()
+	rows, err := db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	if err := checkScalar(rows); err != nil {
+		return nil, err
+	}
+	limit := db.rowLimit(ctx)
+	var out []T
+	for rows.Next() {
+		if limit.exceeded(int64(len(out))) {
+			if limit.truncate {
+				break
+			}
+			return nil, limit.err()
+		}
+		var v T
+		if err := rows.Scan(&v); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		out = append(out, v)
+		if err := checkSelectContext(ctx, int64(len(out))); err != nil {
+			return nil, err
+		}
+	}
+	db.captureScanned(ctx, rows, int64(len(out)))
+
+	return out, rows.Err()
+}
+
+// checkScalar errors unless rows have a single column.
+func checkScalar(rows *sql.Rows) error {
+	cols, err := rows.Columns()
+	if err != nil {
+		return fmt.Errorf("failed to get columns: %w", err)
+	}
+	if len(cols) != 1 {
+		return fmt.Errorf("sqlp: scalar query must select one column, got %v", cols)
+	}
+	return nil
+}
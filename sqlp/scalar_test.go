@@ -0,0 +1,53 @@
+package sqlp
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/greghart/powerputtygo/errcmp"
+)
+
+func TestGetScalar(t *testing.T) {
+	db, ctx, cleanup := testDB(t)
+	defer cleanup()
+
+	grandparent := grandchildrenSetup(ctx, db)
+
+	n, err := GetScalar[int64](ctx, db, "SELECT COUNT(*) FROM people")
+	if err != nil || n != 3 {
+		t.Errorf("expected 3 people, got %v, %v", n, err)
+	}
+
+	name, err := GetScalar[*string](ctx, db, "SELECT first_name FROM people WHERE id = ?", grandparent.ID)
+	if err != nil || name == nil || *name != "John" {
+		t.Errorf("expected John, got %v, %v", name, err)
+	}
+
+	_, err = GetScalar[string](ctx, db, "SELECT first_name FROM people WHERE id = ?", -1)
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected not found, got %v", err)
+	}
+
+	_, err = GetScalar[string](ctx, db, "SELECT first_name, last_name FROM people")
+	errcmp.MustMatch(t, err, "scalar query must select one column, got [first_name last_name]")
+}
+
+func TestSelectScalars(t *testing.T) {
+	db, ctx, cleanup := testDB(t)
+	defer cleanup()
+
+	grandparent := grandchildrenSetup(ctx, db)
+
+	ids, err := SelectScalars[int64](ctx, db, "SELECT id FROM people WHERE parent_id IS NOT NULL ORDER BY id")
+	if err != nil {
+		t.Fatalf("failed to select: %v", err)
+	}
+	expected := []int64{grandparent.Child.ID, grandparent.Child.Child.ID}
+	if diff := cmp.Diff(expected, ids); diff != "" {
+		t.Errorf("selected ids unexpected:\n%v", diff)
+	}
+
+	_, err = SelectScalars[int64](ctx, db, "SELECT first_name FROM people")
+	errcmp.MustMatch(t, err, "failed to scan row")
+}
@@ -0,0 +1,60 @@
+package sqlp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/greghart/powerputtygo/errcmp"
+)
+
+type byteSize int64
+
+type job struct {
+	ID       int64          `sqlp:"id"`
+	Timeout  time.Duration  `sqlp:"timeout_ms,duration=ms"`
+	Interval *time.Duration `sqlp:"interval_s,duration=s"`
+	Quota    byteSize       `sqlp:"quota_kib,scale=1024"`
+}
+
+func (job) Table() string { return "jobs" }
+
+func TestRepository_Scale(t *testing.T) {
+	db, ctx, cleanup := testDB(t)
+	defer cleanup()
+	db.MustExec(ctx, "DROP TABLE IF EXISTS jobs; CREATE TABLE jobs (id INTEGER PRIMARY KEY, timeout_ms INTEGER, interval_s INTEGER, quota_kib INTEGER)")
+	db.MustExec(ctx, "INSERT INTO jobs (id, timeout_ms, interval_s, quota_kib) VALUES (1, 1500, 60, 4096), (2, 0, NULL, 0)")
+	defer db.MustExec(ctx, "DROP TABLE jobs")
+
+	r := NewRepository[job](db)
+	jobs, err := r.Select(ctx, "SELECT * FROM jobs ORDER BY id")
+	if err != nil {
+		t.Fatalf("Select: %v", err)
+	}
+	minute := time.Minute
+	expected := []job{{ID: 1, Timeout: 1500 * time.Millisecond, Interval: &minute, Quota: 4 << 20}, {ID: 2}}
+	if diff := cmp.Diff(expected, jobs); diff != "" {
+		t.Errorf("unexpected jobs (-want +got):\n%s", diff)
+	}
+
+	type limits struct {
+		Timeout time.Duration `sqlp:"timeout_ms,duration=ms"`
+		Quota   byteSize      `sqlp:"quota_kib,scale=1024"`
+	}
+	if _, err := r.UpdateWhereIDs(ctx, []int64{2}, limits{Timeout: 2 * time.Second, Quota: 8 << 20}); err != nil {
+		t.Fatalf("UpdateWhereIDs: %v", err)
+	}
+	var timeout, quota int64
+	if err := db.QueryRow(ctx, "SELECT timeout_ms, quota_kib FROM jobs WHERE id = 2").Scan(&timeout, &quota); err != nil {
+		t.Fatalf("QueryRow: %v", err)
+	}
+	if timeout != 2000 || quota != 8192 {
+		t.Errorf("expected 2000ms and 8192KiB stored, got %d and %d", timeout, quota)
+	}
+
+	type bad struct {
+		Timeout int64 `sqlp:"timeout_ms,duration=ms"`
+	}
+	_, err = NewRepository[bad](db, "jobs").Select(ctx, "SELECT timeout_ms FROM jobs")
+	errcmp.MustMatch(t, err, "duration option needs a time.Duration, got int64")
+}
@@ -12,26 +12,81 @@ type OutScanner[E any] interface {
 	Scan() (E, error)
 }
 
+// Column describes a column of a query's result set, as reported by the driver.
+// Not all drivers support all metadata, hence the `Has`/`Known` flags.
+type Column struct {
+	Name             string
+	DatabaseTypeName string       // eg. "VARCHAR", "INT", empty if unknown
+	ScanType         reflect.Type // Go type suitable for scanning into
+	Nullable         bool
+	NullableKnown    bool
+	Length           int64 // eg. for variable length text and binary types
+	HasLength        bool
+	Precision        int64 // eg. for decimal types
+	Scale            int64
+	HasDecimalSize   bool
+}
+
+// columnInfo reads column metadata from rows.
+func columnInfo(rows *sql.Rows) ([]Column, error) {
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get column types: %w", err)
+	}
+	cols := make([]Column, len(types))
+	for i, t := range types {
+		cols[i] = Column{
+			Name:             t.Name(),
+			DatabaseTypeName: t.DatabaseTypeName(),
+			ScanType:         t.ScanType(),
+		}
+		cols[i].Nullable, cols[i].NullableKnown = t.Nullable()
+		cols[i].Length, cols[i].HasLength = t.Length()
+		cols[i].Precision, cols[i].Scale, cols[i].HasDecimalSize = t.DecimalSize()
+	}
+	return cols, nil
+}
+
+// ScanPlanStats are stats of the process wide cache of scan plans, see ScanPlanCache.
+type ScanPlanStats = reflectp.PlanCacheStats
+
+// ScanPlanCache returns stats of the process wide cache of scan plans, eg. to export as metrics.
+// Reflective scanners plan how to scan a result's columns into a struct once per struct type and
+// columns (and ignored fields), rather than once per query, keeping the most recently used plans.
+func ScanPlanCache() ScanPlanStats {
+	return reflectp.PlanCache()
+}
+
 // ReflectScanner uses a generic type parameter to return values instead of scanning into destinations
 type ReflectScanner[E any] struct {
 	*ReflectDestScanner
 }
 
 func NewReflectScanner[E any](rows *sql.Rows) (*ReflectScanner[E], error) {
+	return newReflectScanner[E](rows, nil, reflectp.Normalization{})
+}
+
+// newReflectScanner returns a ReflectScanner leaving ignored fields as is (see IgnoreColumns), and
+// normalizing values (see WithNormalization).
+func newReflectScanner[E any](
+	rows *sql.Rows, ignore []string, normalization reflectp.Normalization,
+) (*ReflectScanner[E], error) {
 	// Type parameter lets us check validity immediately
 	var e E
 	destFields, err := reflectp.FieldsFactory(reflect.TypeOf(e))
 	if err != nil {
 		return nil, fmt.Errorf("failed to reflect fields for %T: %w", reflect.TypeOf(e), err)
 	}
-	fRows, err := destFields.Rows(rows)
+	fRows, err := destFields.Rows(rows, ignore...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get fields rows: %w", err)
 	}
+	fRows.Normalization = normalization
 	return &ReflectScanner[E]{
 		ReflectDestScanner: &ReflectDestScanner{
-			Rows:  rows,
-			fRows: fRows,
+			Rows:          rows,
+			fRows:         fRows,
+			normalization: normalization,
 		},
 	}, nil
 }
@@ -51,7 +106,11 @@ func (rs *ReflectScanner[E]) Scan() (E, error) {
 // `Scan` API
 type ReflectDestScanner struct {
 	*sql.Rows
-	fRows *reflectp.FieldsRows
+	fRows   *reflectp.FieldsRows
+	columns []Column
+	ignore  []string // Fields to leave as is, see IgnoreColumns
+
+	normalization reflectp.Normalization // See WithNormalization
 }
 
 func NewReflectDestScanner(rows *sql.Rows) *ReflectDestScanner {
@@ -74,10 +133,11 @@ func (rs *ReflectDestScanner) Scan(dest any) error {
 		if err != nil {
 			return fmt.Errorf("failed to reflect fields for %T: %w", elemType, err)
 		}
-		fRows, err := destFields.Rows(rs.Rows)
+		fRows, err := destFields.Rows(rs.Rows, rs.ignore...)
 		if err != nil {
 			return fmt.Errorf("failed to get fields rows: %w", err)
 		}
+		fRows.Normalization = rs.normalization
 		rs.fRows = fRows
 	}
 
@@ -85,12 +145,25 @@ func (rs *ReflectDestScanner) Scan(dest any) error {
 	return err
 }
 
+// ColumnInfo returns metadata about the columns of the result set being scanned.
+func (rs *ReflectDestScanner) ColumnInfo() ([]Column, error) {
+	if rs.columns == nil {
+		cols, err := columnInfo(rs.Rows)
+		if err != nil {
+			return nil, err
+		}
+		rs.columns = cols
+	}
+	return rs.columns, nil
+}
+
 ////////////////////////////////////////////////////////////////////////////////
 
 type MappingScanner[E any] struct {
 	*sql.Rows
-	cols   []string
-	mapper Mapper[E]
+	cols    []string
+	columns []Column
+	mapper  Mapper[E]
 }
 
 func NewMappingScanner[E any](rows *sql.Rows, mapper Mapper[E]) *MappingScanner[E] {
@@ -122,3 +195,62 @@ func (ms *MappingScanner[E]) Scan() (E, error) {
 
 	return e, ms.Rows.Scan(targets...)
 }
+
+// ColumnInfo returns metadata about the columns of the result set being scanned.
+func (ms *MappingScanner[E]) ColumnInfo() ([]Column, error) {
+	if ms.columns == nil {
+		cols, err := columnInfo(ms.Rows)
+		if err != nil {
+			return nil, err
+		}
+		ms.columns = cols
+	}
+	return ms.columns, nil
+}
+
+// //////////////////////////////////////////////////////////////////////////////
+
+// TypedScanner scans rows using a TypedMapper, into buffers set up once per result set rather than
+// once per row.
+type TypedScanner[E any] struct {
+	*sql.Rows
+	mapper  TypedMapper[E]
+	targets []any
+	assigns []func(e *E)
+}
+
+func NewTypedScanner[E any](rows *sql.Rows, mapper TypedMapper[E]) *TypedScanner[E] {
+	return &TypedScanner[E]{
+		Rows:   rows,
+		mapper: mapper,
+	}
+}
+
+func (ts *TypedScanner[E]) Scan() (E, error) {
+	var e E
+
+	if ts.targets == nil {
+		cols, err := ts.Columns()
+		if err != nil {
+			return e, fmt.Errorf("failed to get columns: %w", err)
+		}
+		targets := make([]any, len(cols))
+		assigns := make([]func(e *E), len(cols))
+		for i, c := range cols {
+			mapping, ok := ts.mapper[c]
+			if !ok {
+				return e, fmt.Errorf("failed to get mapping for %v", c)
+			}
+			targets[i], assigns[i] = mapping()
+		}
+		ts.targets, ts.assigns = targets, assigns
+	}
+
+	if err := ts.Rows.Scan(ts.targets...); err != nil {
+		return e, err
+	}
+	for _, assign := range ts.assigns {
+		assign(&e)
+	}
+	return e, nil
+}
@@ -1,10 +1,14 @@
 package sqlp
 
 import (
+	"context"
+	"database/sql"
+	"fmt"
 	"log"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/greghart/powerputtygo/errcmp"
 )
 
 func TestReflectDestScanner(t *testing.T) {
@@ -85,3 +89,204 @@ func TestMappingScanner(t *testing.T) {
 		t.Errorf("selected people unexpected:\n%v", cmp.Diff(expected, people, personComparer))
 	}
 }
+
+func TestScanner_ColumnInfo(t *testing.T) {
+	db, ctx, cleanup := testDB(t)
+	defer cleanup()
+	albertSetup(ctx, db)
+
+	query := "SELECT id, first_name, created_at FROM people"
+	expected := []Column{
+		{Name: "id", DatabaseTypeName: "INTEGER"},
+		{Name: "first_name", DatabaseTypeName: "TEXT"},
+		{Name: "created_at", DatabaseTypeName: "TIMESTAMP"},
+	}
+	comparer := cmp.Comparer(func(x, y Column) bool {
+		return x.Name == y.Name && x.DatabaseTypeName == y.DatabaseTypeName
+	})
+
+	t.Run("ReflectScanner", func(t *testing.T) {
+		rows, err := db.Query(ctx, query)
+		if err != nil {
+			t.Fatalf("failed to query: %v", err)
+		}
+		defer rows.Close()
+		scanner, err := NewReflectScanner[person](rows)
+		if err != nil {
+			t.Fatalf("failed to create scanner: %v", err)
+		}
+		cols, err := scanner.ColumnInfo()
+		if err != nil {
+			t.Fatalf("failed to get column info: %v", err)
+		}
+		if !cmp.Equal(cols, expected, comparer) {
+			t.Errorf("column info unexpected:\n%v", cmp.Diff(expected, cols, comparer))
+		}
+	})
+
+	t.Run("MappingScanner", func(t *testing.T) {
+		rows, err := db.Query(ctx, query)
+		if err != nil {
+			t.Fatalf("failed to query: %v", err)
+		}
+		defer rows.Close()
+		cols, err := NewMappingScanner(rows, personMapper(t)).ColumnInfo()
+		if err != nil {
+			t.Fatalf("failed to get column info: %v", err)
+		}
+		if !cmp.Equal(cols, expected, comparer) {
+			t.Errorf("column info unexpected:\n%v", cmp.Diff(expected, cols, comparer))
+		}
+	})
+}
+
+func TestScanPlanCache(t *testing.T) {
+	db, ctx, cleanup := testDB(t)
+	defer cleanup()
+	grandchildrenSetup(ctx, db)
+
+	// A query shape unique to this test, so its first scan is a miss
+	q := "SELECT first_name, id AS id, last_name FROM people"
+	var people []person
+	if err := db.Select(ctx, &people, q); err != nil {
+		t.Fatalf("Select: %v", err)
+	}
+	before := ScanPlanCache()
+	for range 3 {
+		if err := db.Select(ctx, &people, q); err != nil {
+			t.Fatalf("Select: %v", err)
+		}
+	}
+	after := ScanPlanCache()
+	if hits := after.Hits - before.Hits; hits < 3 {
+		t.Errorf("expected repeated queries to hit the plan cache, got %d hits", hits)
+	}
+	if after.HitRate() <= 0 || after.Size == 0 {
+		t.Errorf("unexpected stats: %+v", after)
+	}
+}
+
+func TestTypedScanner(t *testing.T) {
+	db, ctx, cleanup := testDB(t)
+	defer cleanup()
+	grandparent := grandchildrenSetup(ctx, db)
+
+	mapper := TypedMapper[person]{
+		"id":          Typed(func(p *person, v int64) { p.ID = v }),
+		"first_name":  Typed(func(p *person, v string) { p.FirstName = v }),
+		"null_string": Typed(func(p *person, v *string) { p.NullString = v }),
+	}
+	rows, err := db.Query(ctx, "SELECT id, first_name, NULL AS null_string FROM people ORDER BY id")
+	if err != nil {
+		t.Fatalf("failed to query: %v", err)
+	}
+	defer rows.Close()
+
+	scanner := NewTypedScanner(rows, mapper)
+	var people []person
+	for rows.Next() {
+		p, err := scanner.Scan()
+		if err != nil {
+			t.Fatalf("failed to scan row: %v", err)
+		}
+		people = append(people, p)
+	}
+	expected := []person{
+		{ID: grandparent.ID, FirstName: "John"},
+		{ID: grandparent.Child.ID, FirstName: "Lil Johnnie"},
+		{ID: grandparent.Child.Child.ID, FirstName: "Lil Lil Johnnie"},
+	}
+	if !cmp.Equal(people, expected, personComparer) {
+		t.Errorf("scanned people unexpected:\n%v", cmp.Diff(expected, people, personComparer))
+	}
+
+	rows, err = db.Query(ctx, "SELECT last_name FROM people")
+	if err != nil {
+		t.Fatalf("failed to query: %v", err)
+	}
+	defer rows.Close()
+	rows.Next()
+	_, err = NewTypedScanner(rows, mapper).Scan()
+	errcmp.MustMatch(t, err, "failed to get mapping for last_name")
+}
+
+// BenchmarkScanning_Rows benchmarks scanning a larger result, where per row costs dominate.
+func BenchmarkScanning_Rows(b *testing.B) {
+	db, ctx, cleanup := testDB(b)
+	defer cleanup()
+	err := db.RunInTx(ctx, func(ctx context.Context) error {
+		for i := range 1000 {
+			if _, err := db.Exec(ctx, "INSERT INTO people (first_name, last_name) VALUES (?, ?)", fmt.Sprint("John ", i), "Doe"); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		b.Fatalf("failed to setup: %v", err)
+	}
+	query := "SELECT id, first_name, last_name FROM people"
+	scan := func(b *testing.B, scan func(rows *sql.Rows) (person, error)) {
+		b.Helper()
+		b.ReportAllocs()
+		for b.Loop() {
+			rows, err := db.Query(ctx, query)
+			if err != nil {
+				b.Fatalf("failed to query: %v", err)
+			}
+			people := make([]person, 0, 1000)
+			for rows.Next() {
+				p, err := scan(rows)
+				if err != nil {
+					b.Fatalf("failed to scan row: %v", err)
+				}
+				people = append(people, p)
+			}
+			rows.Close()
+		}
+	}
+
+	b.Run("reflect", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			var people []person
+			if err := db.Select(ctx, &people, query); err != nil {
+				b.Fatalf("failed to select: %v", err)
+			}
+		}
+	})
+	b.Run("mapper", func(b *testing.B) {
+		mapper := Mapper[person]{
+			"id":         func(p *person) any { return &p.ID },
+			"first_name": func(p *person) any { return &p.FirstName },
+			"last_name":  func(p *person) any { return &p.LastName },
+		}
+		var scanner *MappingScanner[person]
+		scan(b, func(rows *sql.Rows) (person, error) {
+			if scanner == nil || scanner.Rows != rows {
+				scanner = NewMappingScanner(rows, mapper)
+			}
+			return scanner.Scan()
+		})
+	})
+	b.Run("typed", func(b *testing.B) {
+		mapper := TypedMapper[person]{
+			"id":         Typed(func(p *person, v int64) { p.ID = v }),
+			"first_name": Typed(func(p *person, v string) { p.FirstName = v }),
+			"last_name":  Typed(func(p *person, v string) { p.LastName = v }),
+		}
+		var scanner *TypedScanner[person]
+		scan(b, func(rows *sql.Rows) (person, error) {
+			if scanner == nil || scanner.Rows != rows {
+				scanner = NewTypedScanner(rows, mapper)
+			}
+			return scanner.Scan()
+		})
+	})
+	b.Run("vanilla", func(b *testing.B) {
+		scan(b, func(rows *sql.Rows) (person, error) {
+			var p person
+			return p, rows.Scan(&p.ID, &p.FirstName, &p.LastName)
+		})
+	})
+}
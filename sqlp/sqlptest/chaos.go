@@ -0,0 +1,313 @@
+package sqlptest
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"math/rand/v2"
+	"regexp"
+	"sync"
+	"time"
+)
+
+// ErrSerializationFailure is a serialization failure, as databases report when concurrent
+// transactions conflict and should be retried. It reports SQLSTATE 40001 through SQLState().
+var ErrSerializationFailure error = sqlStateError{
+	state: "40001",
+	msg:   "sqlptest: could not serialize access due to concurrent update",
+}
+
+type sqlStateError struct {
+	state string
+	msg   string
+}
+
+func (e sqlStateError) Error() string    { return e.msg }
+func (e sqlStateError) SQLState() string { return e.state }
+
+// Fault is a fault for Chaos to inject into matching queries.
+type Fault struct {
+	// Match selects the queries to fault, or all if nil. Transactions are matched as "BEGIN" and
+	// "COMMIT" (a faulted commit is rolled back).
+	Match *regexp.Regexp
+	// Rate is the chance of faulting a matching query, from 0 to 1 (0 is treated as 1, always).
+	Rate float64
+	// Times limits how many times the fault is injected, or unlimited if 0.
+	Times int
+
+	Latency time.Duration // Delay before the query runs (or fails)
+	Err     error         // Error to fail with, eg. driver.ErrBadConn or ErrSerializationFailure
+	Drop    bool          // Drop the connection, failing with driver.ErrBadConn
+}
+
+// Chaos injects faults into connections, to test retries, circuit breakers, and rollback paths
+// deterministically. Faults are injected at the driver level, so they're seen by sqlp (and
+// database/sql) exactly as real ones would be -- eg. database/sql retries driver.ErrBadConn on a
+// fresh connection by itself.
+//
+//	chaos := &sqlptest.Chaos{Faults: []sqlptest.Fault{
+//		{Match: regexp.MustCompile("^COMMIT$"), Err: sqlptest.ErrSerializationFailure, Times: 1},
+//	}}
+//	sqlDB, err := chaos.Open("sqlite3", "./test.db")
+//	db := sqlp.NewDB(sqlDB)
+//
+// For each query, the first matching fault (in order) that rolls under its rate is injected.
+// Rates are rolled from Seed, so a sequence of queries faults the same way every run.
+type Chaos struct {
+	Faults []Fault
+	Seed   uint64
+
+	mu       sync.Mutex
+	rand     *rand.Rand
+	injected []int // count per fault
+}
+
+// Open opens a *sql.DB for the driver registered as driverName, with faults injected.
+func (c *Chaos) Open(driverName, dataSourceName string) (*sql.DB, error) {
+	db, err := sql.Open(driverName, dataSourceName)
+	if err != nil {
+		return nil, err
+	}
+	d := db.Driver()
+	if err := db.Close(); err != nil {
+		return nil, err
+	}
+	return sql.OpenDB(c.Connector(d, dataSourceName)), nil
+}
+
+// Connector returns a connector opening connections to dataSourceName with d, with faults injected.
+func (c *Chaos) Connector(d driver.Driver, dataSourceName string) driver.Connector {
+	return &chaosConnector{chaos: c, driver: d, dsn: dataSourceName}
+}
+
+// Injected returns the number of faults injected so far.
+func (c *Chaos) Injected() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	total := 0
+	for _, n := range c.injected {
+		total += n
+	}
+	return total
+}
+
+// inject rolls for a fault on query, applying any latency, and returning the fault if injected.
+func (c *Chaos) inject(ctx context.Context, query string) *Fault {
+	fault := c.roll(query)
+	if fault == nil {
+		return nil
+	}
+	if fault.Latency > 0 {
+		t := time.NewTimer(fault.Latency)
+		defer t.Stop()
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+		}
+	}
+	return fault
+}
+
+func (c *Chaos) roll(query string) *Fault {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.rand == nil {
+		c.rand = rand.New(rand.NewPCG(c.Seed, c.Seed))
+		c.injected = make([]int, len(c.Faults))
+	}
+	for i := range c.Faults {
+		f := &c.Faults[i]
+		if f.Match != nil && !f.Match.MatchString(query) {
+			continue
+		}
+		if f.Times > 0 && c.injected[i] >= f.Times {
+			continue
+		}
+		if f.Rate > 0 && f.Rate < 1 && c.rand.Float64() >= f.Rate {
+			continue
+		}
+		c.injected[i]++
+		return f
+	}
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+type chaosConnector struct {
+	chaos  *Chaos
+	driver driver.Driver
+	dsn    string
+}
+
+func (c *chaosConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	var conn driver.Conn
+	var err error
+	if dc, ok := c.driver.(driver.DriverContext); ok {
+		var connector driver.Connector
+		if connector, err = dc.OpenConnector(c.dsn); err != nil {
+			return nil, err
+		}
+		conn, err = connector.Connect(ctx)
+	} else {
+		conn, err = c.driver.Open(c.dsn)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &chaosConn{chaos: c.chaos, conn: conn}, nil
+}
+
+func (c *chaosConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+// chaosConn wraps a connection, injecting faults before each query.
+type chaosConn struct {
+	chaos   *Chaos
+	conn    driver.Conn
+	dropped bool
+}
+
+var (
+	_ driver.ConnPrepareContext = &chaosConn{}
+	_ driver.ConnBeginTx        = &chaosConn{}
+	_ driver.ExecerContext      = &chaosConn{}
+	_ driver.QueryerContext     = &chaosConn{}
+	_ driver.NamedValueChecker  = &chaosConn{}
+	_ driver.SessionResetter    = &chaosConn{}
+	_ driver.Validator          = &chaosConn{}
+)
+
+// fault injects any fault for query.
+func (c *chaosConn) fault(ctx context.Context, query string) error {
+	if c.dropped {
+		return driver.ErrBadConn
+	}
+	f := c.chaos.inject(ctx, query)
+	switch {
+	case f == nil:
+		return ctx.Err()
+	case f.Drop:
+		c.dropped = true
+		c.conn.Close()
+		return driver.ErrBadConn
+	case f.Err != nil:
+		return f.Err
+	}
+	return ctx.Err()
+}
+
+func (c *chaosConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *chaosConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	if err := c.fault(ctx, query); err != nil {
+		return nil, err
+	}
+	if pc, ok := c.conn.(driver.ConnPrepareContext); ok {
+		return pc.PrepareContext(ctx, query)
+	}
+	return c.conn.Prepare(query)
+}
+
+func (c *chaosConn) Close() error {
+	if c.dropped {
+		return nil
+	}
+	return c.conn.Close()
+}
+
+func (c *chaosConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *chaosConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if err := c.fault(ctx, "BEGIN"); err != nil {
+		return nil, err
+	}
+	var tx driver.Tx
+	var err error
+	if bc, ok := c.conn.(driver.ConnBeginTx); ok {
+		tx, err = bc.BeginTx(ctx, opts)
+	} else {
+		tx, err = c.conn.Begin() // nolint:staticcheck
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &chaosTx{conn: c, tx: tx}, nil
+}
+
+func (c *chaosConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	ec, ok := c.conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip // falls back to Prepare, which injects instead
+	}
+	if err := c.fault(ctx, query); err != nil {
+		return nil, err
+	}
+	return ec.ExecContext(ctx, query, args)
+}
+
+func (c *chaosConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	qc, ok := c.conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip // falls back to Prepare, which injects instead
+	}
+	if err := c.fault(ctx, query); err != nil {
+		return nil, err
+	}
+	return qc.QueryContext(ctx, query, args)
+}
+
+func (c *chaosConn) CheckNamedValue(nv *driver.NamedValue) error {
+	if nc, ok := c.conn.(driver.NamedValueChecker); ok {
+		return nc.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+func (c *chaosConn) ResetSession(ctx context.Context) error {
+	if c.dropped {
+		return driver.ErrBadConn
+	}
+	if sr, ok := c.conn.(driver.SessionResetter); ok {
+		return sr.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c *chaosConn) IsValid() bool {
+	if c.dropped {
+		return false
+	}
+	if v, ok := c.conn.(driver.Validator); ok {
+		return v.IsValid()
+	}
+	return true
+}
+
+// chaosTx wraps a transaction, injecting faults on commit.
+type chaosTx struct {
+	conn *chaosConn
+	tx   driver.Tx
+}
+
+func (t *chaosTx) Commit() error {
+	if err := t.conn.fault(context.Background(), "COMMIT"); err != nil {
+		if !t.conn.dropped {
+			t.tx.Rollback() // nolint:errcheck
+		}
+		return err
+	}
+	return t.tx.Commit()
+}
+
+func (t *chaosTx) Rollback() error {
+	if t.conn.dropped {
+		return driver.ErrBadConn
+	}
+	return t.tx.Rollback()
+}
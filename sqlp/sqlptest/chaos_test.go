@@ -0,0 +1,142 @@
+package sqlptest
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/greghart/powerputtygo/errcmp"
+	"github.com/greghart/powerputtygo/sqlp"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestChaos(t *testing.T) {
+	insert := regexp.MustCompile("^INSERT")
+	tests := map[string]struct {
+		faults   []Fault
+		run      func(ctx context.Context, db *sqlp.DB) error
+		expected string // error substring, empty for success
+		people   int    // people inserted after
+		injected int
+	}{
+		"error on matching query": {
+			faults: []Fault{{Match: insert, Err: errors.New("boom"), Times: 1}},
+			run: func(ctx context.Context, db *sqlp.DB) error {
+				_, err := db.Exec(ctx, "INSERT INTO people (name) VALUES (?)", "John")
+				return err
+			},
+			expected: "boom",
+			injected: 1,
+		},
+		"bad conns are retried by database/sql": {
+			faults: []Fault{{Match: insert, Err: driver.ErrBadConn, Times: 1}},
+			run: func(ctx context.Context, db *sqlp.DB) error {
+				_, err := db.Exec(ctx, "INSERT INTO people (name) VALUES (?)", "John")
+				return err
+			},
+			people:   1,
+			injected: 1,
+		},
+		"dropped conns are replaced": {
+			faults: []Fault{{Match: insert, Drop: true, Times: 2}},
+			run: func(ctx context.Context, db *sqlp.DB) error {
+				_, err := db.Exec(ctx, "INSERT INTO people (name) VALUES (?)", "John")
+				return err
+			},
+			people:   1,
+			injected: 2,
+		},
+		"serialization failure on commit rolls back": {
+			faults: []Fault{{Match: regexp.MustCompile("^COMMIT$"), Err: ErrSerializationFailure}},
+			run: func(ctx context.Context, db *sqlp.DB) error {
+				return db.RunInTx(ctx, func(ctx context.Context) error {
+					_, err := db.Exec(ctx, "INSERT INTO people (name) VALUES (?)", "John")
+					return err
+				})
+			},
+			expected: "could not serialize",
+			injected: 1,
+		},
+		"latency respects context": {
+			faults: []Fault{{Match: insert, Latency: time.Second}},
+			run: func(ctx context.Context, db *sqlp.DB) error {
+				ctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+				defer cancel()
+				_, err := db.Exec(ctx, "INSERT INTO people (name) VALUES (?)", "John")
+				return err
+			},
+			expected: "context deadline exceeded",
+			injected: 1,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			chaos := &Chaos{Faults: test.faults}
+			db, ctx := chaosDB(t, chaos)
+
+			errcmp.MustMatch(t, test.run(ctx, db), test.expected)
+			var people int
+			if err := db.QueryRow(ctx, "SELECT COUNT(*) FROM people").Scan(&people); err != nil {
+				t.Fatalf("failed to count: %v", err)
+			}
+			if people != test.people {
+				t.Errorf("expected %d people, got %d", test.people, people)
+			}
+			if chaos.Injected() != test.injected {
+				t.Errorf("expected %d faults injected, got %d", test.injected, chaos.Injected())
+			}
+		})
+	}
+}
+
+func TestChaos_Rate(t *testing.T) {
+	run := func() []bool {
+		chaos := &Chaos{Seed: 42, Faults: []Fault{{Rate: 0.5, Err: errors.New("boom")}}}
+		db, ctx := chaosDB(t, chaos)
+		var failed []bool
+		for i := 0; i < 20; i++ {
+			_, err := db.Exec(ctx, "INSERT INTO people (name) VALUES (?)", "John")
+			failed = append(failed, err != nil)
+		}
+		return failed
+	}
+	first, second := run(), run()
+	failures := 0
+	for i := range first {
+		if first[i] != second[i] {
+			t.Fatalf("expected same faults for same seed, got %v and %v", first, second)
+		}
+		if first[i] {
+			failures++
+		}
+	}
+	if failures == 0 || failures == len(first) {
+		t.Errorf("expected some but not all queries to fault, got %v", first)
+	}
+}
+
+// chaosDB opens a fresh sqlite db with a people table, injecting chaos after setup.
+func chaosDB(t *testing.T, chaos *Chaos) (*sqlp.DB, context.Context) {
+	t.Helper()
+	ctx := context.Background()
+	faults := chaos.Faults
+	chaos.Faults = nil // no faults during setup
+
+	sqlDB, err := chaos.Open("sqlite3", filepath.Join(t.TempDir(), "chaos.db"))
+	if err != nil {
+		t.Fatalf("failed to open: %v", err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+	db := sqlp.NewDB(sqlDB)
+	db.MustExec(ctx, "CREATE TABLE people (id INTEGER PRIMARY KEY, name TEXT)")
+
+	chaos.mu.Lock()
+	chaos.Faults = faults
+	chaos.rand = nil // reset, so setup doesn't affect rolls
+	chaos.mu.Unlock()
+	return db, ctx
+}
@@ -0,0 +1,97 @@
+// Package sqlptest provides helpers for testing code built on sqlp.
+package sqlptest
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/greghart/powerputtygo/sqlp/internal/reflectp"
+)
+
+// Option configures how entities are compared.
+type Option func() cmp.Option
+
+// TimeWithin treats times as equal if they're within d of each other, for timestamps set by the
+// database (eg. `DEFAULT CURRENT_TIMESTAMP`).
+func TimeWithin(d time.Duration) Option {
+	return func() cmp.Option {
+		return cmpopts.EquateApproxTime(d)
+	}
+}
+
+// IgnoreColumns ignores fields mapped to any of the given columns, at any depth.
+// Columns are named the same as for scanning (sqlp tag, or field name), but without any prefixes,
+// eg. "updated_at" ignores both `updated_at` and `child_updated_at`.
+func IgnoreColumns(columns ...string) Option {
+	ignored := make(map[string]bool, len(columns))
+	for _, c := range columns {
+		ignored[c] = true
+	}
+	return ignoreFields(func(f *reflectp.Field) bool { return ignored[f.Column] })
+}
+
+// IgnoreTagged ignores fields whose sqlp tag has the given option, at any depth, eg.
+// IgnoreTagged("auto") for fields the database sets, tagged `sqlp:"id,auto"`.
+func IgnoreTagged(option string) Option {
+	return ignoreFields(func(f *reflectp.Field) bool { return f.HasOption(option) })
+}
+
+// ignoreFields ignores struct fields that sqlp maps to a column for which ignore returns true.
+func ignoreFields(ignore func(f *reflectp.Field) bool) Option {
+	return func() cmp.Option {
+		return cmp.FilterPath(func(p cmp.Path) bool {
+			sf, ok := p.Last().(cmp.StructField)
+			if !ok {
+				return false
+			}
+			field := field(p.Index(-2).Type(), sf.Index())
+			return field != nil && ignore(field)
+		}, cmp.Ignore())
+	}
+}
+
+// Diff compares two entities (or slices of them), returning a human readable diff, or an empty
+// string if they're equal.
+// Unexported fields are compared (entities commonly embed unexported structs like timestamps), and
+// nil and empty slices/maps are treated as equal.
+func Diff(want, got any, opts ...Option) string {
+	return cmp.Diff(want, got, cmpOptions(opts)...)
+}
+
+// EqualEntities reports a test error with a diff if the entities aren't equal, see Diff.
+func EqualEntities(t testing.TB, want, got any, opts ...Option) bool {
+	t.Helper()
+	if diff := Diff(want, got, opts...); diff != "" {
+		t.Errorf("entities unexpected (-want +got):\n%s", diff)
+		return false
+	}
+	return true
+}
+
+func cmpOptions(opts []Option) []cmp.Option {
+	out := []cmp.Option{
+		cmp.Exporter(func(reflect.Type) bool { return true }),
+		cmpopts.EquateEmpty(),
+	}
+	for _, opt := range opts {
+		out = append(out, opt())
+	}
+	return out
+}
+
+// field returns the i-th field of struct t as sqlp scans it, or nil if it isn't scanned.
+func field(t reflect.Type, i int) *reflectp.Field {
+	fields, err := reflectp.FieldsFactory(t)
+	if err != nil {
+		return nil
+	}
+	for _, f := range fields.ByColumnName {
+		if len(f.Index) == 1 && f.Index[0] == i {
+			return f
+		}
+	}
+	return nil
+}
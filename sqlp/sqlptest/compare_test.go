@@ -0,0 +1,108 @@
+package sqlptest
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDiff(t *testing.T) {
+	now := time.Now()
+	base := person{
+		ID: 1, FirstName: "John",
+		Child:      &person{ID: 2, FirstName: "Lil Johnnie", timestamps: timestamps{CreatedAt: now}},
+		timestamps: timestamps{CreatedAt: now, UpdatedAt: now},
+	}
+	tests := map[string]struct {
+		got      func(p person) person
+		opts     []Option
+		expected string // substring of diff, empty for equal
+	}{
+		"equal": {
+			got: func(p person) person { return p },
+		},
+		"different field": {
+			got:      func(p person) person { p.FirstName = "Jon"; return p },
+			expected: "FirstName",
+		},
+		"different unexported embedded field": {
+			got:      func(p person) person { p.UpdatedAt = now.Add(time.Hour); return p },
+			expected: "UpdatedAt",
+		},
+		"nil and empty slices are equal": {
+			got: func(p person) person { p.Children = []person{}; return p },
+		},
+		"times within tolerance": {
+			got:  func(p person) person { p.CreatedAt = now.Add(time.Second); return p },
+			opts: []Option{TimeWithin(5 * time.Second)},
+		},
+		"times outside tolerance": {
+			got:      func(p person) person { p.CreatedAt = now.Add(time.Minute); return p },
+			opts:     []Option{TimeWithin(5 * time.Second)},
+			expected: "CreatedAt",
+		},
+		"ignored columns at any depth": {
+			got: func(p person) person {
+				p.UpdatedAt = time.Time{}
+				child := *p.Child
+				child.CreatedAt = time.Time{}
+				p.Child = &child
+				return p
+			},
+			opts: []Option{IgnoreColumns("created_at", "updated_at")},
+		},
+		"ignored tagged fields at any depth": {
+			got: func(p person) person {
+				p.ID = 3
+				child := *p.Child
+				child.ID = 4
+				p.Child = &child
+				return p
+			},
+			opts: []Option{IgnoreTagged("auto")},
+		},
+		"ignored tagged fields don't ignore others": {
+			got:      func(p person) person { p.FirstName = "Jon"; return p },
+			opts:     []Option{IgnoreTagged("auto")},
+			expected: "FirstName",
+		},
+		"ignored columns don't ignore others": {
+			got:      func(p person) person { p.ID = 3; return p },
+			opts:     []Option{IgnoreColumns("first_name")},
+			expected: "ID",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			diff := Diff(base, test.got(base), test.opts...)
+			if test.expected == "" && diff != "" {
+				t.Errorf("expected equal, got diff:\n%s", diff)
+			}
+			if test.expected != "" && !strings.Contains(diff, test.expected) {
+				t.Errorf("expected diff of %s, got:\n%s", test.expected, diff)
+			}
+		})
+	}
+}
+
+func TestEqualEntities(t *testing.T) {
+	people := []person{{ID: 1}, {ID: 2}}
+	if !EqualEntities(t, people, []person{{ID: 1}, {ID: 2}}) {
+		t.Errorf("expected equal entities")
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+type person struct {
+	ID        int64    `sqlp:"id,auto"`
+	FirstName string   `sqlp:"first_name"`
+	Child     *person  `sqlp:"child"`
+	Children  []person `sqlp:"children"`
+	timestamps
+}
+
+type timestamps struct {
+	CreatedAt time.Time `sqlp:"created_at"`
+	UpdatedAt time.Time `sqlp:"updated_at"`
+}
@@ -0,0 +1,116 @@
+package sqlptest
+
+import (
+	"context"
+	"sync"
+
+	"github.com/greghart/powerputtygo/sqlp"
+)
+
+// Contend runs each callback in its own transaction, concurrently, returning each transaction's
+// error (nil if it committed). Callbacks interleave deterministically through the Interleaving, to
+// test contention such as optimistic locking, SKIP LOCKED queues, and serialization retries:
+//
+//	errs := sqlptest.Contend(ctx, db,
+//		func(ctx context.Context, i *sqlptest.Interleaving) error {
+//			version := read(ctx)
+//			i.Barrier(ctx) // both have read
+//			return write(ctx, version)
+//		},
+//		func(ctx context.Context, i *sqlptest.Interleaving) error {
+//			version := read(ctx)
+//			i.Barrier(ctx)
+//			i.WaitFor(ctx, 0) // the first has committed
+//			return write(ctx, version) // expect a conflict
+//		},
+//	)
+//
+// Use a context with a timeout, so a real deadlock fails the test rather than hanging it.
+func Contend(
+	ctx context.Context, db *sqlp.DB, fns ...func(ctx context.Context, i *Interleaving) error,
+) []error {
+	i := &Interleaving{
+		running: len(fns),
+		release: make(chan struct{}),
+		done:    make([]chan struct{}, len(fns)),
+	}
+	for n := range fns {
+		i.done[n] = make(chan struct{})
+	}
+
+	errs := make([]error, len(fns))
+	var wg sync.WaitGroup
+	for n, fn := range fns {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer i.finish(n)
+			errs[n] = db.RunInTx(ctx, func(ctx context.Context) error {
+				return fn(ctx, i)
+			})
+		}()
+	}
+	wg.Wait()
+	return errs
+}
+
+// Interleaving coordinates the callbacks of Contend.
+type Interleaving struct {
+	mu      sync.Mutex
+	running int           // callbacks still running
+	arrived int           // callbacks waiting at the current barrier
+	release chan struct{} // closed to release the current barrier
+	done    []chan struct{}
+}
+
+// Barrier blocks until every callback still running has reached a barrier, or ctx is done.
+func (i *Interleaving) Barrier(ctx context.Context) error {
+	i.mu.Lock()
+	i.arrived++
+	release := i.release
+	i.releaseIfAllArrived()
+	i.mu.Unlock()
+
+	select {
+	case <-release:
+		return nil
+	case <-ctx.Done():
+		i.mu.Lock()
+		if i.release == release { // still waiting, so no longer arrived
+			i.arrived--
+		}
+		i.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// WaitFor blocks until the n-th callback's transaction has finished (committed or rolled back), or
+// ctx is done.
+func (i *Interleaving) WaitFor(ctx context.Context, n int) error {
+	select {
+	case <-i.done[n]:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// finish marks the n-th callback's transaction finished, releasing any barrier only it was
+// holding up.
+func (i *Interleaving) finish(n int) {
+	i.mu.Lock()
+	i.running--
+	if i.arrived > 0 {
+		i.releaseIfAllArrived()
+	}
+	i.mu.Unlock()
+	close(i.done[n])
+}
+
+func (i *Interleaving) releaseIfAllArrived() {
+	if i.arrived >= i.running {
+		close(i.release)
+		i.release = make(chan struct{})
+		i.arrived = 0
+	}
+}
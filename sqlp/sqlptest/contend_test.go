@@ -0,0 +1,128 @@
+package sqlptest
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/greghart/powerputtygo/errcmp"
+	"github.com/greghart/powerputtygo/sqlp"
+)
+
+func TestContend(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	// WAL, so readers don't block writers
+	db, err := sqlp.Open("sqlite3", filepath.Join(t.TempDir(), "contend.db")+"?_journal_mode=WAL")
+	if err != nil {
+		t.Fatalf("failed to open: %v", err)
+	}
+	defer db.Close()
+	db.MustExec(ctx, "CREATE TABLE counters (id INTEGER PRIMARY KEY, version INTEGER)")
+	db.MustExec(ctx, "INSERT INTO counters (version) VALUES (1)")
+
+	t.Run("interleaves deterministically", func(t *testing.T) {
+		var mu sync.Mutex
+		var events []string
+		log := func(event string) {
+			mu.Lock()
+			defer mu.Unlock()
+			events = append(events, event)
+		}
+		errs := Contend(ctx, db,
+			func(ctx context.Context, i *Interleaving) error {
+				log("a1")
+				i.Barrier(ctx)
+				i.Barrier(ctx) // b2 happens between barriers
+				log("a3")
+				return nil
+			},
+			func(ctx context.Context, i *Interleaving) error {
+				i.Barrier(ctx)
+				log("b2")
+				i.Barrier(ctx)
+				i.WaitFor(ctx, 0)
+				log("b4")
+				return nil
+			},
+		)
+		if !cmp.Equal(errs, []error{nil, nil}) {
+			t.Errorf("expected no errors, got %v", errs)
+		}
+		if expected := []string{"a1", "b2", "a3", "b4"}; !cmp.Equal(events, expected) {
+			t.Errorf("unexpected events:\n%v", cmp.Diff(expected, events))
+		}
+	})
+
+	t.Run("optimistic locking conflict", func(t *testing.T) {
+		read := func(ctx context.Context) (version int) {
+			if err := db.QueryRow(ctx, "SELECT version FROM counters WHERE id = 1").Scan(&version); err != nil {
+				t.Errorf("failed to read: %v", err)
+			}
+			return version
+		}
+		write := func(ctx context.Context, version int) error {
+			_, err := db.Exec(ctx, "UPDATE counters SET version = ? WHERE id = 1 AND version = ?", version+1, version)
+			return err
+		}
+		errs := Contend(ctx, db,
+			func(ctx context.Context, i *Interleaving) error {
+				version := read(ctx)
+				i.Barrier(ctx)
+				return write(ctx, version)
+			},
+			func(ctx context.Context, i *Interleaving) error {
+				version := read(ctx)
+				i.Barrier(ctx)
+				i.WaitFor(ctx, 0)
+				return write(ctx, version) // stale snapshot
+			},
+		)
+		errcmp.MustMatch(t, errs[0], "")
+		errcmp.MustMatch(t, errs[1], "database is locked")
+	})
+
+	t.Run("abandoned barriers aren't counted", func(t *testing.T) {
+		var mu sync.Mutex
+		var events []string
+		log := func(event string) {
+			mu.Lock()
+			defer mu.Unlock()
+			events = append(events, event)
+		}
+		errs := Contend(ctx, db,
+			func(ctx context.Context, i *Interleaving) error {
+				abandoned, cancel := context.WithCancel(ctx)
+				cancel()
+				errcmp.MustMatch(t, i.Barrier(abandoned), "context canceled")
+				i.Barrier(ctx)
+				log("a")
+				return nil
+			},
+			func(ctx context.Context, i *Interleaving) error {
+				time.Sleep(10 * time.Millisecond)
+				log("b")
+				return i.Barrier(ctx)
+			},
+		)
+		if !cmp.Equal(errs, []error{nil, nil}) {
+			t.Errorf("expected no errors, got %v", errs)
+		}
+		if expected := []string{"b", "a"}; !cmp.Equal(events, expected) {
+			t.Errorf("unexpected events:\n%v", cmp.Diff(expected, events))
+		}
+	})
+
+	t.Run("finished callbacks don't hold up barriers", func(t *testing.T) {
+		errs := Contend(ctx, db,
+			func(ctx context.Context, i *Interleaving) error { return nil },
+			func(ctx context.Context, i *Interleaving) error { return i.Barrier(ctx) },
+		)
+		if !cmp.Equal(errs, []error{nil, nil}) {
+			t.Errorf("expected no errors, got %v", errs)
+		}
+	})
+}
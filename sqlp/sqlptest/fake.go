@@ -0,0 +1,112 @@
+package sqlptest
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"reflect"
+	"time"
+
+	"github.com/greghart/powerputtygo/sqlp/internal/reflectp"
+)
+
+// Fake returns an entity with its columns filled with fake data, deterministic for a given seed.
+// The `id` column is left zero so the database can assign it, and nullable (pointer and sql.Null)
+// fields are occasionally left null. Nested structs that aren't columns are left alone.
+// Panics if E isn't a valid sqlp entity.
+func Fake[E any](seed uint64) E {
+	var e E
+	fields, err := reflectp.FieldsFactory(reflect.TypeOf(e))
+	if err != nil {
+		panic(fmt.Sprintf("sqlptest: failed to reflect fields for %T: %v", e, err))
+	}
+
+	// Columns are named as in a result set, with the prefixes of promoted structs
+	byField := make(map[*reflectp.Field]string, len(fields.ByColumnName))
+	for column, field := range fields.ByColumnName {
+		byField[field] = column
+	}
+
+	rng := rand.New(rand.NewPCG(seed, seed))
+	v := reflect.ValueOf(&e).Elem()
+	for _, field := range fields.Columns() { // in declaration order, so deterministic
+		column := byField[field]
+		if column == "id" {
+			continue
+		}
+		fake(rng, column, fieldByIndex(v, field.Index))
+	}
+	return e
+}
+
+// fakeEpoch is the base of fake times, so they're deterministic.
+var fakeEpoch = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// fake sets v to a fake value for the given column.
+func fake(rng *rand.Rand, column string, v reflect.Value) {
+	if v.Kind() == reflect.Pointer {
+		if rng.IntN(4) == 0 {
+			return // null
+		}
+		v.Set(reflect.New(v.Type().Elem()))
+		v = v.Elem()
+	}
+
+	switch {
+	case v.Type() == reflect.TypeFor[time.Time]():
+		offset := time.Duration(rng.Int64N(int64(365 * 24 * time.Hour)))
+		v.Set(reflect.ValueOf(fakeEpoch.Add(offset).Truncate(time.Second)))
+		return
+	case isNullType(v.Type()):
+		// sql.NullString and friends: a value field followed by Valid.
+		if rng.IntN(4) == 0 {
+			return
+		}
+		fake(rng, column, v.Field(0))
+		v.FieldByName("Valid").SetBool(true)
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(fmt.Sprintf("%s_%d", column, rng.IntN(100000)))
+	case reflect.Bool:
+		v.SetBool(rng.IntN(2) == 1)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v.SetInt(rng.Int64N(100) + 1)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v.SetUint(rng.Uint64N(100) + 1)
+	case reflect.Float32, reflect.Float64:
+		v.SetFloat(float64(rng.IntN(100000)) / 100)
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			b := make([]byte, 8)
+			for i := range b {
+				b[i] = byte(rng.UintN(256))
+			}
+			v.SetBytes(b)
+		}
+	}
+}
+
+// isNullType returns whether t looks like sql.NullString, sql.Null[T], etc.
+func isNullType(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct || t.NumField() != 2 {
+		return false
+	}
+	valid := t.Field(1)
+	return valid.Name == "Valid" && valid.Type.Kind() == reflect.Bool
+}
+
+// fieldByIndex returns the nested field of v, allocating any nil pointers along the way.
+func fieldByIndex(v reflect.Value, index []int) reflect.Value {
+	for i, fieldI := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(fieldI)
+	}
+	return v
+}
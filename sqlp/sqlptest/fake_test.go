@@ -0,0 +1,72 @@
+package sqlptest
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+)
+
+func TestFake(t *testing.T) {
+	type entity struct {
+		ID       int64          `sqlp:"id"`
+		Name     string         `sqlp:"name"`
+		Age      uint8          `sqlp:"age"`
+		Score    float64        `sqlp:"score"`
+		Active   bool           `sqlp:"active"`
+		Data     []byte         `sqlp:"data"`
+		Nickname *string        `sqlp:"nickname"`
+		Bio      sql.NullString `sqlp:"bio"`
+		Parent   *entity        `sqlp:"parent"`
+		timestamps
+	}
+
+	e := Fake[entity](1)
+	if e.ID != 0 {
+		t.Errorf("expected id left zero, got %d", e.ID)
+	}
+	if e.Name == "" || e.Age == 0 || len(e.Data) == 0 || e.CreatedAt.IsZero() || e.UpdatedAt.IsZero() {
+		t.Errorf("expected columns filled, got %+v", e)
+	}
+	if e.Parent != nil {
+		t.Errorf("expected nested struct left alone, got %+v", e.Parent)
+	}
+	if !EqualEntities(t, e, Fake[entity](1)) {
+		t.Errorf("expected same seed to be deterministic")
+	}
+	if Diff(e, Fake[entity](2)) == "" {
+		t.Errorf("expected different seeds to differ")
+	}
+
+	// Across seeds, nullable fields should be both null and not.
+	var nulls, values int
+	for seed := range uint64(50) {
+		e := Fake[entity](seed)
+		for _, isNull := range []bool{e.Nickname == nil, !e.Bio.Valid} {
+			if isNull {
+				nulls++
+			} else {
+				values++
+			}
+		}
+	}
+	if nulls == 0 || values == 0 {
+		t.Errorf("expected mix of nulls (%d) and values (%d)", nulls, values)
+	}
+	t.Run("promoted structs", func(t *testing.T) {
+		type owner struct {
+			ID   int64  `sqlp:"id"`
+			Name string `sqlp:"name"`
+		}
+		type pet struct {
+			ID    int64 `sqlp:"id"`
+			owner `sqlp:"owner,promote"`
+		}
+		p := Fake[pet](1)
+		if p.ID != 0 {
+			t.Errorf("expected id left zero, got %d", p.ID)
+		}
+		if p.owner.ID == 0 || !strings.HasPrefix(p.owner.Name, "owner_name_") {
+			t.Errorf("expected owner columns filled by their prefixed names, got %+v", p.owner)
+		}
+	})
+}
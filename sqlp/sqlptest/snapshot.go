@@ -0,0 +1,203 @@
+package sqlptest
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/greghart/powerputtygo/queryp"
+	"github.com/greghart/powerputtygo/sqlp"
+)
+
+// TableSnapshot is an in memory copy of some tables' rows, to restore between tests that mutate
+// reference data. It's intended for small tables, and works the same for any database.
+// Values round trip through the driver, so equal values may be stored differently after a restore
+// (eg. SQLite timestamps are rewritten in the driver's format).
+//
+//	snapshot, err := sqlptest.Snapshot(ctx, db, "countries", "regions")
+//	t.Cleanup(func() { snapshot.Restore(ctx) })
+type TableSnapshot struct {
+	db     *sqlp.DB
+	tables []tableRows
+}
+
+type tableRows struct {
+	table   string
+	columns []string
+	rows    [][]any
+}
+
+// Snapshot copies the rows of tables, which may be schema qualified (eg. "public.regions"). Tables
+// can be given in any order: they're ordered by their foreign keys (as introspected for Postgres,
+// SQLite and MySQL), so Restore deletes and reinserts them in a valid order. Unknown dialects keep
+// the given order, so give tables with foreign keys after the tables they reference.
+func Snapshot(ctx context.Context, db *sqlp.DB, tables ...string) (*TableSnapshot, error) {
+	tables, err := orderByReferences(ctx, db, tables)
+	if err != nil {
+		return nil, err
+	}
+	s := &TableSnapshot{db: db}
+	for _, table := range tables {
+		rows, err := db.Query(ctx, "SELECT * FROM "+table)
+		if err != nil {
+			return nil, fmt.Errorf("sqlptest: failed to snapshot %s: %w", table, err)
+		}
+		snapshot := tableRows{table: table}
+		if snapshot.columns, err = rows.Columns(); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("sqlptest: failed to snapshot %s: %w", table, err)
+		}
+		for rows.Next() {
+			row := make([]any, len(snapshot.columns))
+			dest := make([]any, len(row))
+			for i := range row {
+				dest[i] = &row[i]
+			}
+			if err := rows.Scan(dest...); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("sqlptest: failed to snapshot %s: %w", table, err)
+			}
+			snapshot.rows = append(snapshot.rows, row)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("sqlptest: failed to snapshot %s: %w", table, err)
+		}
+		s.tables = append(s.tables, snapshot)
+	}
+	return s, nil
+}
+
+// Restore deletes the current rows of the snapshot's tables and reinserts the snapshotted ones, in
+// one transaction. SQLite defers foreign key checks to its commit, so even rows referencing each
+// other (eg. a table referencing itself) restore in any order.
+func (s *TableSnapshot) Restore(ctx context.Context) error {
+	d := s.db.Dialect()
+	return s.db.RunInTx(ctx, func(ctx context.Context) error {
+		if d == queryp.Sqlite {
+			if _, err := s.db.Exec(ctx, "PRAGMA defer_foreign_keys = ON"); err != nil {
+				return fmt.Errorf("sqlptest: failed to defer foreign keys: %w", err)
+			}
+		}
+		for i := len(s.tables) - 1; i >= 0; i-- {
+			if _, err := s.db.Exec(ctx, "DELETE FROM "+s.tables[i].table); err != nil {
+				return fmt.Errorf("sqlptest: failed to restore %s: %w", s.tables[i].table, err)
+			}
+		}
+		for _, t := range s.tables {
+			columns := make([]string, len(t.columns))
+			for i, column := range t.columns {
+				columns[i] = d.QuoteIdent(column)
+			}
+			for _, row := range t.rows {
+				args := queryp.NewArgs().WithPlaceholderer(d.Placeholderer())
+				placeholders := make([]string, len(row))
+				for i, v := range row {
+					placeholders[i] = args.Add(v)
+				}
+				q := "INSERT INTO " + t.table + " (" + strings.Join(columns, ", ") + ") VALUES (" + strings.Join(placeholders, ", ") + ")"
+				if _, err := s.db.Exec(ctx, q, args.Args()...); err != nil {
+					return fmt.Errorf("sqlptest: failed to restore %s: %w", t.table, err)
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// orderByReferences orders tables so each comes after the tables it references with foreign keys,
+// keeping the given order otherwise. References outside of tables are ignored, and tables in a
+// cycle keep their given order.
+func orderByReferences(ctx context.Context, db *sqlp.DB, tables []string) ([]string, error) {
+	references := map[string][]string{}
+	for _, table := range tables {
+		refs, err := referencedTables(ctx, db, table)
+		if err != nil {
+			return nil, fmt.Errorf("sqlptest: failed to introspect %s: %w", table, err)
+		}
+		references[table] = refs
+	}
+
+	ordered := make([]string, 0, len(tables))
+	done := map[string]bool{}
+	visiting := map[string]bool{}
+	var visit func(table string)
+	visit = func(table string) {
+		if done[table] || visiting[table] {
+			return // visited, or a cycle
+		}
+		visiting[table] = true
+		for _, ref := range references[table] {
+			if slices.Contains(tables, ref) {
+				visit(ref)
+			}
+		}
+		visiting[table] = false
+		done[table] = true
+		ordered = append(ordered, table)
+	}
+	for _, table := range tables {
+		visit(table)
+	}
+	return ordered, nil
+}
+
+// referencedTables returns the tables table references with foreign keys, or none if the dialect
+// is unknown. A table may be schema qualified, and is otherwise in the current schema. References
+// are qualified by their schema, and also unqualified when in the current schema, so they match
+// tables given either way.
+func referencedTables(ctx context.Context, db *sqlp.DB, table string) ([]string, error) {
+	schema, name := "", table
+	if s, n, ok := strings.Cut(table, "."); ok {
+		schema, name = s, n
+	}
+	var refs []struct {
+		Schema  string `sqlp:"ref_schema"`
+		Table   string `sqlp:"ref_table"`
+		Current bool   `sqlp:"in_current"`
+	}
+	var err error
+	switch db.Dialect() {
+	case queryp.Sqlite:
+		if schema == "" {
+			schema = "main"
+		}
+		// SQLite's foreign keys can only reference tables of their own schema
+		err = db.Select(ctx, &refs, `
+			SELECT DISTINCT ? AS ref_schema, "table" AS ref_table, ? = 'main' AS in_current
+			FROM pragma_foreign_key_list(?, ?)
+		`, schema, schema, name, schema)
+	case queryp.Postgres:
+		err = db.Select(ctx, &refs, `
+			SELECT DISTINCT
+				ccu.table_schema AS ref_schema, ccu.table_name AS ref_table,
+				ccu.table_schema = current_schema() AS in_current
+			FROM information_schema.table_constraints tc
+			JOIN information_schema.constraint_column_usage ccu
+				ON ccu.constraint_schema = tc.constraint_schema AND ccu.constraint_name = tc.constraint_name
+			WHERE tc.constraint_type = 'FOREIGN KEY'
+				AND tc.table_schema = COALESCE(NULLIF($1, ''), current_schema()) AND tc.table_name = $2
+		`, schema, name)
+	case queryp.MySQL:
+		err = db.Select(ctx, &refs, `
+			SELECT DISTINCT
+				referenced_table_schema AS ref_schema, referenced_table_name AS ref_table,
+				referenced_table_schema = DATABASE() AS in_current
+			FROM information_schema.key_column_usage
+			WHERE table_schema = COALESCE(NULLIF(?, ''), DATABASE()) AND table_name = ?
+				AND referenced_table_name IS NOT NULL
+		`, schema, name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var tables []string
+	for _, ref := range refs {
+		tables = append(tables, ref.Schema+"."+ref.Table)
+		if ref.Current {
+			tables = append(tables, ref.Table)
+		}
+	}
+	return tables, nil
+}
@@ -0,0 +1,87 @@
+package sqlptest
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/greghart/powerputtygo/sqlp"
+)
+
+func TestSnapshot(t *testing.T) {
+	ctx := context.Background()
+	db, err := sqlp.Open("sqlite3", filepath.Join(t.TempDir(), "snapshot.db")+"?_foreign_keys=on")
+	if err != nil {
+		t.Fatalf("failed to open: %v", err)
+	}
+	defer db.Close()
+	db.MustExec(ctx, `
+		CREATE TABLE countries (id INTEGER PRIMARY KEY, name TEXT, founded TIMESTAMP);
+		CREATE TABLE regions (id INTEGER PRIMARY KEY, country_id INTEGER REFERENCES countries (id), name TEXT);
+		INSERT INTO countries (name, founded) VALUES ('Atlantis', '1900-01-01 00:00:00'), ('Lemuria', NULL);
+		INSERT INTO regions (country_id, name) VALUES (1, 'Coast'), (2, 'Peak');
+	`)
+	dump := func() []string {
+		t.Helper()
+		var rows []string
+		for _, q := range []string{
+			"SELECT id || ' ' || name || ' ' || COALESCE(datetime(founded), 'null') FROM countries ORDER BY id",
+			"SELECT id || ' ' || country_id || ' ' || name FROM regions ORDER BY id",
+		} {
+			r, err := db.Query(ctx, q)
+			if err != nil {
+				t.Fatalf("failed to dump: %v", err)
+			}
+			for r.Next() {
+				var row string
+				if err := r.Scan(&row); err != nil {
+					t.Fatalf("failed to scan: %v", err)
+				}
+				rows = append(rows, row)
+			}
+			r.Close()
+		}
+		return rows
+	}
+	expected := dump()
+
+	snapshot, err := Snapshot(ctx, db, "regions", "countries")
+	if err != nil {
+		t.Fatalf("failed to snapshot: %v", err)
+	}
+	var order []string
+	for _, table := range snapshot.tables {
+		order = append(order, table.table)
+	}
+	if expected := []string{"countries", "regions"}; !cmp.Equal(order, expected) {
+		t.Errorf("expected tables ordered by foreign keys:\n%v", cmp.Diff(expected, order))
+	}
+	db.MustExec(ctx, "DELETE FROM regions WHERE id = 1; UPDATE countries SET name = 'Mu' WHERE id = 2")
+	db.MustExec(ctx, "INSERT INTO countries (name) VALUES ('Hyperborea')")
+
+	if err := snapshot.Restore(ctx); err != nil {
+		t.Fatalf("failed to restore: %v", err)
+	}
+	if got := dump(); !cmp.Equal(got, expected) {
+		t.Errorf("unexpected restored rows:\n%v", cmp.Diff(expected, got))
+	}
+
+	t.Run("schema qualified", func(t *testing.T) {
+		snapshot, err := Snapshot(ctx, db, "main.regions", "main.countries")
+		if err != nil {
+			t.Fatalf("failed to snapshot: %v", err)
+		}
+		var order []string
+		for _, table := range snapshot.tables {
+			order = append(order, table.table)
+		}
+		if expected := []string{"main.countries", "main.regions"}; !cmp.Equal(order, expected) {
+			t.Errorf("expected tables ordered by foreign keys:\n%v", cmp.Diff(expected, order))
+		}
+	})
+
+	if _, err := Snapshot(ctx, db, "nope"); err == nil {
+		t.Errorf("expected unknown table to error")
+	}
+}
@@ -0,0 +1,165 @@
+package sqlp
+
+import (
+	"container/list"
+	"context"
+	"database/sql"
+	"strings"
+	"sync"
+)
+
+// StmtCacheStats are stats of a DB's prepared statement cache, see WithStmtCache.
+type StmtCacheStats struct {
+	Hits      int64
+	Misses    int64
+	Evictions int64
+	Size      int // Current number of cached statements
+}
+
+// stmtCache is a least recently used cache of prepared statements by query. A *sql.Stmt prepares
+// itself on each connection it's used on, and transactions reuse the connection's statement, so
+// entries are per query rather than per query and connection.
+type stmtCache struct {
+	size    int
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List // Of *stmtEntry, most recently used first
+	stats   StmtCacheStats
+}
+
+type stmtEntry struct {
+	query   string
+	stmt    *sql.Stmt
+	refs    int  // Callers using stmt, see get
+	evicted bool // Whether stmt is to be closed once it's no longer used
+}
+
+func newStmtCache(size int) *stmtCache {
+	return &stmtCache{size: size, entries: map[string]*list.Element{}, lru: list.New()}
+}
+
+// get returns the prepared statement for query (on tx, if any), preparing it on a miss, and a
+// release function to call once the statement has run. Evicted statements are only closed once released, and once
+// they're done with any open rows (which database/sql sees to).
+// Misses in a transaction are prepared on it rather than cached, since preparing on the pool would
+// take a second connection (deadlocking pools of one, as is common for SQLite).
+func (c *stmtCache) get(ctx context.Context, db *sql.DB, tx *sql.Tx, query string) (*sql.Stmt, func(), error) {
+	c.mu.Lock()
+	if e, ok := c.entries[query]; ok {
+		c.lru.MoveToFront(e)
+		c.stats.Hits++
+		entry := e.Value.(*stmtEntry)
+		entry.refs++
+		c.mu.Unlock()
+		if tx != nil {
+			return tx.StmtContext(ctx, entry.stmt), c.releaser(entry), nil
+		}
+		return entry.stmt, c.releaser(entry), nil
+	}
+	c.stats.Misses++
+	c.mu.Unlock()
+
+	if tx != nil {
+		stmt, err := tx.PrepareContext(ctx, query)
+		if err != nil {
+			return nil, nil, err
+		}
+		return stmt, func() {}, nil // The transaction closes it when it's done
+	}
+
+	// Prepare outside the lock, concurrent misses may both prepare, keeping the first.
+	stmt, err := db.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.entries[query]; ok {
+		stmt.Close()
+		entry := e.Value.(*stmtEntry)
+		entry.refs++
+		return entry.stmt, c.releaser(entry), nil
+	}
+	entry := &stmtEntry{query: query, stmt: stmt, refs: 1}
+	c.entries[query] = c.lru.PushFront(entry)
+	if c.lru.Len() > c.size {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*stmtEntry).query)
+		c.evict(oldest.Value.(*stmtEntry))
+		c.stats.Evictions++
+	}
+	return stmt, c.releaser(entry), nil
+}
+
+// releaser returns a function releasing a use of entry, closing it if it was evicted meanwhile.
+func (c *stmtCache) releaser(entry *stmtEntry) func() {
+	return func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		entry.refs--
+		if entry.evicted && entry.refs == 0 {
+			entry.stmt.Close()
+		}
+	}
+}
+
+// evict closes entry's statement, or marks it to be closed once it's released if it's in use.
+// Callers must hold c.mu.
+func (c *stmtCache) evict(entry *stmtEntry) {
+	entry.evicted = true
+	if entry.refs == 0 {
+		entry.stmt.Close()
+	}
+}
+
+// close closes and forgets all cached statements, once they're released.
+func (c *stmtCache) close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for e := c.lru.Front(); e != nil; e = e.Next() {
+		c.evict(e.Value.(*stmtEntry))
+	}
+	c.entries = map[string]*list.Element{}
+	c.lru.Init()
+}
+
+// StmtCacheStats returns stats of the prepared statement cache, or zero stats if there is none.
+func (db *DB) StmtCacheStats() StmtCacheStats {
+	if db.stmts == nil {
+		return StmtCacheStats{}
+	}
+	db.stmts.mu.Lock()
+	defer db.stmts.mu.Unlock()
+	stats := db.stmts.stats
+	stats.Size = db.stmts.lru.Len()
+	return stats
+}
+
+// stmt returns the cached prepared statement for query (on the context's transaction, if any), and
+// a release function to call once it has run, or a nil statement if there's no cache, in which case
+// the query should run unprepared.
+// Queries with several statements aren't cached, since drivers only prepare the first.
+func (db *DB) stmt(ctx context.Context, query string) (*sql.Stmt, func()) {
+	if db.stmts == nil || strings.Contains(query, ";") {
+		return nil, nil
+	}
+	tx := db.txContext(ctx)
+	if tx == nil && db.pinnedConn(ctx) != nil {
+		return nil, nil // Statements can't be moved onto a connection, unlike onto a transaction
+	}
+	stmt, release, err := db.stmts.get(ctx, db.DB, tx, query)
+	if err != nil {
+		return nil, nil // Running it unprepared reports the error
+	}
+	return stmt, release
+}
+
+// Close closes any cached prepared statements and the database.
+func (db *DB) Close() error {
+	if db.stmts != nil {
+		db.stmts.close()
+	}
+	return db.DB.Close()
+}
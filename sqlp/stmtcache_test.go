@@ -0,0 +1,117 @@
+package sqlp
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWithStmtCache(t *testing.T) {
+	sqlDB, ctx, cleanup := testDB(t)
+	defer cleanup()
+	grandparent := grandchildrenSetup(ctx, sqlDB)
+	db := NewDB(sqlDB.DB, WithStmtCache(2))
+
+	for range 3 {
+		if _, err := Get[person](ctx, db, "SELECT id, first_name FROM people WHERE id = ?", grandparent.ID); err != nil {
+			t.Fatalf("failed to get: %v", err)
+		}
+	}
+	if _, err := db.Exec(ctx, "UPDATE people SET last_name = ? WHERE id = ?", "Doe", grandparent.ID); err != nil {
+		t.Fatalf("failed to exec: %v", err)
+	}
+	err := db.RunInTx(ctx, func(ctx context.Context) error {
+		var name string
+		if err := db.QueryRow(ctx, "SELECT first_name FROM people WHERE id = ?", grandparent.ID).Scan(&name); err != nil {
+			return err
+		}
+		_, err := db.Exec(ctx, "UPDATE people SET last_name = ? WHERE id = ?", "Doe", grandparent.ID)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("failed to run in tx: %v", err)
+	}
+	// Several statements aren't cached
+	db.MustExec(ctx, "SELECT 1; SELECT 2")
+
+	// The miss in the transaction is prepared on it, rather than cached
+	expected := StmtCacheStats{Hits: 3, Misses: 3, Evictions: 0, Size: 2}
+	if diff := cmp.Diff(expected, db.StmtCacheStats()); diff != "" {
+		t.Errorf("unexpected stats:\n%v", diff)
+	}
+	if diff := cmp.Diff(StmtCacheStats{}, sqlDB.StmtCacheStats()); diff != "" {
+		t.Errorf("unexpected stats without cache:\n%v", diff)
+	}
+}
+
+func TestWithStmtCache_Concurrency(t *testing.T) {
+	sqlDB, ctx, cleanup := testDB(t)
+	defer cleanup()
+	grandchildrenSetup(ctx, sqlDB)
+
+	t.Run("misses in a transaction -> prepared on it", func(t *testing.T) {
+		db := NewDB(sqlDB.DB, WithStmtCache(2))
+		db.SetMaxOpenConns(1) // Preparing on the pool would wait on the transaction's connection
+		defer db.SetMaxOpenConns(0)
+		err := db.RunInTx(ctx, func(ctx context.Context) error {
+			_, err := Select[person](ctx, db, "SELECT * FROM people WHERE last_name = ?", "Doe")
+			return err
+		})
+		if err != nil {
+			t.Errorf("failed to run in tx: %v", err)
+		}
+	})
+
+	t.Run("evictions wait on statements in use", func(t *testing.T) {
+		db := NewDB(sqlDB.DB, WithStmtCache(1))
+		var wg sync.WaitGroup
+		errs := make(chan error, 8)
+		for i := range 8 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := range 100 {
+					// Alternate queries so each evicts the other
+					q := fmt.Sprintf("SELECT COUNT(*) FROM people WHERE id > %d", (i+j)%2)
+					var n int
+					if err := db.QueryRow(ctx, q).Scan(&n); err != nil {
+						errs <- err
+						return
+					}
+				}
+			}()
+		}
+		wg.Wait()
+		close(errs)
+		for err := range errs {
+			t.Errorf("failed to query: %v", err)
+		}
+		if stats := db.StmtCacheStats(); stats.Evictions == 0 {
+			t.Errorf("expected evictions, got %+v", stats)
+		}
+	})
+}
+
+func BenchmarkStmtCache(b *testing.B) {
+	sqlDB, ctx, cleanup := testDB(b)
+	defer cleanup()
+	grandparent := grandchildrenSetup(ctx, sqlDB)
+
+	for name, db := range map[string]*DB{
+		"unprepared": sqlDB,
+		"cached":     NewDB(sqlDB.DB, WithStmtCache(16)),
+	} {
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				var name string
+				if err := db.QueryRow(ctx, "SELECT first_name FROM people WHERE id = ?", grandparent.ID).Scan(&name); err != nil {
+					b.Fatalf("failed to query: %v", err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package sqlp
+
+import (
+	"context"
+	"fmt"
+)
+
+// Result is one value streamed by SelectChan: either a scanned entity, or the terminal error.
+type Result[E any] struct {
+	Entity E
+	Err    error
+}
+
+// SelectChan runs a query and streams its scanned entities on the returned channel, scanning on a
+// goroutine so it runs ahead of slower consumers (eg. ETL pipelines). Errors running the query are
+// returned directly, while a failure scanning is sent as a final Result with Err set. The channel
+// is closed once the results are done.
+//
+// Canceling ctx stops the query and closes the channel, so consumers that stop reading early
+// should cancel it to release the goroutine and its connection.
+//
+//	people, err := sqlp.SelectChan[person](ctx, db, "SELECT * FROM people")
+//	if err != nil {
+//		return err
+//	}
+//	for res := range people {
+//		if res.Err != nil {
+//			return res.Err
+//		}
+//		...
+//	}
+func SelectChan[E any](ctx context.Context, db *DB, query string, args ...any) (<-chan Result[E], error) {
+	ctx, cancel := db.timeoutContext(ctx)
+	rows, err := db.Query(ctx, query, args...)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	scanner, err := newReflectScanner[E](rows, ignoredColumns(ctx), db.normalization)
+	if err != nil {
+		rows.Close()
+		cancel()
+		return nil, fmt.Errorf("failed to get reflect scanner: %w", err)
+	}
+
+	ch := make(chan Result[E])
+	go func() {
+		defer cancel()
+		defer close(ch)
+		defer rows.Close()
+		send := func(res Result[E]) error {
+			select {
+			case ch <- res:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		err := scanEach(ctx, db, rows, scanner, func(e E) error {
+			return send(Result[E]{Entity: e})
+		})
+		if err != nil && ctx.Err() == nil {
+			send(Result[E]{Err: err}) // nolint:errcheck
+		}
+	}()
+	return ch, nil
+}
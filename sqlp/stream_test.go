@@ -0,0 +1,95 @@
+package sqlp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/greghart/powerputtygo/errcmp"
+)
+
+func TestSelectChan(t *testing.T) {
+	db, ctx, cleanup := testDB(t)
+	defer cleanup()
+
+	grandparent := grandchildrenSetup(ctx, db)
+
+	t.Run("streams each row", func(t *testing.T) {
+		ch, err := SelectChan[person](ctx, db, "SELECT id, first_name FROM people WHERE last_name = ?", "Doe")
+		if err != nil {
+			t.Fatalf("failed to select: %v", err)
+		}
+		var people []person
+		for res := range ch {
+			if res.Err != nil {
+				t.Fatalf("failed to select: %v", res.Err)
+			}
+			people = append(people, res.Entity)
+		}
+		expected := []person{
+			{ID: grandparent.ID, FirstName: "John"},
+			{ID: grandparent.Child.ID, FirstName: "Lil Johnnie"},
+			{ID: grandparent.Child.Child.ID, FirstName: "Lil Lil Johnnie"},
+		}
+		if !cmp.Equal(people, expected, personComparer) {
+			t.Errorf("selected people unexpected:\n%v", cmp.Diff(expected, people, personComparer))
+		}
+	})
+
+	t.Run("query error -> err", func(t *testing.T) {
+		ch, err := SelectChan[person](ctx, db, "SELECT nope FROM people")
+		errcmp.MustMatch(t, err, "no such column: nope")
+		if ch != nil {
+			t.Errorf("expected no channel")
+		}
+	})
+
+	t.Run("scan error -> terminal result", func(t *testing.T) {
+		ch, err := SelectChan[person](ctx, db, "SELECT first_name AS id FROM people")
+		if err != nil {
+			t.Fatalf("failed to select: %v", err)
+		}
+		var results []Result[person]
+		for res := range ch {
+			results = append(results, res)
+		}
+		if len(results) != 1 {
+			t.Fatalf("expected 1 result, got %v", results)
+		}
+		errcmp.MustMatch(t, results[0].Err, "failed to scan row")
+	})
+
+	t.Run("consumer slower than scanning", func(t *testing.T) {
+		ch, err := SelectChan[person](ctx, db, "SELECT id FROM people")
+		if err != nil {
+			t.Fatalf("failed to select: %v", err)
+		}
+		n := 0
+		for res := range ch {
+			if res.Err != nil {
+				t.Fatalf("failed to select: %v", res.Err)
+			}
+			time.Sleep(5 * time.Millisecond)
+			n++
+		}
+		if n != 3 {
+			t.Errorf("expected 3 people, got %d", n)
+		}
+	})
+
+	t.Run("canceled -> closes", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(ctx)
+		ch, err := SelectChan[person](ctx, db, "SELECT id, first_name FROM people")
+		if err != nil {
+			t.Fatalf("failed to select: %v", err)
+		}
+		<-ch
+		cancel()
+		for res := range ch {
+			if res.Err != nil {
+				t.Errorf("expected no error after cancel, got %v", res.Err)
+			}
+		}
+	})
+}
@@ -0,0 +1,24 @@
+package sqlp
+
+import (
+	"context"
+	"runtime"
+)
+
+// timeoutContext returns ctx with the default timeout applied, unless it already has a deadline,
+// see WithDefaultTimeout.
+func (db *DB) timeoutContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if db.defaultTimeout <= 0 {
+		return ctx, func() {}
+	}
+	if _, ok := ctx.Deadline(); ok {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, db.defaultTimeout)
+}
+
+// cancelOnCollect releases a timeout once v, the rows or row it covers, is collected. database/sql
+// keeps rows reachable until they're closed, so this is once they're closed and dropped.
+func cancelOnCollect[T any](v *T, cancel context.CancelFunc) {
+	runtime.AddCleanup(v, func(cancel context.CancelFunc) { cancel() }, cancel)
+}
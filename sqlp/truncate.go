@@ -0,0 +1,132 @@
+package sqlp
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/greghart/powerputtygo/queryp"
+)
+
+// DeleteAllOption configures Repository.DeleteAll and Truncate.
+type DeleteAllOption int
+
+const (
+	// IUnderstandThisDeletesEverything must be given to DeleteAll and Truncate, so deleting a whole
+	// table is never an accident.
+	IUnderstandThisDeletesEverything DeleteAllOption = iota + 1
+	// ResetIdentity also restarts the table's auto incrementing ids.
+	// MySQL always does so on Truncate, and DeleteAll assumes an `id` primary key on Postgres.
+	ResetIdentity
+)
+
+// DeleteAll deletes every row of the repository's table with `DELETE FROM`, returning the number of
+// rows deleted. Unlike Truncate, this fires triggers, and is transactional everywhere.
+// It requires IUnderstandThisDeletesEverything, and refuses to run outside of tests unless the DB
+// was opened WithDeleteAllOutsideTests.
+func (r *Repository[E]) DeleteAll(ctx context.Context, opts ...DeleteAllOption) (int64, error) {
+	reset, err := r.checkDeleteAll("DeleteAll", opts)
+	if err != nil {
+		return 0, err
+	}
+	var deleted int64
+	err = r.RunInTx(ctx, func(ctx context.Context) error {
+		var err error
+		q, args := "DELETE FROM "+r.QualifiedTable(), []any(nil)
+		if r.discriminator != nil {
+			where, scoped := r.where(nil)
+			q, args = q+" WHERE "+where, scoped
+		}
+		if deleted, err = r.ExecRows(ctx, q, args...); err != nil {
+			return err
+		}
+		if !reset {
+			return nil
+		}
+		switch r.Dialect() {
+		case queryp.Postgres:
+			_, err = r.Exec(ctx, "SELECT setval(pg_get_serial_sequence($1, 'id'), 1, false)", r.QualifiedTable())
+		case queryp.MySQL:
+			_, err = r.Exec(ctx, "ALTER TABLE "+r.QualifiedTable()+" AUTO_INCREMENT = 1")
+		case queryp.Sqlite:
+			err = r.resetSqliteSequence(ctx)
+		default:
+			err = r.errUnknownDialect("DeleteAll")
+		}
+		return err
+	})
+	return deleted, err
+}
+
+// Truncate deletes every row of the repository's table, as quickly as the dialect allows:
+// `TRUNCATE TABLE` on Postgres and MySQL, and `DELETE FROM` on SQLite (which has no TRUNCATE).
+// It requires IUnderstandThisDeletesEverything, and refuses to run outside of tests unless the DB
+// was opened WithDeleteAllOutsideTests.
+func (r *Repository[E]) Truncate(ctx context.Context, opts ...DeleteAllOption) error {
+	reset, err := r.checkDeleteAll("Truncate", opts)
+	if err != nil {
+		return err
+	}
+	switch r.Dialect() {
+	case queryp.Postgres:
+		q := "TRUNCATE TABLE " + r.QualifiedTable()
+		if reset {
+			q += " RESTART IDENTITY"
+		}
+		_, err = r.Exec(ctx, q)
+	case queryp.MySQL:
+		_, err = r.Exec(ctx, "TRUNCATE TABLE "+r.QualifiedTable())
+	case queryp.Sqlite:
+		err = r.RunInTx(ctx, func(ctx context.Context) error {
+			if _, err := r.Exec(ctx, "DELETE FROM "+r.QualifiedTable()); err != nil || !reset {
+				return err
+			}
+			return r.resetSqliteSequence(ctx)
+		})
+	default:
+		err = r.errUnknownDialect("Truncate")
+	}
+	return err
+}
+
+// checkDeleteAll checks deleting everything is allowed, returning whether to reset identity.
+func (r *Repository[E]) checkDeleteAll(helper string, opts []DeleteAllOption) (bool, error) {
+	if err := r.checkWritable(helper); err != nil {
+		return false, err
+	}
+	confirmed, reset := false, false
+	for _, opt := range opts {
+		switch opt {
+		case IUnderstandThisDeletesEverything:
+			confirmed = true
+		case ResetIdentity:
+			reset = true
+		}
+	}
+	if !confirmed {
+		return false, fmt.Errorf("%w: %s of %s requires IUnderstandThisDeletesEverything", ErrDeleteAllRefused, helper, r.table)
+	}
+	if r.discriminator != nil && (reset || helper == "Truncate") {
+		return false, fmt.Errorf("%w: %s of %s would affect rows of other types, use DeleteAll without ResetIdentity", ErrDeleteAllRefused, helper, r.table)
+	}
+	if !testing.Testing() && !r.deleteAllOutsideTests {
+		return false, fmt.Errorf("%w: %s of %s outside of tests requires WithDeleteAllOutsideTests", ErrDeleteAllRefused, helper, r.table)
+	}
+	return reset, nil
+}
+
+// resetSqliteSequence resets the table's AUTOINCREMENT sequence, if there is one.
+// Plain INTEGER PRIMARY KEY ids already restart once the table is empty.
+func (r *Repository[E]) resetSqliteSequence(ctx context.Context) error {
+	schema := ""
+	if r.schema != "" {
+		schema = r.Dialect().QuoteIdent(r.schema) + "."
+	}
+	var exists int
+	err := r.QueryRow(ctx, "SELECT COUNT(*) FROM "+schema+"sqlite_master WHERE type = 'table' AND name = 'sqlite_sequence'").Scan(&exists)
+	if err != nil || exists == 0 {
+		return err
+	}
+	_, err = r.Exec(ctx, "DELETE FROM "+schema+"sqlite_sequence WHERE name = ?", r.table)
+	return err
+}
@@ -0,0 +1,66 @@
+package sqlp
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/greghart/powerputtygo/errcmp"
+)
+
+func TestRepository_DeleteAll(t *testing.T) {
+	db, ctx, cleanup := testDB(t)
+	defer cleanup()
+	repository := NewRepository[person](db, "people")
+	grandchildrenSetup(ctx, db)
+
+	_, err := repository.DeleteAll(ctx)
+	if !errors.Is(err, ErrDeleteAllRefused) {
+		t.Fatalf("expected refusal without confirmation, got %v", err)
+	}
+	errcmp.MustMatch(t, err, "DeleteAll of people requires IUnderstandThisDeletesEverything")
+
+	deleted, err := repository.DeleteAll(ctx, IUnderstandThisDeletesEverything, ResetIdentity)
+	if err != nil || deleted != 3 {
+		t.Fatalf("expected 3 deleted, got %v, %v", deleted, err)
+	}
+	if id := must(db.ExecID(ctx, "INSERT INTO people (first_name) VALUES (?)", "John")); id != 1 {
+		t.Errorf("expected identity reset, got id %v", id)
+	}
+}
+
+func TestRepository_Truncate(t *testing.T) {
+	db, ctx, cleanup := testDB(t)
+	defer cleanup()
+	// AUTOINCREMENT ids don't restart on their own, unlike plain INTEGER PRIMARY KEY ones
+	db.MustExec(ctx, "CREATE TABLE counters (id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT)")
+	defer db.MustExec(ctx, "DROP TABLE counters")
+	type counter struct {
+		ID   int64  `sqlp:"id"`
+		Name string `sqlp:"name"`
+	}
+	repository := NewRepository[counter](db, "counters")
+	insert := func() int64 {
+		return must(db.ExecID(ctx, "INSERT INTO counters (name) VALUES (?)", "hits"))
+	}
+	insert()
+	insert()
+
+	err := repository.Truncate(ctx, ResetIdentity)
+	errcmp.MustMatch(t, err, "refusing to delete everything: Truncate of counters requires IUnderstandThisDeletesEverything")
+
+	if err := repository.Truncate(ctx, IUnderstandThisDeletesEverything); err != nil {
+		t.Fatalf("failed to truncate: %v", err)
+	}
+	if id := insert(); id != 3 {
+		t.Errorf("expected identity kept without ResetIdentity, got id %v", id)
+	}
+	if err := repository.Truncate(ctx, IUnderstandThisDeletesEverything, ResetIdentity); err != nil {
+		t.Fatalf("failed to truncate: %v", err)
+	}
+	if id := insert(); id != 1 {
+		t.Errorf("expected identity reset, got id %v", id)
+	}
+	if n := must(Select[counter](ctx, db, "SELECT * FROM counters")); len(n) != 1 {
+		t.Errorf("expected only the new counter, got %v", n)
+	}
+}
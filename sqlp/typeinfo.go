@@ -0,0 +1,64 @@
+package sqlp
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+
+	"github.com/greghart/powerputtygo/sqlp/internal/reflectp"
+)
+
+// EntityInfo describes how sqlp maps an entity type to columns, see TypeInfo.
+type EntityInfo struct {
+	Type    reflect.Type
+	Columns []ColumnInfo // In field declaration order
+}
+
+// ColumnInfo describes a column of an entity, and the field it's scanned into.
+type ColumnInfo struct {
+	Name   string
+	Path   []int        // Index path of the field, see reflect.Value.FieldByIndex
+	Type   reflect.Type // Type of the field
+	Tagged bool         // Whether the column is named by a tag, rather than the field name
+	Gzip   bool         // Whether the column is stored compressed
+}
+
+// Column returns the info of the named column, if E has it.
+func (e *EntityInfo) Column(name string) (ColumnInfo, bool) {
+	i := slices.IndexFunc(e.Columns, func(c ColumnInfo) bool { return c.Name == name })
+	if i < 0 {
+		return ColumnInfo{}, false
+	}
+	return e.Columns[i], true
+}
+
+// TypeInfo returns how E is mapped to columns, as sqlp itself scans it (including promoted
+// embedded structs), eg. for validators or generators that want to reuse the same mapping rather
+// than parse tags themselves. Associations (nested structs scanned through prefixed columns) aren't
+// included. The info is a copy, so changing it has no effect on sqlp.
+//
+//	info, err := sqlp.TypeInfo[person]()
+//	for _, column := range info.Columns { ... }
+func TypeInfo[E any]() (*EntityInfo, error) {
+	t := reflect.TypeFor[E]()
+	fields, err := reflectp.FieldsFactory(t)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reflect fields for %v: %w", t, err)
+	}
+	byField := make(map[*reflectp.Field]string, len(fields.ByColumnName))
+	for column, field := range fields.ByColumnName {
+		byField[field] = column
+	}
+
+	info := &EntityInfo{Type: t}
+	for _, field := range fields.Columns() {
+		info.Columns = append(info.Columns, ColumnInfo{
+			Name:   byField[field],
+			Path:   slices.Clone(field.Index),
+			Type:   field.Type,
+			Tagged: field.Tag,
+			Gzip:   field.Gzip,
+		})
+	}
+	return info, nil
+}
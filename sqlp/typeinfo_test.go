@@ -0,0 +1,52 @@
+package sqlp
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/greghart/powerputtygo/errcmp"
+)
+
+func TestTypeInfo(t *testing.T) {
+	info, err := TypeInfo[person]()
+	if err != nil {
+		t.Fatalf("TypeInfo: %v", err)
+	}
+	timeType, stringType := reflect.TypeFor[time.Time](), reflect.TypeFor[string]()
+	expected := []ColumnInfo{
+		{Name: "id", Path: []int{0}, Type: reflect.TypeFor[int64](), Tagged: true},
+		{Name: "first_name", Path: []int{1}, Type: stringType, Tagged: true},
+		{Name: "last_name", Path: []int{2}, Type: stringType, Tagged: true},
+		{Name: "null_string", Path: []int{3}, Type: reflect.TypeFor[*string](), Tagged: true},
+		{Name: "created_at", Path: []int{7, 0}, Type: timeType, Tagged: true},
+		{Name: "updated_at", Path: []int{7, 1}, Type: timeType, Tagged: true},
+	}
+	typeComparer := cmp.Comparer(func(a, b reflect.Type) bool { return a == b })
+	if diff := cmp.Diff(expected, info.Columns, typeComparer); diff != "" {
+		t.Errorf("unexpected columns (-want +got):\n%s", diff)
+	}
+	if column, ok := info.Column("created_at"); !ok || column.Name != "created_at" {
+		t.Errorf("expected created_at column, got %+v", column)
+	}
+	if _, ok := info.Column("child"); ok {
+		t.Errorf("expected associations to not be columns")
+	}
+
+	// A copy, so the cache can't be corrupted
+	info.Columns[4].Path[0] = 100
+	if again, _ := TypeInfo[person](); again.Columns[4].Path[0] != 7 {
+		t.Errorf("expected info to be a copy")
+	}
+
+	if info, _ := TypeInfo[document](); !info.Columns[1].Gzip {
+		t.Errorf("expected gzip column, got %+v", info.Columns[1])
+	}
+	type invalid struct {
+		A string `sqlp:"a"`
+		B string `sqlp:"a"`
+	}
+	_, err = TypeInfo[invalid]()
+	errcmp.MustMatch(t, err, "duplicate column name a")
+}
@@ -0,0 +1,161 @@
+package sqlp
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/greghart/powerputtygo/queryp"
+	"github.com/greghart/powerputtygo/sqlp/internal/reflectp"
+)
+
+// updateChunkSize is how many ids UpdateWhereIDs updates per statement, keeping well under
+// parameter limits (eg. 999 in older SQLite).
+const updateChunkSize = 500
+
+// UpdateWhereIDs sets columns of the rows with the given ids (assuming `id` is the primary key, as
+// with Find), returning the total number of rows affected. Ids are updated in chunks of
+// `UPDATE ... WHERE id IN (...)` statements, all in one transaction, eg. for mass state transitions.
+// Columns to set are given as a map[string]any of column values, or a struct whose columnar fields
+// are the columns (typically a partial struct of just those columns). Either way they're validated
+// against E's columns, returning ErrUnknownColumn if any aren't one.
+//
+// With SkipFailedRows, rows that fail (eg. on a constraint) are skipped and reported in a
+// *BatchError, while the rest are updated.
+//
+//	n, err := repository.UpdateWhereIDs(ctx, ids, map[string]any{"status": "archived"})
+func (r *Repository[E]) UpdateWhereIDs(ctx context.Context, ids []int64, set any, opts ...BatchOption) (int64, error) {
+	if err := r.checkWritable("UpdateWhereIDs"); err != nil {
+		return 0, err
+	}
+	columns, values, err := r.assignments(set)
+	if err != nil {
+		return 0, err
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	d := r.Dialect()
+	update := func(ctx context.Context, ids []int64) (int64, error) {
+		args := queryp.NewArgs().WithPlaceholderer(d.Placeholderer())
+		assignments := make([]string, len(columns))
+		for i, column := range columns {
+			assignments[i] = d.QuoteIdent(column) + " = " + args.Add(values[i])
+		}
+		q := "UPDATE " + r.QualifiedTable() + " SET " + strings.Join(assignments, ", ") +
+			" WHERE " + r.scope(queryp.In("id", ids))(args)
+		return r.ExecRows(ctx, q, args.Args()...)
+	}
+
+	var affected int64
+	var failures []*BatchFailure
+	skip := hasBatchOption(opts, SkipFailedRows)
+	err = r.RunInTx(ctx, func(ctx context.Context) error {
+		for i := 0; i < len(ids); i += updateChunkSize {
+			chunk := ids[i:min(i+updateChunkSize, len(ids))]
+			if !skip {
+				n, err := update(ctx, chunk)
+				if err != nil {
+					return err
+				}
+				affected += n
+				continue
+			}
+
+			var n int64
+			err := r.RunInSavepoint(ctx, func(ctx context.Context) (err error) {
+				n, err = update(ctx, chunk)
+				return err
+			})
+			if err == nil {
+				affected += n
+				continue
+			}
+			// Find the failing rows
+			for j, id := range chunk {
+				err := r.RunInSavepoint(ctx, func(ctx context.Context) (err error) {
+					n, err = update(ctx, []int64{id})
+					return err
+				})
+				if err != nil {
+					failures = append(failures, &BatchFailure{Index: i + j, Key: id, Err: err})
+					continue
+				}
+				affected += n
+			}
+		}
+		return nil
+	})
+	if err == nil && len(failures) > 0 {
+		err = &BatchError{Failures: failures}
+	}
+	return affected, err
+}
+
+// assignments returns the columns and values to set from a map or struct, validated against E.
+// Values are as written (eg. compressed for gzip fields), with the checksum if E has one.
+func (r *Repository[E]) assignments(set any) ([]string, []any, error) {
+	var columns []string
+	var values []any
+	v := reflect.Indirect(reflect.ValueOf(set))
+	switch {
+	case v.Kind() == reflect.Map && v.Type().Key().Kind() == reflect.String:
+		for _, key := range v.MapKeys() {
+			columns = append(columns, key.String())
+		}
+		slices.Sort(columns)
+		for _, column := range columns {
+			values = append(values, v.MapIndex(reflect.ValueOf(column).Convert(v.Type().Key())).Interface())
+		}
+	case v.Kind() == reflect.Struct:
+		fields, err := reflectp.FieldsFactory(v.Type())
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to reflect fields for %v: %w", v.Type(), err)
+		}
+		byIndex := map[*reflectp.Field]string{}
+		for column, field := range fields.ByColumnName {
+			byIndex[field] = column
+		}
+		for _, field := range fields.Columns() {
+			fv, err := v.FieldByIndexErr(field.Index)
+			if err != nil {
+				return nil, nil, fmt.Errorf("sqlp: update column %s: %w", byIndex[field], err)
+			}
+			columns = append(columns, byIndex[field])
+			values = append(values, fv.Interface())
+		}
+	default:
+		return nil, nil, fmt.Errorf("sqlp: columns to update must be a map or struct, got %T", set)
+	}
+	if len(columns) == 0 {
+		return nil, nil, fmt.Errorf("sqlp: no columns to update")
+	}
+
+	fields, err := reflectp.FieldsFactory(r.t)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to reflect fields for %v: %w", r.t, err)
+	}
+	for _, column := range columns {
+		if field, ok := fields.ByColumnName[column]; !ok || !field.Columnar() {
+			return nil, nil, fmt.Errorf("%w: cannot update %q", ErrUnknownColumn, column)
+		}
+	}
+	sum, ok, err := r.checksumAssignment(columns, values)
+	if err != nil {
+		return nil, nil, err
+	}
+	for i, column := range columns {
+		if field := fields.ByColumnName[column]; field.Gzip || field.Scale != 0 {
+			if values[i], err = field.Value(reflect.ValueOf(values[i])); err != nil {
+				return nil, nil, fmt.Errorf("sqlp: update column %s: %w", column, err)
+			}
+		}
+	}
+	if ok {
+		columns = append(columns, r.checksum.column)
+		values = append(values, sum)
+	}
+	return columns, values, nil
+}
@@ -0,0 +1,124 @@
+package sqlp
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/greghart/powerputtygo/errcmp"
+	"github.com/greghart/powerputtygo/queryp"
+)
+
+func TestRepository_UpdateWhereIDs(t *testing.T) {
+	db, ctx, cleanup := testDB(t)
+	defer cleanup()
+	repository := NewRepository[person](db, "people")
+
+	var ids []int64
+	for range updateChunkSize + 2 {
+		ids = append(ids, must(db.ExecID(ctx, "INSERT INTO people (first_name, last_name) VALUES (?, ?)", "John", "Doe")))
+	}
+	untouched := must(db.ExecID(ctx, "INSERT INTO people (first_name, last_name) VALUES (?, ?)", "Jane", "Doe"))
+
+	lastNames := func() map[string]int {
+		t.Helper()
+		counts := map[string]int{}
+		rows, err := db.Query(ctx, "SELECT last_name, COUNT(*) FROM people GROUP BY last_name")
+		if err != nil {
+			t.Fatalf("failed to count: %v", err)
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var name string
+			var n int
+			if err := rows.Scan(&name, &n); err != nil {
+				t.Fatalf("failed to scan: %v", err)
+			}
+			counts[name] = n
+		}
+		return counts
+	}
+
+	t.Run("map", func(t *testing.T) {
+		n, err := repository.UpdateWhereIDs(ctx, ids, map[string]any{"last_name": "Smith"})
+		if err != nil || n != int64(len(ids)) {
+			t.Fatalf("expected %d updated, got %d, %v", len(ids), n, err)
+		}
+		if counts := lastNames(); counts["Smith"] != len(ids) || counts["Doe"] != 1 {
+			t.Errorf("unexpected last names: %v", counts)
+		}
+	})
+
+	t.Run("struct", func(t *testing.T) {
+		set := struct {
+			FirstName string `sqlp:"first_name"`
+			LastName  string `sqlp:"last_name"`
+		}{"Jim", "Jones"}
+		n, err := repository.UpdateWhereIDs(ctx, []int64{ids[0], untouched, 9999}, &set)
+		if err != nil || n != 2 {
+			t.Fatalf("expected 2 updated, got %d, %v", n, err)
+		}
+		p := must(repository.Find(ctx, int(untouched)))
+		if p.FirstName != "Jim" || p.LastName != "Jones" {
+			t.Errorf("unexpected person: %+v", p)
+		}
+	})
+
+	t.Run("skip failed rows", func(t *testing.T) {
+		db.MustExec(ctx, "CREATE UNIQUE INDEX people_first_name ON people (first_name) WHERE first_name LIKE 'Unique%'")
+		defer db.MustExec(ctx, "DROP INDEX people_first_name")
+		batch := []int64{ids[1], ids[2], ids[3]}
+
+		_, err := repository.UpdateWhereIDs(ctx, batch, map[string]any{"first_name": "Unique"})
+		errcmp.MustMatch(t, err, "UNIQUE constraint failed")
+
+		n, err := repository.UpdateWhereIDs(ctx, batch, map[string]any{"first_name": "Unique"}, SkipFailedRows)
+		if n != 1 {
+			t.Errorf("expected 1 updated, got %d", n)
+		}
+		var batchErr *BatchError
+		if !errors.As(err, &batchErr) {
+			t.Fatalf("expected a BatchError, got %v", err)
+		}
+		if len(batchErr.Failures) != 2 || batchErr.Failures[0].Index != 1 || batchErr.Failures[1].Key != ids[3] {
+			t.Errorf("unexpected failures: %+v", batchErr.Failures)
+		}
+		errcmp.MustMatch(t, err, "2 rows of batch failed: 1 (3): UNIQUE constraint failed")
+		if p := must(repository.Find(ctx, int(ids[1]))); p.FirstName != "Unique" {
+			t.Errorf("expected first row updated, got %+v", p)
+		}
+	})
+
+	t.Run("postgres placeholders", func(t *testing.T) {
+		pg := NewRepository[person](NewDB(db.DB, WithDialect(queryp.Postgres)), "people")
+		ctx, capture := CaptureQueries(ctx)
+		_, err := pg.UpdateWhereIDs(ctx, []int64{ids[0], ids[1]}, map[string]any{"first_name": "Jim", "last_name": "Jones"})
+		errcmp.MustMatch(t, err, "")
+		expected := `UPDATE people SET "first_name" = $1, "last_name" = $2 WHERE id IN ($3, $4)`
+		if queries := capture.Queries(); len(queries) != 1 || queries[0].Query != expected {
+			t.Errorf("expected Postgres placeholders, got %v", queries)
+		}
+	})
+
+	tests := map[string]struct {
+		set      any
+		expected string
+	}{
+		"unknown column":  {map[string]any{"email": "x"}, "unknown column: cannot update \"email\""},
+		"struct column":   {map[string]any{"child": nil}, "unknown column: cannot update \"child\""},
+		"no columns":      {map[string]any{}, "no columns to update"},
+		"not map/struct":  {"last_name", "must be a map or struct, got string"},
+		"unknown field":   {struct{ Email string }{"x"}, "cannot update \"Email\""},
+		"read-only check": {nil, "read-only"},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := repository
+			if test.set == nil {
+				r = repository.ReadOnly()
+				test.set = map[string]any{"last_name": "x"}
+			}
+			_, err := r.UpdateWhereIDs(ctx, ids, test.set)
+			errcmp.MustMatch(t, err, test.expected)
+		})
+	}
+}
@@ -0,0 +1,153 @@
+package sqlp
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"slices"
+
+	"github.com/greghart/powerputtygo/sqlp/internal/reflectp"
+)
+
+// Variants registers the concrete types that rows of interface I scan into, by the value of a
+// discriminator column, eg. for single table inheritance:
+//
+//	animals := sqlp.NewVariants[Animal]("type")
+//	sqlp.WithVariant[Animal, dog](animals, "dog")
+//	sqlp.WithVariant[Animal, cat](animals, "cat")
+//	pets, err := sqlp.SelectVariants(ctx, db, animals, "SELECT * FROM animals") // []Animal
+type Variants[I any] struct {
+	column string
+	types  map[string]reflect.Type
+}
+
+// NewVariants returns variants of I discriminated by column.
+func NewVariants[I any](column string) *Variants[I] {
+	return &Variants[I]{column: column, types: map[string]reflect.Type{}}
+}
+
+// WithVariant registers T as the concrete type of rows whose discriminator is value. T (or *T)
+// must implement I.
+func WithVariant[I, T any](v *Variants[I], value string) *Variants[I] {
+	v.types[value] = reflect.TypeFor[T]()
+	return v
+}
+
+// Column returns the discriminator column.
+func (v *Variants[I]) Column() string {
+	return v.column
+}
+
+// VariantScanner scans rows into the concrete types registered with Variants, by each row's
+// discriminator. The whole row is read before its type is known, so it's slower than scanning
+// straight into a struct.
+type VariantScanner[I any] struct {
+	*sql.Rows
+	variants *Variants[I]
+	columns  []string
+	at       int   // index of the discriminator column
+	values   []any // the row as read, before it's scanned into its type
+	targets  []any
+	ignore   []string
+}
+
+// NewVariantScanner returns a scanner of rows into variants.
+func NewVariantScanner[I any](rows *sql.Rows, variants *Variants[I]) (*VariantScanner[I], error) {
+	return newVariantScanner(rows, variants, nil)
+}
+
+func newVariantScanner[I any](rows *sql.Rows, variants *Variants[I], ignore []string) (*VariantScanner[I], error) {
+	iface := reflect.TypeFor[I]()
+	for value, t := range variants.types {
+		if !t.Implements(iface) && !reflect.PointerTo(t).Implements(iface) {
+			return nil, fmt.Errorf("sqlp: variant %v for %q doesn't implement %v", t, value, iface)
+		}
+	}
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get columns: %w", err)
+	}
+	at := slices.Index(columns, variants.column)
+	if at < 0 {
+		return nil, fmt.Errorf("%w: discriminator %q not in result", ErrUnknownColumn, variants.column)
+	}
+	s := &VariantScanner[I]{
+		Rows:     rows,
+		variants: variants,
+		columns:  columns,
+		at:       at,
+		values:   make([]any, len(columns)),
+		targets:  make([]any, len(columns)),
+		ignore:   ignore,
+	}
+	for i := range s.values {
+		s.targets[i] = &s.values[i]
+	}
+	return s, nil
+}
+
+// Scan scans the current row into a new value of its variant.
+func (s *VariantScanner[I]) Scan() (I, error) {
+	var i I
+	if err := s.Rows.Scan(s.targets...); err != nil {
+		return i, fmt.Errorf("failed to scan row: %w", err)
+	}
+	var value string
+	if err := reflectp.Assign(&value, s.values[s.at]); err != nil {
+		return i, fmt.Errorf("failed to read discriminator %s: %w", s.variants.column, err)
+	}
+	t, ok := s.variants.types[value]
+	if !ok {
+		return i, fmt.Errorf("%w: %s %q", ErrUnknownVariant, s.variants.column, value)
+	}
+	fields, err := reflectp.FieldsFactory(t)
+	if err != nil {
+		return i, fmt.Errorf("failed to reflect fields for %v: %w", t, err)
+	}
+	val, err := fields.ScanValues(s.columns, s.values, s.ignore...)
+	if err != nil {
+		return i, fmt.Errorf("failed to scan row into %v: %w", t, err)
+	}
+	if e, ok := val.Elem().Interface().(I); ok {
+		return e, nil
+	}
+	return val.Interface().(I), nil
+}
+
+// SelectVariants runs a query and scans the results into their registered variants.
+func SelectVariants[I any](ctx context.Context, db *DB, variants *Variants[I], query string, args ...any) ([]I, error) {
+	ctx, cancel := db.timeoutContext(ctx)
+	defer cancel()
+	rows, err := db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	scanner, err := newVariantScanner(rows, variants, ignoredColumns(ctx))
+	if err != nil {
+		return nil, err
+	}
+	limit := db.rowLimit(ctx)
+	var entities []I
+	for rows.Next() {
+		if limit.exceeded(int64(len(entities))) {
+			if limit.truncate {
+				break
+			}
+			return nil, limit.err()
+		}
+		e, err := scanner.Scan()
+		if err != nil {
+			return nil, err
+		}
+		entities = append(entities, e)
+		if err := checkSelectContext(ctx, int64(len(entities))); err != nil {
+			return nil, err
+		}
+	}
+	db.captureScanned(ctx, rows, int64(len(entities)))
+
+	return entities, rows.Err()
+}
@@ -0,0 +1,79 @@
+package sqlp
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/greghart/powerputtygo/errcmp"
+)
+
+type animal interface {
+	Sound() string
+}
+
+type dog struct {
+	ID       int64   `sqlp:"id"`
+	Type     string  `sqlp:"type"`
+	Name     string  `sqlp:"name"`
+	Nickname *string `sqlp:"nickname"`
+}
+
+func (d dog) Sound() string { return "woof" }
+
+type cat struct {
+	ID    int64  `sqlp:"id"`
+	Type  string `sqlp:"type"`
+	Name  string `sqlp:"name"`
+	Lives int    `sqlp:"lives"`
+}
+
+func (c *cat) Sound() string { return "meow" }
+
+func TestSelectVariants(t *testing.T) {
+	db, ctx, cleanup := testDB(t)
+	defer cleanup()
+	db.MustExec(ctx, "DROP TABLE IF EXISTS animals; CREATE TABLE animals (id INTEGER PRIMARY KEY, type TEXT, name TEXT, nickname TEXT, lives INTEGER)")
+	db.MustExec(ctx, "INSERT INTO animals (type, name, nickname, lives) VALUES ('dog', 'Rex', 'Rexy', NULL), ('cat', 'Tom', NULL, 9), ('dog', 'Fido', NULL, NULL)")
+	defer db.MustExec(ctx, "DROP TABLE animals")
+
+	variants := NewVariants[animal]("type")
+	WithVariant[animal, dog](variants, "dog")
+	WithVariant[animal, cat](variants, "cat")
+
+	t.Run("scans each row into its variant", func(t *testing.T) {
+		animals, err := SelectVariants(ctx, db, variants, "SELECT id, type, name, nickname, lives FROM animals ORDER BY id")
+		if err != nil {
+			t.Fatalf("failed to select: %v", err)
+		}
+		nickname := "Rexy"
+		expected := []animal{
+			dog{ID: 1, Type: "dog", Name: "Rex", Nickname: &nickname},
+			&cat{ID: 2, Type: "cat", Name: "Tom", Lives: 9},
+			dog{ID: 3, Type: "dog", Name: "Fido"},
+		}
+		if diff := cmp.Diff(expected, animals); diff != "" {
+			t.Errorf("selected animals unexpected:\n%v", diff)
+		}
+		if sound := animals[1].Sound(); sound != "meow" {
+			t.Errorf("expected meow, got %v", sound)
+		}
+	})
+
+	t.Run("unregistered variant -> err", func(t *testing.T) {
+		db.MustExec(ctx, "INSERT INTO animals (type, name) VALUES ('fish', 'Nemo')")
+		defer db.MustExec(ctx, "DELETE FROM animals WHERE type = 'fish'")
+		_, err := SelectVariants(ctx, db, variants, "SELECT * FROM animals")
+		errcmp.MustMatch(t, err, `unknown variant: type "fish"`)
+	})
+
+	t.Run("missing discriminator -> err", func(t *testing.T) {
+		_, err := SelectVariants(ctx, db, variants, "SELECT id, name FROM animals")
+		errcmp.MustMatch(t, err, `discriminator "type" not in result`)
+	})
+
+	t.Run("variant not implementing interface -> err", func(t *testing.T) {
+		variants := WithVariant[animal, person](NewVariants[animal]("type"), "person")
+		_, err := SelectVariants(ctx, db, variants, "SELECT * FROM animals")
+		errcmp.MustMatch(t, err, `variant sqlp.person for "person" doesn't implement sqlp.animal`)
+	})
+}
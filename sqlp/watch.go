@@ -0,0 +1,174 @@
+package sqlp
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/greghart/powerputtygo/queryp"
+	"github.com/greghart/powerputtygo/sqlp/internal/reflectp"
+)
+
+// Watcher polls a repository's table for changed rows, delivering them in batches to a callback --
+// a lightweight change data capture for tables with an updated timestamp.
+// Rows are tracked by an (updated at, id) watermark, so rows updated in the same instant aren't
+// skipped. The watermark only advances once the callback succeeds, so delivery is at least once.
+//
+//	w := sqlp.NewWatcher(people, func(ctx context.Context, changed []person) error { ... })
+//	w.From = lastSaved // eg. persisted from w.Watermark()
+//	err := w.Run(ctx)
+//
+// Note the updated column must be set by your writes, and compare correctly against time.Time args
+// (eg. SQLite stores whatever text it's given, so set it from go rather than CURRENT_TIMESTAMP).
+type Watcher[E any] struct {
+	Interval      time.Duration // How long to wait between polls once caught up, defaults to 1s
+	BatchSize     int           // Max rows per batch, defaults to 100
+	UpdatedColumn string        // Defaults to "updated_at", and must be a time.Time field
+	IDColumn      string        // Defaults to "id", and must be an integer field
+	From          Watermark     // Where to start watching from, defaults to the beginning
+
+	r  *Repository[E]
+	fn func(ctx context.Context, changed []E) error
+
+	mu        sync.Mutex
+	watermark *Watermark
+}
+
+// Watermark is the position of a Watcher, the last row it delivered.
+type Watermark struct {
+	UpdatedAt time.Time
+	ID        int64
+}
+
+// NewWatcher returns a Watcher of r's table, calling fn with each batch of changed rows.
+func NewWatcher[E any](r *Repository[E], fn func(ctx context.Context, changed []E) error) *Watcher[E] {
+	return &Watcher[E]{
+		Interval:      time.Second,
+		BatchSize:     100,
+		UpdatedColumn: "updated_at",
+		IDColumn:      "id",
+		r:             r,
+		fn:            fn,
+	}
+}
+
+// Run polls until ctx is done (returning nil), or a poll or callback errors.
+// Batches are polled back to back while full, then every Interval.
+func (w *Watcher[E]) Run(ctx context.Context) error {
+	for {
+		n, err := w.Poll(ctx)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if n >= w.BatchSize {
+			continue
+		}
+		t := time.NewTimer(w.Interval)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return nil
+		case <-t.C:
+		}
+	}
+}
+
+// Poll selects the next batch of changed rows after the watermark (within the repository's scope,
+// see WithDiscriminator), delivering them to the callback if there are any, and returns how many
+// there were.
+func (w *Watcher[E]) Poll(ctx context.Context) (int, error) {
+	from := w.Watermark()
+	d := w.r.Dialect()
+	updated, id := d.QuoteIdent(w.UpdatedColumn), d.QuoteIdent(w.IDColumn)
+	where, args := w.r.where(queryp.Or(
+		queryp.Gt(updated, from.UpdatedAt),
+		queryp.And(queryp.Eq(updated, from.UpdatedAt), queryp.Gt(id, from.ID)),
+	))
+	changed, err := w.r.Select(
+		ctx,
+		fmt.Sprintf(
+			"SELECT * FROM %s WHERE %s ORDER BY %s ASC, %s ASC LIMIT %d",
+			w.r.QualifiedTable(), where, updated, id, w.BatchSize,
+		),
+		args...,
+	)
+	if err != nil || len(changed) == 0 {
+		return 0, err
+	}
+	last, err := w.watermarkOf(changed[len(changed)-1])
+	if err != nil {
+		return 0, err
+	}
+	if err := w.fn(ctx, changed); err != nil {
+		return 0, err
+	}
+	w.mu.Lock()
+	w.watermark = &last
+	w.mu.Unlock()
+	return len(changed), nil
+}
+
+// Watermark returns the watcher's current position, eg. to persist and resume from later.
+func (w *Watcher[E]) Watermark() Watermark {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.watermark == nil {
+		return w.From
+	}
+	return *w.watermark
+}
+
+// watermarkOf returns the watermark of an entity.
+func (w *Watcher[E]) watermarkOf(entity E) (Watermark, error) {
+	fields, err := reflectp.FieldsFactory(w.r.t)
+	if err != nil {
+		return Watermark{}, err
+	}
+	v := reflect.ValueOf(entity)
+	updatedAt, err := watermarkField(fields, v, w.UpdatedColumn)
+	if err != nil {
+		return Watermark{}, err
+	}
+	id, err := watermarkField(fields, v, w.IDColumn)
+	if err != nil {
+		return Watermark{}, err
+	}
+
+	var mark Watermark
+	var ok bool
+	if mark.UpdatedAt, ok = updatedAt.Interface().(time.Time); !ok {
+		return Watermark{}, fmt.Errorf("sqlp: watcher column %s is a %v, expected time.Time", w.UpdatedColumn, updatedAt.Type())
+	}
+	switch {
+	case id.CanInt():
+		mark.ID = id.Int()
+	case id.CanUint():
+		mark.ID = int64(id.Uint())
+	default:
+		return Watermark{}, fmt.Errorf("sqlp: watcher column %s is a %v, expected an integer", w.IDColumn, id.Type())
+	}
+	return mark, nil
+}
+
+func watermarkField(fields *reflectp.Fields, v reflect.Value, column string) (reflect.Value, error) {
+	field, ok := fields.ByColumnName[column]
+	if !ok {
+		return reflect.Value{}, fmt.Errorf("%w: watcher column %s", ErrUnknownColumn, column)
+	}
+	fv, err := v.FieldByIndexErr(field.Index)
+	if err != nil {
+		return reflect.Value{}, fmt.Errorf("sqlp: watcher column %s: %w", column, err)
+	}
+	if fv.Kind() == reflect.Pointer {
+		if fv.IsNil() {
+			return reflect.Value{}, fmt.Errorf("sqlp: watcher column %s is null", column)
+		}
+		fv = fv.Elem()
+	}
+	return fv, nil
+}
@@ -0,0 +1,144 @@
+package sqlp
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/greghart/powerputtygo/errcmp"
+	"github.com/greghart/powerputtygo/queryp"
+)
+
+func TestWatcher(t *testing.T) {
+	db, ctx, cleanup := testDB(t)
+	defer cleanup()
+	repository := NewRepository[person](db, "people")
+
+	now := time.Now().UTC().Truncate(time.Second)
+	insert := func(name string, updatedAt time.Time) int64 {
+		return must(db.ExecID(ctx, "INSERT INTO people (first_name, last_name, updated_at) VALUES (?, ?, ?)", name, "Doe", updatedAt))
+	}
+	john := insert("John", now)
+	jane := insert("Jane", now) // same instant, must not be skipped
+	albert := insert("Albert", now.Add(time.Second))
+
+	var batches [][]string
+	w := NewWatcher(repository, func(ctx context.Context, changed []person) error {
+		names := []string{}
+		for _, p := range changed {
+			names = append(names, p.FirstName)
+		}
+		batches = append(batches, names)
+		return nil
+	})
+	w.BatchSize = 2
+
+	poll := func(expected int) {
+		t.Helper()
+		n, err := w.Poll(ctx)
+		if err != nil || n != expected {
+			t.Fatalf("expected %d changed, got %d, %v", expected, n, err)
+		}
+	}
+	poll(2)
+	if mark := w.Watermark(); mark.ID != jane || !mark.UpdatedAt.Equal(now) {
+		t.Errorf("unexpected watermark after first batch: %+v", mark)
+	}
+	poll(1)
+	poll(0)
+	db.MustExec(ctx, "UPDATE people SET updated_at = ? WHERE id = ?", now.Add(2*time.Second), john)
+	poll(1)
+
+	expected := [][]string{{"John", "Jane"}, {"Albert"}, {"John"}}
+	if !cmp.Equal(batches, expected) {
+		t.Errorf("unexpected batches:\n%v", cmp.Diff(expected, batches))
+	}
+	if mark := w.Watermark(); mark.ID != john {
+		t.Errorf("unexpected watermark: %+v", mark)
+	}
+
+	t.Run("resumes from watermark", func(t *testing.T) {
+		resumed := NewWatcher(repository, func(ctx context.Context, changed []person) error {
+			if len(changed) != 1 || changed[0].ID != albert {
+				t.Errorf("expected only albert, got %v", changed)
+			}
+			return nil
+		})
+		resumed.From = Watermark{UpdatedAt: now, ID: jane}
+		db.MustExec(ctx, "UPDATE people SET updated_at = ? WHERE id = ?", now, john) // back in time
+		if n, err := resumed.Poll(ctx); n != 1 || err != nil {
+			t.Errorf("expected 1 changed, got %d, %v", n, err)
+		}
+	})
+
+	t.Run("scoped, with dialect placeholders", func(t *testing.T) {
+		pg := NewRepository[person](NewDB(db.DB, WithDialect(queryp.Postgres)), "people").
+			WithDiscriminator("last_name", "Smith")
+		db.MustExec(ctx, "UPDATE people SET last_name = 'Smith' WHERE id = ?", albert)
+		var changed []person
+		w := NewWatcher(pg, func(ctx context.Context, batch []person) error {
+			changed = append(changed, batch...)
+			return nil
+		})
+		ctx, capture := CaptureQueries(ctx)
+		if n, err := w.Poll(ctx); n != 1 || err != nil {
+			t.Fatalf("expected 1 changed, got %d, %v", n, err)
+		}
+		if changed[0].ID != albert {
+			t.Errorf("expected only albert, got %v", changed)
+		}
+		expected := `SELECT * FROM people WHERE (("updated_at" > $1 OR ("updated_at" = $2 AND "id" > $3)) AND "last_name" = $4) ` +
+			`ORDER BY "updated_at" ASC, "id" ASC LIMIT 100`
+		if queries := capture.Queries(); len(queries) != 1 || queries[0].Query != expected {
+			t.Errorf("unexpected query: %v", queries)
+		}
+	})
+
+	t.Run("null watermark columns -> err", func(t *testing.T) {
+		type nullable struct {
+			ID        *int64     `sqlp:"id"`
+			UpdatedAt *time.Time `sqlp:"updated_at"`
+		}
+		w := NewWatcher(NewRepository[nullable](db, "people"), func(ctx context.Context, changed []nullable) error {
+			return nil
+		})
+		_, err := w.watermarkOf(nullable{ID: &john})
+		errcmp.MustMatch(t, err, "watcher column updated_at is null")
+		_, err = w.watermarkOf(nullable{UpdatedAt: &now})
+		errcmp.MustMatch(t, err, "watcher column id is null")
+		mark, err := w.watermarkOf(nullable{ID: &john, UpdatedAt: &now})
+		if err != nil || mark != (Watermark{UpdatedAt: now, ID: john}) {
+			t.Errorf("unexpected watermark: %+v, %v", mark, err)
+		}
+	})
+
+	t.Run("callback errors stop the watcher without advancing", func(t *testing.T) {
+		failing := NewWatcher(repository, func(ctx context.Context, changed []person) error {
+			return errors.New("boom")
+		})
+		failing.Interval = time.Millisecond
+		errcmp.MustMatch(t, failing.Run(ctx), "boom")
+		if mark := failing.Watermark(); mark != (Watermark{}) {
+			t.Errorf("expected watermark not advanced, got %+v", mark)
+		}
+	})
+
+	t.Run("run stops with context", func(t *testing.T) {
+		runCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+		defer cancel()
+		w := NewWatcher(repository, func(ctx context.Context, changed []person) error { return nil })
+		w.Interval = time.Millisecond
+		if err := w.Run(runCtx); err != nil {
+			t.Errorf("expected nil when context done, got %v", err)
+		}
+	})
+}
+
+func must[T any](v T, err error) T {
+	if err != nil {
+		panic(err)
+	}
+	return v
+}